@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/agent"
+	"github.com/fmuoria/CV-Review-agent/internal/export"
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/jobs"
+	"github.com/fmuoria/CV-Review-agent/internal/llm"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// runScore scores the applications in a folder
+func runScore(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("score", "--job job.json [--dir ./cvs] [--out report.xlsx]", stderr)
+	jobPath := fs.String("job", "", "job description JSON file (required)")
+	dir := fs.String("dir", "uploads", "folder with the applications")
+	out := fs.String("out", "", "report file, .xlsx or .json (default: JSON on stdout)")
+	if err := parseFlags(fs, args, "job"); err != nil {
+		return err
+	}
+
+	jobDescJSON, err := readJobDescription(*jobPath)
+	if err != nil {
+		return err
+	}
+
+	a := newAgent(*dir, stderr)
+	defer a.Close()
+
+	if err := a.IngestFromUploadWithContext(ctx, jobDescJSON); err != nil {
+		return err
+	}
+	report, err := a.GetReport()
+	if err != nil {
+		return err
+	}
+	if err := writeReport(report, *out, stdout); err != nil {
+		return err
+	}
+	return checkFailed(a, report)
+}
+
+// runRescore scores some or all applicants of a saved report again, keeping the other results
+func runRescore(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("rescore", "--report report.json [--only \"Jane Smith,John Doe\"] [--job job.json] [--dir ./cvs] [--out report.xlsx]", stderr)
+	reportPath := fs.String("report", "", "JSON report from an earlier run (required)")
+	only := fs.String("only", "", "comma-separated applicants to rescore (default: all)")
+	jobPath := fs.String("job", "", "job description JSON file (default: the one saved in the report)")
+	dir := fs.String("dir", "uploads", "folder with the applications")
+	out := fs.String("out", "", "report file, .xlsx or .json (default: JSON on stdout)")
+	if err := parseFlags(fs, args, "report"); err != nil {
+		return err
+	}
+
+	previous, err := readReport(*reportPath)
+	if err != nil {
+		return err
+	}
+
+	var jobDescJSON string
+	switch {
+	case *jobPath != "":
+		if jobDescJSON, err = readJobDescription(*jobPath); err != nil {
+			return err
+		}
+	case previous.JobDescription != nil:
+		data, err := json.Marshal(previous.JobDescription)
+		if err != nil {
+			return fmt.Errorf("failed to encode job description: %w", err)
+		}
+		jobDescJSON = string(data)
+	default:
+		return fmt.Errorf("%s has no job description, pass --job", *reportPath)
+	}
+
+	var names []string
+	for _, name := range strings.Split(*only, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	a := newAgent(*dir, stderr)
+	defer a.Close()
+
+	if err := a.RescoreWithContext(ctx, jobDescJSON, previous.Applicants, names); err != nil {
+		return err
+	}
+	report, err := a.GetReport()
+	if err != nil {
+		return err
+	}
+	if err := writeReport(report, *out, stdout); err != nil {
+		return err
+	}
+	return checkFailed(a, report)
+}
+
+// extractResult is the output of the extract command
+type extractResult struct {
+	Applicants       []models.ApplicantDocument `json:"applicants"`
+	UnprocessedFiles []models.UnprocessedFile   `json:"unprocessed_files,omitempty"`
+}
+
+// runExtract unpacks archives and email exports into a folder and prints the
+// documents and text found per applicant, without scoring
+func runExtract(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("extract", "[--dir ./cvs] [--out applicants.json] [--text=false] [applications.zip|export.mbox|message.eml ...]", stderr)
+	dir := fs.String("dir", "uploads", "folder to unpack into and read the applications from")
+	out := fs.String("out", "", "JSON output file (default: stdout)")
+	text := fs.Bool("text", true, "include the extracted text of each document")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	a := agent.NewCVReviewAgent()
+	a.FileHandler = ingestion.NewFileHandler(*dir)
+
+	for _, path := range fs.Args() {
+		switch {
+		case ingestion.IsMailFile(path):
+			saved, err := a.FileHandler.ImportMailFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", path, err)
+			}
+			fmt.Fprintf(stderr, "Imported %d attachment(s) from %s\n", saved, filepath.Base(path))
+		case strings.EqualFold(filepath.Ext(path), ".zip"):
+			saved, err := a.FileHandler.ExtractArchive(path)
+			if err != nil {
+				return fmt.Errorf("failed to extract %s: %w", path, err)
+			}
+			fmt.Fprintf(stderr, "Extracted %d file(s) from %s\n", len(saved), filepath.Base(path))
+		default:
+			return fmt.Errorf("unsupported file %s (use .zip, .eml or .mbox)", path)
+		}
+	}
+
+	documents, err := a.FileHandler.LoadDocuments()
+	if err != nil {
+		return fmt.Errorf("failed to load documents: %w", err)
+	}
+	if !*text {
+		for i := range documents {
+			for j := range documents[i].Documents {
+				documents[i].Documents[j].Content = ""
+			}
+		}
+	}
+	fmt.Fprintf(stderr, "Found %d applicant(s) in %s\n", len(documents), *dir)
+
+	return writeJSON(extractResult{
+		Applicants:       documents,
+		UnprocessedFiles: a.FileHandler.UnprocessedFiles(),
+	}, *out, stdout)
+}
+
+// runExport converts a saved JSON report to Excel
+func runExport(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export", "--report report.json --out report.xlsx [--job job.json]", stderr)
+	reportPath := fs.String("report", "", "JSON report from an earlier run (required)")
+	out := fs.String("out", "", "Excel file to write (required)")
+	jobPath := fs.String("job", "", "job description JSON file for the summary sheet (default: the one saved in the report)")
+	if err := parseFlags(fs, args, "report", "out"); err != nil {
+		return err
+	}
+
+	report, err := readReport(*reportPath)
+	if err != nil {
+		return err
+	}
+	if *jobPath != "" {
+		jobDescJSON, err := readJobDescription(*jobPath)
+		if err != nil {
+			return err
+		}
+		report.JobDescription = &models.JobDescription{}
+		if err := json.Unmarshal([]byte(jobDescJSON), report.JobDescription); err != nil {
+			return fmt.Errorf("failed to parse job description %s: %w", *jobPath, err)
+		}
+	}
+	return writeReport(report, *out, stdout)
+}
+
+// runParseJob turns a job posting into a job description JSON file to review
+// and pass to score --job
+func runParseJob(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("parse-job", "--in posting.pdf [--out job.json]", stderr)
+	in := fs.String("in", "", "job posting as text, PDF, DOCX, HTML or another supported format (required)")
+	out := fs.String("out", "", "job description JSON file to write (default: stdout)")
+	if err := parseFlags(fs, args, "in"); err != nil {
+		return err
+	}
+
+	// Read the posting first so a bad file fails before the LLM is set up
+	text, err := jobs.PostingText(*in)
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := llm.NewVertexAIClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	defer llmClient.Close()
+
+	jobDesc, err := jobs.NewParser(llmClient).ParseText(ctx, text)
+	if err != nil {
+		return err
+	}
+	if err := jobDesc.Validate(); err != nil {
+		fmt.Fprintf(stderr, "Review the job description before scoring: %v\n", err)
+	}
+	return writeJSON(jobDesc, *out, stdout)
+}
+
+// newFlagSet creates a flag set that reports errors instead of exiting
+func newFlagSet(name, synopsis string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: cvreview %s %s\n\nFlags:\n", name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and checks that the required flags were set
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage
+	}
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "missing required flag --%s\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// newAgent creates an agent that reads applications from dir and prints progress
+func newAgent(dir string, stderr io.Writer) *agent.CVReviewAgent {
+	a := agent.NewCVReviewAgent()
+	a.FileHandler = ingestion.NewFileHandler(dir)
+
+	var last string
+	a.SetProgressCallback(func(current, total int, message string) {
+		if message == last {
+			return
+		}
+		last = message
+		fmt.Fprintf(stderr, "[%3d%%] %s\n", 100*current/total, message)
+	})
+	return a
+}
+
+// checkFailed returns an error naming the applicants that could not be scored,
+// so a run that left some out of the report does not exit with 0
+func checkFailed(a *agent.CVReviewAgent, report models.ReportResponse) error {
+	failed := a.FailedApplicants()
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d applicant(s) could not be scored and are missing from the report: %s",
+		len(failed), len(failed)+len(report.Applicants), strings.Join(failed, ", "))
+}
+
+// readJobDescription reads and checks a job description file
+func readJobDescription(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read job description: %w", err)
+	}
+	var jobDesc models.JobDescription
+	if err := json.Unmarshal(data, &jobDesc); err != nil {
+		return "", fmt.Errorf("failed to parse job description %s: %w", path, err)
+	}
+	if strings.TrimSpace(jobDesc.Title) == "" {
+		return "", fmt.Errorf("job description %s has no title", path)
+	}
+	return string(data), nil
+}
+
+// readReport reads a JSON report written by score or rescore (or GET /report)
+func readReport(path string) (models.ReportResponse, error) {
+	var report models.ReportResponse
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, fmt.Errorf("failed to read report: %w", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// writeReport writes the report as Excel or JSON depending on the extension of
+// out, or as JSON to stdout when out is empty. Writing a file prints the ranking.
+func writeReport(report models.ReportResponse, out string, stdout io.Writer) error {
+	switch strings.ToLower(filepath.Ext(out)) {
+	case "":
+		if out != "" {
+			return fmt.Errorf("unsupported report format %q (use .xlsx or .json)", out)
+		}
+		return writeJSON(report, "", stdout)
+	case ".json":
+		if err := writeJSON(report, out, stdout); err != nil {
+			return err
+		}
+	case ".xlsx":
+		jobDesc := models.JobDescription{Title: report.JobTitle}
+		if report.JobDescription != nil {
+			jobDesc = *report.JobDescription
+		}
+		if err := export.ExportToExcel(report.Applicants, jobDesc, out); err != nil {
+			return fmt.Errorf("failed to export report: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported report format %q (use .xlsx or .json)", filepath.Ext(out))
+	}
+
+	for _, result := range report.Applicants {
+		fmt.Fprintf(stdout, "%3d. %-30s %6.2f\n", result.Rank, result.Name, result.Scores.TotalScore)
+	}
+	if len(report.UnprocessedFiles) > 0 {
+		fmt.Fprintf(stdout, "%d file(s) were not scored; see unprocessed_files in the JSON report\n", len(report.UnprocessedFiles))
+	}
+	fmt.Fprintf(stdout, "Wrote %d applicant(s) to %s\n", len(report.Applicants), out)
+	return nil
+}
+
+// writeJSON writes v as indented JSON to out, or to stdout when out is empty
+func writeJSON(v interface{}, out string, stdout io.Writer) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	data = append(data, '\n')
+
+	if out == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Command cvreview scores applications from the command line, for batch runs
+// from cron or CI without a browser or display.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const usage = `Usage: cvreview <command> [flags]
+
+Commands:
+  score     Score the applications in a folder and write the ranked report
+  extract   Unpack archives or email exports and print the text found per applicant
+  export    Convert a saved JSON report to Excel
+  rescore   Score some or all applicants of a saved report again
+  parse-job Turn a job posting (text, PDF, DOCX or HTML) into a job description
+
+Run 'cvreview <command> -h' for the flags of a command.
+`
+
+// errUsage marks invalid arguments; the flag package has already printed why
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the process exit code: 0 on success,
+// 1 on failure and 2 for invalid arguments
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	commands := map[string]func(context.Context, []string, io.Writer, io.Writer) error{
+		"score":     runScore,
+		"extract":   runExtract,
+		"export":    runExport,
+		"rescore":   runRescore,
+		"parse-job": runParseJob,
+	}
+
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "cvreview: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := command(ctx, args[1:], stdout, stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintf(stderr, "cvreview %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// TestRun_ExitCodes tests usage errors and help
+func TestRun_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, 2},
+		{"unknown command", []string{"grade"}, 2},
+		{"help", []string{"help"}, 0},
+		{"command help", []string{"score", "-h"}, 0},
+		{"missing job", []string{"score", "--dir", "cvs"}, 2},
+		{"unknown flag", []string{"export", "--bogus"}, 2},
+		{"missing report file", []string{"export", "--report", "missing.json", "--out", "r.xlsx"}, 1},
+		{"missing posting flag", []string{"parse-job"}, 2},
+		{"missing posting file", []string{"parse-job", "--in", "missing.pdf"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(context.Background(), tt.args, &stdout, &stderr); got != tt.want {
+				t.Errorf("run(%v) = %d, want %d (stderr: %s)", tt.args, got, tt.want, stderr.String())
+			}
+		})
+	}
+}
+
+// TestRun_Export tests converting a saved report to Excel and JSON
+func TestRun_Export(t *testing.T) {
+	dir := t.TempDir()
+	report := models.ReportResponse{
+		JobTitle: "Platform Engineer",
+		Applicants: []models.ApplicantResult{
+			{Name: "Jane Smith", Rank: 1, Scores: models.Scores{TotalScore: 82.5}},
+			{Name: "John Doe", Rank: 2, Scores: models.Scores{TotalScore: 61}},
+		},
+	}
+	data, _ := json.Marshal(report)
+	reportPath := filepath.Join(dir, "report.json")
+	os.WriteFile(reportPath, data, 0644)
+
+	for _, out := range []string{"report.xlsx", "copy.json"} {
+		var stdout, stderr bytes.Buffer
+		outPath := filepath.Join(dir, out)
+		if code := run(context.Background(), []string{"export", "--report", reportPath, "--out", outPath}, &stdout, &stderr); code != 0 {
+			t.Fatalf("export to %s failed with %d: %s", out, code, stderr.String())
+		}
+		if _, err := os.Stat(outPath); err != nil {
+			t.Errorf("Expected %s to be written: %v", out, err)
+		}
+		if !strings.Contains(stdout.String(), "1. Jane Smith") {
+			t.Errorf("Expected ranking on stdout, got %q", stdout.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"export", "--report", reportPath, "--out", filepath.Join(dir, "report.csv")}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected unsupported format to fail, got %d", code)
+	}
+}
+
+// TestRun_Extract tests listing applicants without their text
+func TestRun_Extract(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "JaneSmith_CV.txt"), []byte("Jane Smith\njane@example.com\nWork Experience\nEngineer at Acme 2018 - 2024\nEducation\nBSc Computer Science"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"extract", "--dir", dir, "--text=false"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("extract failed with %d: %s", code, stderr.String())
+	}
+
+	var result extractResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(result.Applicants) != 1 || result.Applicants[0].Name != "JaneSmith" {
+		t.Fatalf("Expected JaneSmith, got %+v", result.Applicants)
+	}
+	if result.Applicants[0].Documents[0].Content != "" {
+		t.Error("Expected text to be left out with --text=false")
+	}
+}
@@ -5,6 +5,11 @@
 
 set -e
 
+if [ -z "$API_KEY" ]; then
+    echo "Error: set API_KEY to a recruiter or admin API key (the server logs an admin key on first start)"
+    exit 1
+fi
+
 echo "=== CV Review Agent - Upload Method Test ==="
 echo ""
 
@@ -25,6 +30,7 @@ echo ""
 # Upload documents and job description
 echo "Uploading documents and job description..."
 curl -X POST http://localhost:8080/ingest \
+  -H "Authorization: Bearer $API_KEY" \
   -F "method=upload" \
   -F "job_description=$(cat examples/job_description.json)" \
   -F "files=@examples/JohnDoe_CV.txt" \
@@ -41,7 +47,7 @@ echo ""
 
 # Get the report
 echo "Fetching evaluation report..."
-curl -s http://localhost:8080/report | jq .
+curl -s -H "Authorization: Bearer $API_KEY" http://localhost:8080/report | jq .
 echo ""
 
 echo "=== Test completed successfully ==="
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"sort"
 	"strings"
@@ -30,23 +31,60 @@ const (
 type CVReviewAgent struct {
 	FileHandler  *ingestion.FileHandler
 	gmailHandler *ingestion.GmailHandler
-	llmClient    *llm.VertexAIClient
-	scorer       *scoring.Scorer
+	gmailAccount string    // account of the last Gmail fetch, used by the follow-up
+	llmClient    io.Closer // client of scorer, closed when the scorer is replaced
+	scorer       Scorer
+	newScorer    func() (Scorer, io.Closer, error)
 	jobDesc      models.JobDescription
 	results      []models.ApplicantResult
+	unprocessed  []models.UnprocessedFile
+	failed       []string                            // applicants the last run could not score
+	documents    map[string]models.ApplicantDocument // last loaded documents by applicant name
+	generation   uint64                              // incremented when the job, documents or results are replaced
 	mu           sync.RWMutex
 	progressCb   ProgressCallback
 }
 
+// Scorer scores one applicant against a job description
+type Scorer interface {
+	ScoreApplicant(ctx context.Context, doc models.ApplicantDocument, jobDesc models.JobDescription) (models.Scores, error)
+}
+
+// newVertexAIScorer creates a Vertex AI client and a scorer using it
+func newVertexAIScorer() (Scorer, io.Closer, error) {
+	llmClient, err := llm.NewVertexAIClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	return scoring.NewScorer(llmClient), llmClient, nil
+}
+
+// nopCloser is the client of a scorer that needs no cleanup
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
 // NewCVReviewAgent creates a new CV review agent
 func NewCVReviewAgent() *CVReviewAgent {
 	fileHandler := ingestion.NewFileHandler("uploads")
 
 	return &CVReviewAgent{
 		FileHandler: fileHandler,
+		newScorer:   newVertexAIScorer,
 	}
 }
 
+// SetScorer makes the agent score applicants with scorer instead of the
+// Vertex AI model, e.g. another model or a fake in tests
+func (a *CVReviewAgent) SetScorer(scorer Scorer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.newScorer = func() (Scorer, io.Closer, error) {
+		return scorer, nopCloser{}, nil
+	}
+	a.scorer = nil
+}
+
 // SetProgressCallback sets the progress callback function
 func (a *CVReviewAgent) SetProgressCallback(cb ProgressCallback) {
 	a.mu.Lock()
@@ -73,19 +111,16 @@ func (a *CVReviewAgent) IngestFromUpload(jobDescJSON string) error {
 // IngestFromUploadWithContext processes documents from the uploads directory with context
 func (a *CVReviewAgent) IngestFromUploadWithContext(ctx context.Context, jobDescJSON string) error {
 	// Parse job description
-	if err := json.Unmarshal([]byte(jobDescJSON), &a.jobDesc); err != nil {
-		return fmt.Errorf("failed to parse job description: %w", err)
+	if err := a.setJobDescription(jobDescJSON); err != nil {
+		return err
 	}
 
 	a.reportProgress(0, 100, "Initializing LLM client...")
 
 	// Initialize LLM client
-	llmClient, err := llm.NewVertexAIClient()
-	if err != nil {
-		return fmt.Errorf("failed to initialize LLM client: %w", err)
+	if err := a.initScorer(); err != nil {
+		return err
 	}
-	a.llmClient = llmClient
-	a.scorer = scoring.NewScorer(llmClient)
 
 	a.reportProgress(10, 100, "Loading documents...")
 
@@ -94,6 +129,7 @@ func (a *CVReviewAgent) IngestFromUploadWithContext(ctx context.Context, jobDesc
 	if err != nil {
 		return fmt.Errorf("failed to load documents: %w", err)
 	}
+	a.recordLoaded(a.FileHandler, documents)
 
 	if len(documents) == 0 {
 		return fmt.Errorf("no documents found in uploads directory")
@@ -103,25 +139,30 @@ func (a *CVReviewAgent) IngestFromUploadWithContext(ctx context.Context, jobDesc
 	a.reportProgress(20, 100, fmt.Sprintf("Processing %d applicants...", len(documents)))
 
 	// Process each applicant
-	return a.processApplicants(ctx, documents)
+	return a.processApplicants(ctx, documents, nil)
 }
 
 // IngestFromGmail processes documents from Gmail
 func (a *CVReviewAgent) IngestFromGmail(subject string, jobDescJSON string) error {
-	return a.IngestFromGmailWithContext(context.Background(), subject, jobDescJSON)
+	return a.IngestFromGmailWithContext(context.Background(), models.GmailFilter{Subject: subject}, jobDescJSON)
 }
 
-// IngestFromGmailWithContext processes documents from Gmail with context
-func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, subject string, jobDescJSON string) error {
+// IngestFromGmailWithContext processes documents from the Gmail messages matching filter
+func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, filter models.GmailFilter, jobDescJSON string) error {
+	// Reject a bad filter before clearing the uploads
+	if _, err := ingestion.GmailQuery(filter); err != nil {
+		return err
+	}
+
 	// Parse job description
-	if err := json.Unmarshal([]byte(jobDescJSON), &a.jobDesc); err != nil {
-		return fmt.Errorf("failed to parse job description: %w", err)
+	if err := a.setJobDescription(jobDescJSON); err != nil {
+		return err
 	}
 
 	a.reportProgress(0, 100, "Initializing Gmail handler...")
 
 	// Initialize Gmail handler with progress callback
-	gmailHandler, err := ingestion.NewGmailHandlerWithCallback("uploads", func(current, total int, message string) {
+	gmailHandler, err := ingestion.NewGmailHandlerForAccount(filter.Account, "uploads", func(current, total int, message string) {
 		// Map Gmail progress (0-40% of total progress)
 		progress := 40 * current / total
 		a.reportProgress(progress, 100, message)
@@ -130,6 +171,9 @@ func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, subject
 		return fmt.Errorf("failed to initialize Gmail handler: %w", err)
 	}
 	a.gmailHandler = gmailHandler
+	a.mu.Lock()
+	a.gmailAccount = gmailHandler.Account()
+	a.mu.Unlock()
 
 	a.reportProgress(5, 100, "Clearing existing uploads...")
 
@@ -141,19 +185,16 @@ func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, subject
 	a.reportProgress(10, 100, "Fetching emails from Gmail...")
 
 	// Fetch attachments from Gmail
-	if err := a.gmailHandler.FetchAttachmentsWithContext(ctx, subject); err != nil {
+	if err := a.gmailHandler.FetchAttachmentsWithContext(ctx, filter); err != nil {
 		return fmt.Errorf("failed to fetch Gmail attachments: %w", err)
 	}
 
 	a.reportProgress(40, 100, "Initializing LLM client...")
 
 	// Initialize LLM client
-	llmClient, err := llm.NewVertexAIClient()
-	if err != nil {
-		return fmt.Errorf("failed to initialize LLM client: %w", err)
+	if err := a.initScorer(); err != nil {
+		return err
 	}
-	a.llmClient = llmClient
-	a.scorer = scoring.NewScorer(llmClient)
 
 	a.reportProgress(50, 100, "Loading documents...")
 
@@ -162,6 +203,7 @@ func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, subject
 	if err != nil {
 		return fmt.Errorf("failed to load documents: %w", err)
 	}
+	a.recordLoaded(a.FileHandler, documents)
 
 	if len(documents) == 0 {
 		return fmt.Errorf("no documents found after Gmail fetch")
@@ -171,7 +213,159 @@ func (a *CVReviewAgent) IngestFromGmailWithContext(ctx context.Context, subject
 	a.reportProgress(60, 100, fmt.Sprintf("Processing %d applicants...", len(documents)))
 
 	// Process each applicant
-	return a.processApplicants(ctx, documents)
+	return a.processApplicants(ctx, documents, nil)
+}
+
+// IngestFromIMAPWithContext fetches new application emails from an IMAP mailbox
+// and evaluates the documents. Unlike Gmail ingestion the uploads are kept:
+// only messages that no earlier fetch downloaded are fetched.
+func (a *CVReviewAgent) IngestFromIMAPWithContext(ctx context.Context, imapConfig ingestion.IMAPConfig, jobDescJSON string) error {
+	// Parse job description
+	if err := a.setJobDescription(jobDescJSON); err != nil {
+		return err
+	}
+
+	a.reportProgress(0, 100, "Initializing IMAP handler...")
+
+	// Map IMAP progress (0-40% of total progress)
+	imapHandler, err := ingestion.NewIMAPHandler(imapConfig, "uploads", func(current, total int, message string) {
+		a.reportProgress(40*current/total, 100, message)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize IMAP handler: %w", err)
+	}
+
+	saved, err := imapHandler.FetchAttachmentsWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch IMAP attachments: %w", err)
+	}
+	log.Printf("Fetched %d new attachment(s) over IMAP", saved)
+
+	return a.IngestFromUploadWithContext(ctx, jobDescJSON)
+}
+
+// RescoreWithContext evaluates the uploads again, keeping the previous result of
+// applicants that are not named in names. Named applicants, and applicants that
+// have no previous result, are scored; an empty names rescores everyone.
+// Applicants whose documents are gone are dropped.
+func (a *CVReviewAgent) RescoreWithContext(ctx context.Context, jobDescJSON string, previous []models.ApplicantResult, names []string) error {
+	// Parse job description
+	if err := a.setJobDescription(jobDescJSON); err != nil {
+		return err
+	}
+
+	documents, err := a.FileHandler.LoadDocuments()
+	if err != nil {
+		return fmt.Errorf("failed to load documents: %w", err)
+	}
+	a.recordLoaded(a.FileHandler, documents)
+
+	if len(documents) == 0 {
+		return fmt.Errorf("no documents found in uploads directory")
+	}
+
+	pending, kept := selectForRescore(documents, previous, names)
+	log.Printf("Rescoring %d applicant(s), keeping %d previous result(s)", len(pending), len(kept))
+	if len(pending) == 0 {
+		rankResults(kept)
+		a.mu.Lock()
+		a.results = kept
+		a.failed = nil
+		a.generation++
+		a.mu.Unlock()
+		return nil
+	}
+
+	a.reportProgress(0, 100, "Initializing LLM client...")
+
+	// Initialize LLM client
+	if err := a.initScorer(); err != nil {
+		return err
+	}
+
+	a.reportProgress(20, 100, fmt.Sprintf("Processing %d applicants...", len(pending)))
+	return a.processApplicants(ctx, pending, kept)
+}
+
+// selectForRescore splits the applicants into those to score and the previous
+// results to keep. Names are matched case-insensitively.
+func selectForRescore(documents []models.ApplicantDocument, previous []models.ApplicantResult, names []string) ([]models.ApplicantDocument, []models.ApplicantResult) {
+	byName := make(map[string]models.ApplicantResult, len(previous))
+	for _, result := range previous {
+		byName[strings.ToLower(result.Name)] = result
+	}
+	rescore := make(map[string]bool, len(names))
+	for _, name := range names {
+		rescore[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	var pending []models.ApplicantDocument
+	var kept []models.ApplicantResult
+	for _, doc := range documents {
+		key := strings.ToLower(doc.Name)
+		if result, ok := byName[key]; ok && len(names) > 0 && !rescore[key] {
+			kept = append(kept, result)
+			continue
+		}
+		pending = append(pending, doc)
+	}
+	return pending, kept
+}
+
+// FollowUpGmailWithContext labels and acknowledges the Gmail messages of the
+// current results. The account must have granted Gmail modify and compose access.
+func (a *CVReviewAgent) FollowUpGmailWithContext(ctx context.Context, followUp models.GmailFollowUp) (ingestion.GmailFollowUpSummary, error) {
+	if err := ingestion.ValidateGmailFollowUp(followUp); err != nil {
+		return ingestion.GmailFollowUpSummary{}, err
+	}
+
+	results := a.GetResults()
+	if len(results) == 0 {
+		return ingestion.GmailFollowUpSummary{}, fmt.Errorf("no results available, run ingestion first")
+	}
+
+	a.mu.RLock()
+	account := a.gmailAccount
+	a.mu.RUnlock()
+
+	gmailHandler, err := ingestion.NewGmailFollowUpHandler(account, "uploads")
+	if err != nil {
+		return ingestion.GmailFollowUpSummary{}, fmt.Errorf("failed to initialize Gmail handler: %w", err)
+	}
+	return gmailHandler.FollowUp(ctx, results, a.GetJobDescription().Title, followUp)
+}
+
+// setJobDescription parses the job description the next results are scored against
+func (a *CVReviewAgent) setJobDescription(jobDescJSON string) error {
+	var jobDesc models.JobDescription
+	if err := json.Unmarshal([]byte(jobDescJSON), &jobDesc); err != nil {
+		return fmt.Errorf("failed to parse job description: %w", err)
+	}
+	a.mu.Lock()
+	a.jobDesc = jobDesc
+	a.generation++
+	a.mu.Unlock()
+	return nil
+}
+
+// recordLoaded keeps the documents of the last load, so single applicants can
+// be shown and rescored, and the files it did not score so the report can list them
+func (a *CVReviewAgent) recordLoaded(fh *ingestion.FileHandler, documents []models.ApplicantDocument) {
+	unprocessed := fh.UnprocessedFiles()
+	if len(unprocessed) > 0 {
+		log.Printf("%d file(s) will not be scored; see unprocessed_files in the report", len(unprocessed))
+	}
+
+	byName := make(map[string]models.ApplicantDocument, len(documents))
+	for _, doc := range documents {
+		byName[doc.Name] = doc
+	}
+
+	a.mu.Lock()
+	a.unprocessed = unprocessed
+	a.documents = byName
+	a.generation++
+	a.mu.Unlock()
 }
 
 // isRateLimitError detects if an error is due to rate limiting
@@ -186,9 +380,16 @@ func isRateLimitError(err error) bool {
 		strings.Contains(errMsg, "quota")
 }
 
-// processApplicants evaluates all applicants and generates rankings
-func (a *CVReviewAgent) processApplicants(ctx context.Context, documents []models.ApplicantDocument) error {
-	results := make([]models.ApplicantResult, 0, len(documents))
+// processApplicants evaluates all applicants and ranks them together with the
+// already scored results in kept
+func (a *CVReviewAgent) processApplicants(ctx context.Context, documents []models.ApplicantDocument, kept []models.ApplicantResult) error {
+	a.mu.RLock()
+	scorer, jobDesc := a.scorer, a.jobDesc
+	a.mu.RUnlock()
+
+	results := make([]models.ApplicantResult, 0, len(kept)+len(documents))
+	results = append(results, kept...)
+	var failed []string
 	baseProgress := 60 // Start at 60% for Gmail, 20% for upload
 
 	for i, doc := range documents {
@@ -205,93 +406,130 @@ func (a *CVReviewAgent) processApplicants(ctx context.Context, documents []model
 		progress := baseProgress + (35 * i / len(documents))
 		a.reportProgress(progress, 100, fmt.Sprintf("Evaluating %s (%d/%d)", doc.Name, i+1, len(documents)))
 
-		// Score the applicant with retry logic
-		var scores models.Scores
-		var err error
-
-		for attempt := 0; attempt < maxRetries; attempt++ {
-			scores, err = a.scorer.ScoreApplicant(ctx, doc, a.jobDesc)
-
-			if err == nil {
-				// Check if we got an empty response (all scores are zero and all reasoning fields are empty)
-				if scores.ExperienceScore == 0 && scores.EducationScore == 0 &&
-					scores.DutiesScore == 0 && scores.CoverLetterScore == 0 &&
-					scores.ExperienceReasoning == "" && scores.EducationReasoning == "" &&
-					scores.DutiesReasoning == "" && scores.CoverLetterReasoning == "" {
-					// Empty response detected
-					if attempt < maxRetries-1 {
-						// Exponential backoff: 10s, 20s, 40s
-						backoffDuration := retryBackoff * time.Duration(1<<attempt)
-						log.Printf("Empty response received for %s, retrying in %v (attempt %d/%d)",
-							doc.Name, backoffDuration, attempt+1, maxRetries)
-						a.reportProgress(progress, 100, fmt.Sprintf("Empty response - retrying %s in %v", doc.Name, backoffDuration))
-						time.Sleep(backoffDuration)
-						continue
-					} else {
-						log.Printf("Empty response for %s after %d attempts, skipping", doc.Name, maxRetries)
-						err = fmt.Errorf("empty response after %d retries", maxRetries)
-						break
-					}
-				}
+		if result, err := a.scoreApplicant(ctx, scorer, jobDesc, doc, progress); err == nil {
+			results = append(results, result)
+		} else {
+			failed = append(failed, doc.Name)
+		}
 
-				// Success with valid scores!
-				log.Printf("Successfully scored: %s - Total: %.2f (Exp: %.2f, Edu: %.2f, Duties: %.2f, CL: %.2f)",
-					doc.Name, scores.TotalScore, scores.ExperienceScore, scores.EducationScore, scores.DutiesScore, scores.CoverLetterScore)
-				break
-			}
+		// Rate limiting delay between requests (skip after last applicant)
+		if i < len(documents)-1 {
+			log.Printf("Rate limit delay (%v) before next applicant...", requestDelay)
+			a.reportProgress(progress, 100, fmt.Sprintf("Rate limit delay before next applicant..."))
+			time.Sleep(requestDelay)
+		}
+	}
 
-			// Check for empty response error
-			if strings.Contains(err.Error(), "length: 0") || strings.Contains(err.Error(), "no JSON found in response:") {
-				if attempt < maxRetries-1 {
-					// Exponential backoff: 10s, 20s, 40s
-					backoffDuration := retryBackoff * time.Duration(1<<attempt)
-					log.Printf("Empty response for %s, retrying in %v (attempt %d/%d)",
-						doc.Name, backoffDuration, attempt+1, maxRetries)
-					a.reportProgress(progress, 100, fmt.Sprintf("Empty response - retrying %s in %v", doc.Name, backoffDuration))
-					time.Sleep(backoffDuration)
-					continue
-				}
-			}
+	a.reportProgress(95, 100, "Ranking candidates...")
+	rankResults(results)
+
+	a.mu.Lock()
+	a.results = results
+	a.failed = failed
+	a.generation++
+	a.mu.Unlock()
+
+	if len(failed) > 0 {
+		log.Printf("Could not score %d applicant(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	a.reportProgress(100, 100, "Processing complete!")
 
-			// Check if it's a rate limit error
-			if isRateLimitError(err) {
+	return nil
+}
+
+// scoreApplicant scores one applicant, retrying empty responses and rate limits
+func (a *CVReviewAgent) scoreApplicant(ctx context.Context, scorer Scorer, jobDesc models.JobDescription, doc models.ApplicantDocument, progress int) (models.ApplicantResult, error) {
+	// Score the applicant with retry logic
+	var scores models.Scores
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		scores, err = scorer.ScoreApplicant(ctx, doc, jobDesc)
+
+		if err == nil {
+			// Check if we got an empty response (all scores are zero and all reasoning fields are empty)
+			if scores.ExperienceScore == 0 && scores.EducationScore == 0 &&
+				scores.DutiesScore == 0 && scores.CoverLetterScore == 0 &&
+				scores.ExperienceReasoning == "" && scores.EducationReasoning == "" &&
+				scores.DutiesReasoning == "" && scores.CoverLetterReasoning == "" {
+				// Empty response detected
 				if attempt < maxRetries-1 {
 					// Exponential backoff: 10s, 20s, 40s
 					backoffDuration := retryBackoff * time.Duration(1<<attempt)
-					log.Printf("Rate limit hit for %s, retrying in %v (attempt %d/%d)",
+					log.Printf("Empty response received for %s, retrying in %v (attempt %d/%d)",
 						doc.Name, backoffDuration, attempt+1, maxRetries)
-					a.reportProgress(progress, 100, fmt.Sprintf("Rate limit - retrying %s in %v", doc.Name, backoffDuration))
+					a.reportProgress(progress, 100, fmt.Sprintf("Empty response - retrying %s in %v", doc.Name, backoffDuration))
 					time.Sleep(backoffDuration)
 					continue
+				} else {
+					log.Printf("Empty response for %s after %d attempts, skipping", doc.Name, maxRetries)
+					err = fmt.Errorf("empty response after %d retries", maxRetries)
+					break
 				}
 			}
 
-			// Other errors or max retries reached - log and skip
-			log.Printf("Failed to score applicant %s after %d attempts: %v", doc.Name, attempt+1, err)
+			// Success with valid scores!
+			log.Printf("Successfully scored: %s - Total: %.2f (Exp: %.2f, Edu: %.2f, Duties: %.2f, CL: %.2f)",
+				doc.Name, scores.TotalScore, scores.ExperienceScore, scores.EducationScore, scores.DutiesScore, scores.CoverLetterScore)
 			break
 		}
 
-		if err == nil {
-			result := models.ApplicantResult{
-				Name:   doc.Name,
-				Scores: scores,
-				CVPath: doc.CVPath,
-				CLPath: doc.CLPath,
+		// Check for empty response error
+		if strings.Contains(err.Error(), "length: 0") || strings.Contains(err.Error(), "no JSON found in response:") {
+			if attempt < maxRetries-1 {
+				// Exponential backoff: 10s, 20s, 40s
+				backoffDuration := retryBackoff * time.Duration(1<<attempt)
+				log.Printf("Empty response for %s, retrying in %v (attempt %d/%d)",
+					doc.Name, backoffDuration, attempt+1, maxRetries)
+				a.reportProgress(progress, 100, fmt.Sprintf("Empty response - retrying %s in %v", doc.Name, backoffDuration))
+				time.Sleep(backoffDuration)
+				continue
 			}
-			results = append(results, result)
 		}
 
-		// Rate limiting delay between requests (skip after last applicant)
-		if i < len(documents)-1 {
-			log.Printf("Rate limit delay (%v) before next applicant...", requestDelay)
-			a.reportProgress(progress, 100, fmt.Sprintf("Rate limit delay before next applicant..."))
-			time.Sleep(requestDelay)
+		// Check if it's a rate limit error
+		if isRateLimitError(err) {
+			if attempt < maxRetries-1 {
+				// Exponential backoff: 10s, 20s, 40s
+				backoffDuration := retryBackoff * time.Duration(1<<attempt)
+				log.Printf("Rate limit hit for %s, retrying in %v (attempt %d/%d)",
+					doc.Name, backoffDuration, attempt+1, maxRetries)
+				a.reportProgress(progress, 100, fmt.Sprintf("Rate limit - retrying %s in %v", doc.Name, backoffDuration))
+				time.Sleep(backoffDuration)
+				continue
+			}
 		}
+
+		// Other errors or max retries reached - log and skip
+		log.Printf("Failed to score applicant %s after %d attempts: %v", doc.Name, attempt+1, err)
+		break
 	}
 
-	a.reportProgress(95, 100, "Ranking candidates...")
+	if err != nil {
+		return models.ApplicantResult{}, err
+	}
+
+	result := models.ApplicantResult{
+		Name:   doc.Name,
+		Scores: scores,
+		CVPath: doc.CV().Path,
+		CLPath: doc.CoverLetter().Path,
+		Emails: doc.Emails,
+		Phones: doc.Phones,
+		// Carry document quality flags through so reviewers can check OCR'd CVs
+		NeedsReview:   len(doc.ReviewReasons) > 0,
+		ReviewReasons: doc.ReviewReasons,
+		OCRPages:      doc.OCRPages,
+	}
+	for _, d := range doc.Documents {
+		result.Documents = append(result.Documents, models.Document{Type: d.Type, Path: d.Path})
+	}
+	return result, nil
+}
 
-	// Sort by total score (descending), with tie-breaking by component scores
+// rankResults sorts results by total score (descending), with tie-breaking by
+// component scores, and assigns ranks
+func rankResults(results []models.ApplicantResult) {
 	sort.Slice(results, func(i, j int) bool {
 		// Primary: Total score
 		if results[i].Scores.TotalScore != results[j].Scores.TotalScore {
@@ -317,18 +555,17 @@ func (a *CVReviewAgent) processApplicants(ctx context.Context, documents []model
 		return results[i].Scores.CoverLetterScore > results[j].Scores.CoverLetterScore
 	})
 
-	// Assign ranks
+	// Assign ranks and IDs; IDs do not depend on the rank, so they stay the same when rescoring
+	seen := make(map[string]int, len(results))
 	for i := range results {
 		results[i].Rank = i + 1
-	}
-
-	a.mu.Lock()
-	a.results = results
-	a.mu.Unlock()
-
-	a.reportProgress(100, 100, "Processing complete!")
 
-	return nil
+		id := resultID(results[i])
+		if seen[id]++; seen[id] > 1 {
+			id = fmt.Sprintf("%s-%d", id, seen[id])
+		}
+		results[i].ID = id
+	}
 }
 
 // GetReport returns the evaluation report
@@ -340,10 +577,15 @@ func (a *CVReviewAgent) GetReport() (models.ReportResponse, error) {
 		return models.ReportResponse{}, fmt.Errorf("no results available, run ingestion first")
 	}
 
+	jobDesc := a.jobDesc
+
 	return models.ReportResponse{
-		Applicants: a.results,
-		JobTitle:   a.jobDesc.Title,
-		Timestamp:  time.Now().Format(time.RFC3339),
+		Applicants:       a.results,
+		JobTitle:         a.jobDesc.Title,
+		JobDescription:   &jobDesc,
+		Timestamp:        time.Now().Format(time.RFC3339),
+		UnprocessedFiles: a.unprocessed,
+		Total:            len(a.results),
 	}, nil
 }
 
@@ -358,6 +600,14 @@ func (a *CVReviewAgent) GetResults() []models.ApplicantResult {
 	return resultsCopy
 }
 
+// FailedApplicants returns the applicants the last run could not score; they
+// are left out of the results
+func (a *CVReviewAgent) FailedApplicants() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string(nil), a.failed...)
+}
+
 // GetJobDescription returns the current job description (thread-safe)
 func (a *CVReviewAgent) GetJobDescription() models.JobDescription {
 	a.mu.RLock()
@@ -367,8 +617,12 @@ func (a *CVReviewAgent) GetJobDescription() models.JobDescription {
 
 // Close cleans up resources
 func (a *CVReviewAgent) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 	if a.llmClient != nil {
-		return a.llmClient.Close()
+		err := a.llmClient.Close()
+		a.llmClient, a.scorer = nil, nil
+		return err
 	}
 	return nil
 }
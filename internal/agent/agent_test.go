@@ -1,8 +1,11 @@
 package agent
 
 import (
+	"context"
 	"errors"
+	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/fmuoria/CV-Review-agent/internal/models"
@@ -194,3 +197,238 @@ func TestSortingWithTieBreaking(t *testing.T) {
 		})
 	}
 }
+
+// TestApplicantFingerprint tests that only document changes alter the fingerprint
+func TestApplicantFingerprint(t *testing.T) {
+	doc := models.ApplicantDocument{
+		Name: "JaneSmith",
+		Documents: []models.Document{
+			{Type: models.DocumentCV, Path: "uploads/JaneSmith_CV.pdf", Content: "Jane Smith CV"},
+		},
+	}
+	base := applicantFingerprint(doc)
+
+	if applicantFingerprint(doc) != base {
+		t.Error("Fingerprint should be stable")
+	}
+
+	changed := doc
+	changed.Documents = []models.Document{{Type: models.DocumentCV, Path: "uploads/JaneSmith_CV.pdf", Content: "Jane Smith CV, updated"}}
+	if applicantFingerprint(changed) == base {
+		t.Error("Changed content should change the fingerprint")
+	}
+
+	added := doc
+	added.Documents = append([]models.Document{}, doc.Documents...)
+	added.Documents = append(added.Documents, models.Document{Type: models.DocumentCoverLetter, Path: "uploads/JaneSmith_CL.pdf", Content: "Dear Hiring Manager"})
+	if applicantFingerprint(added) == base {
+		t.Error("A new document should change the fingerprint")
+	}
+}
+
+// TestSelectForRescore tests which applicants are scored again
+func TestSelectForRescore(t *testing.T) {
+	documents := []models.ApplicantDocument{{Name: "JaneSmith"}, {Name: "JohnDoe"}, {Name: "AnnLee"}}
+	previous := []models.ApplicantResult{{Name: "JaneSmith"}, {Name: "JohnDoe"}, {Name: "Gone"}}
+
+	tests := []struct {
+		name        string
+		names       []string
+		wantPending string
+		wantKept    string
+	}{
+		{"everyone", nil, "JaneSmith,JohnDoe,AnnLee", ""},
+		{"named", []string{"janesmith"}, "JaneSmith,AnnLee", "JohnDoe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, kept := selectForRescore(documents, previous, tt.names)
+			var pendingNames, keptNames []string
+			for _, doc := range pending {
+				pendingNames = append(pendingNames, doc.Name)
+			}
+			for _, result := range kept {
+				keptNames = append(keptNames, result.Name)
+			}
+			if got := strings.Join(pendingNames, ","); got != tt.wantPending {
+				t.Errorf("pending = %s, want %s", got, tt.wantPending)
+			}
+			if got := strings.Join(keptNames, ","); got != tt.wantKept {
+				t.Errorf("kept = %s, want %s", got, tt.wantKept)
+			}
+		})
+	}
+}
+
+// TestApplicantID tests that names become URL-safe IDs
+func TestApplicantID(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Jane Doe", "jane-doe"},
+		{"  O'Brien,  Mary-Kate ", "o-brien-mary-kate"},
+		{"Applicant 42", "applicant-42"},
+		{"José", "jos"},
+		{"!!!", "applicant"},
+		{"", "applicant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applicantID(tt.name); got != tt.expected {
+				t.Errorf("applicantID(%q) = %q, want %q", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRankResultsAssignsStableIDs tests that IDs are unique and do not change with ranks
+func TestRankResultsAssignsStableIDs(t *testing.T) {
+	cv := func(path string) []models.Document {
+		return []models.Document{{Type: models.DocumentCV, Path: path}}
+	}
+	results := []models.ApplicantResult{
+		{Name: "Jane Doe", Scores: models.Scores{TotalScore: 50}, Documents: cv("uploads/Jane Doe/CV.pdf")},
+		{Name: "jane doe", Scores: models.Scores{TotalScore: 70}, Documents: cv("uploads/jane doe/CV.pdf")},
+		{Name: "John Smith", Scores: models.Scores{TotalScore: 60}, Documents: cv("uploads/John Smith/CV.pdf")},
+		{Name: "John Smith", Scores: models.Scores{TotalScore: 40}, Documents: cv("other/John Smith/CV.pdf")},
+	}
+	rankResults(results)
+
+	ids := make(map[string]string)
+	for i, result := range results {
+		if result.Rank != i+1 {
+			t.Errorf("results[%d].Rank = %d, want %d", i, result.Rank, i+1)
+		}
+		if !strings.HasPrefix(result.ID, applicantID(result.Name)+"-") {
+			t.Errorf("results[%d].ID = %q, want %s-<hash>", i, result.ID, applicantID(result.Name))
+		}
+		if _, ok := ids[result.ID]; ok {
+			t.Errorf("results[%d].ID = %q is not unique", i, result.ID)
+		}
+		ids[result.ID] = result.Documents[0].Path
+	}
+	if results[3].ID != results[1].ID+"-2" {
+		t.Errorf("same name and files: ID = %q, want %q", results[3].ID, results[1].ID+"-2")
+	}
+
+	// Rescoring changes the ranks but not the IDs
+	results[0].Scores.TotalScore = 10
+	rankResults(results)
+	for _, result := range results {
+		if path, ok := ids[result.ID]; !ok || path != result.Documents[0].Path {
+			t.Errorf("after rescoring %q has ID %q, was it %q?", result.Documents[0].Path, result.ID, path)
+		}
+	}
+}
+
+// TestGetApplicant tests applicant lookup and document access
+func TestGetApplicant(t *testing.T) {
+	a := NewCVReviewAgent()
+	a.jobDesc = models.JobDescription{Title: "Data Analyst"}
+	results := []models.ApplicantResult{{
+		Name:      "Jane Doe",
+		Scores:    models.Scores{TotalScore: 80},
+		Emails:    []string{"jane@example.com"},
+		Documents: []models.Document{{Type: models.DocumentCV, Path: "uploads/Jane Doe/CV_Jane.pdf"}},
+	}}
+	rankResults(results)
+	a.results = results
+	a.documents = map[string]models.ApplicantDocument{
+		"Jane Doe": {Name: "Jane Doe", Documents: []models.Document{
+			{Type: models.DocumentCV, Path: "uploads/Jane Doe/CV_Jane.pdf", Content: "Five years of SQL"},
+		}},
+	}
+
+	id := results[0].ID
+	detail, err := a.GetApplicant(id)
+	if err != nil {
+		t.Fatalf("GetApplicant() error = %v", err)
+	}
+	if detail.JobID != a.JobID() || !strings.HasPrefix(detail.JobID, "data-analyst-") {
+		t.Errorf("JobID = %q, want data-analyst-<hash>", detail.JobID)
+	}
+	if len(detail.Documents) != 1 || detail.Documents[0].Name != "CV_Jane.pdf" || detail.Documents[0].Text != "Five years of SQL" {
+		t.Errorf("Documents = %+v", detail.Documents)
+	}
+	if len(detail.Profile.Emails) != 1 {
+		t.Errorf("Profile.Emails = %v", detail.Profile.Emails)
+	}
+
+	if _, err := a.GetApplicant("john-smith"); !errors.Is(err, ErrApplicantNotFound) {
+		t.Errorf("GetApplicant(unknown) error = %v, want ErrApplicantNotFound", err)
+	}
+
+	path, err := a.ApplicantDocumentPath(id, "CV_Jane.pdf")
+	if err != nil || path != "uploads/Jane Doe/CV_Jane.pdf" {
+		t.Errorf("ApplicantDocumentPath() = %q, %v", path, err)
+	}
+	for _, name := range []string{"../../etc/passwd", "other.pdf", ""} {
+		if _, err := a.ApplicantDocumentPath(id, name); !errors.Is(err, ErrApplicantNotFound) {
+			t.Errorf("ApplicantDocumentPath(%q) error = %v, want ErrApplicantNotFound", name, err)
+		}
+	}
+}
+
+// TestFailedApplicants tests that applicants that could not be scored are reported
+func TestFailedApplicants(t *testing.T) {
+	a := NewCVReviewAgent()
+	useFakeScorer(a, &fakeScorer{fail: "John Doe"}, &fakeCloser{})
+	if err := a.initScorer(); err != nil {
+		t.Fatalf("initScorer() error = %v", err)
+	}
+	documents := []models.ApplicantDocument{
+		{Name: "Jane Smith", Documents: []models.Document{{Type: models.DocumentCV, Content: "Go"}}},
+		{Name: "John Doe", Documents: []models.Document{{Type: models.DocumentCV, Content: "Go"}}},
+	}
+	if err := a.processApplicants(context.Background(), documents, nil); err != nil {
+		t.Fatalf("processApplicants() error = %v", err)
+	}
+
+	if got := a.FailedApplicants(); len(got) != 1 || got[0] != "John Doe" {
+		t.Errorf("FailedApplicants() = %v, want [John Doe]", got)
+	}
+	if got := a.GetResults(); len(got) != 1 || got[0].Name != "Jane Smith" {
+		t.Errorf("GetResults() = %+v, want only Jane Smith", got)
+	}
+}
+
+// TestRescoreApplicant_ResultsChanged tests that a single rescore is not merged
+// into results replaced while it was scoring
+func TestRescoreApplicant_ResultsChanged(t *testing.T) {
+	a := NewCVReviewAgent()
+	scorer := &fakeScorer{}
+	useFakeScorer(a, scorer, &fakeCloser{})
+	if err := a.initScorer(); err != nil {
+		t.Fatalf("initScorer() error = %v", err)
+	}
+	documents := []models.ApplicantDocument{
+		{Name: "Jane Smith", Documents: []models.Document{{Type: models.DocumentCV, Path: "Jane Smith/CV.txt", Content: "Go"}}},
+		{Name: "John Doe", Documents: []models.Document{{Type: models.DocumentCV, Path: "John Doe/CV.txt", Content: "Go"}}},
+	}
+	a.documents = map[string]models.ApplicantDocument{"Jane Smith": documents[0], "John Doe": documents[1]}
+	if err := a.processApplicants(context.Background(), documents, nil); err != nil {
+		t.Fatalf("processApplicants() error = %v", err)
+	}
+	id := a.GetResults()[0].ID
+
+	if _, err := a.RescoreApplicantWithContext(context.Background(), id); err != nil {
+		t.Fatalf("RescoreApplicantWithContext() error = %v", err)
+	}
+
+	// Another job description is set while the applicant is being scored
+	scorer.onScore = func(doc models.ApplicantDocument) {
+		if err := a.setJobDescription(`{"title": "Data Engineer"}`); err != nil {
+			t.Errorf("setJobDescription() error = %v", err)
+		}
+	}
+	before := a.GetResults()
+	if _, err := a.RescoreApplicantWithContext(context.Background(), id); !errors.Is(err, ErrResultsChanged) {
+		t.Fatalf("RescoreApplicantWithContext() error = %v, want ErrResultsChanged", err)
+	}
+	if after := a.GetResults(); !reflect.DeepEqual(after, before) {
+		t.Errorf("Results changed by a discarded rescore: %+v", after)
+	}
+}
@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// ErrApplicantNotFound is returned for an unknown applicant ID or document
+var ErrApplicantNotFound = errors.New("applicant not found")
+
+// ErrResultsChanged is returned when the job or results are replaced while an
+// applicant is rescored
+var ErrResultsChanged = errors.New("the job or results changed while rescoring, try again")
+
+// JobID identifies the job the current results were scored against
+func (a *CVReviewAgent) JobID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return jobIDFor(a.jobDesc)
+}
+
+// jobIDFor returns the library ID of a stored job description. Other job
+// descriptions get an ID from the title and a hash of their content, so
+// editing the description gives a new ID.
+func jobIDFor(jobDesc models.JobDescription) string {
+	if jobDesc.ID != "" {
+		return jobDesc.ID
+	}
+	data, _ := json.Marshal(jobDesc)
+	sum := sha256.Sum256(data)
+	return applicantID(jobDesc.Title) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// applicantID turns a name into a URL-safe ID ("Jane Doe" -> "jane-doe")
+func applicantID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		return "applicant"
+	}
+	return id
+}
+
+// resultID identifies an applicant by name and file names, so the ID does not
+// change with scores or ranks ("Jane Doe" -> "jane-doe-1a2b3c4d")
+func resultID(result models.ApplicantResult) string {
+	var files []string
+	for _, d := range result.Documents {
+		files = append(files, filepath.Base(d.Path))
+	}
+	if len(files) == 0 {
+		// Results saved before documents were listed
+		for _, path := range []string{result.CVPath, result.CLPath} {
+			if path != "" {
+				files = append(files, filepath.Base(path))
+			}
+		}
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", result.Name)
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00", file)
+	}
+	return applicantID(result.Name) + "-" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// findResult returns the index of the result with the given ID; callers hold the lock
+func (a *CVReviewAgent) findResult(id string) int {
+	for i, result := range a.results {
+		if result.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetApplicant returns the scores, profile and documents of one applicant.
+// Extracted text is included while the applicant's files are loaded.
+func (a *CVReviewAgent) GetApplicant(id string) (models.ApplicantDetail, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	i := a.findResult(id)
+	if i < 0 {
+		return models.ApplicantDetail{}, ErrApplicantNotFound
+	}
+	result := a.results[i]
+
+	detail := models.ApplicantDetail{
+		ID:     result.ID,
+		JobID:  jobIDFor(a.jobDesc),
+		Name:   result.Name,
+		Rank:   result.Rank,
+		Scores: result.Scores,
+		Profile: models.ApplicantProfile{
+			Emails:        result.Emails,
+			Phones:        result.Phones,
+			NeedsReview:   result.NeedsReview,
+			ReviewReasons: result.ReviewReasons,
+			OCRPages:      result.OCRPages,
+		},
+		Documents: []models.ApplicantFile{},
+	}
+
+	documents := result.Documents
+	if doc, ok := a.documents[result.Name]; ok {
+		documents = doc.Documents
+	}
+	for _, d := range documents {
+		detail.Documents = append(detail.Documents, models.ApplicantFile{
+			Name: filepath.Base(d.Path),
+			Type: d.Type,
+			Text: d.Content,
+		})
+	}
+	return detail, nil
+}
+
+// ApplicantDocumentPath returns the path of one of an applicant's files by
+// file name. Only files recorded for the applicant are returned.
+func (a *CVReviewAgent) ApplicantDocumentPath(id, fileName string) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	i := a.findResult(id)
+	if i < 0 {
+		return "", ErrApplicantNotFound
+	}
+	for _, d := range a.results[i].Documents {
+		if d.Path != "" && filepath.Base(d.Path) == fileName {
+			return d.Path, nil
+		}
+	}
+	return "", fmt.Errorf("document %q: %w", fileName, ErrApplicantNotFound)
+}
+
+// RescoreApplicantWithContext scores one applicant again against the current
+// job description and re-ranks the results
+func (a *CVReviewAgent) RescoreApplicantWithContext(ctx context.Context, id string) (models.ApplicantResult, error) {
+	a.mu.RLock()
+	i := a.findResult(id)
+	var doc models.ApplicantDocument
+	var loaded bool
+	if i >= 0 {
+		doc, loaded = a.documents[a.results[i].Name]
+	}
+	generation := a.generation
+	a.mu.RUnlock()
+
+	if i < 0 {
+		return models.ApplicantResult{}, ErrApplicantNotFound
+	}
+	if !loaded {
+		return models.ApplicantResult{}, fmt.Errorf("documents for %s are no longer loaded, run ingestion again", id)
+	}
+
+	scorer, jobDesc, current, err := a.ensureScorer()
+	if err != nil {
+		return models.ApplicantResult{}, err
+	}
+	if current != generation {
+		return models.ApplicantResult{}, ErrResultsChanged
+	}
+
+	a.reportProgress(0, 100, fmt.Sprintf("Evaluating %s", doc.Name))
+	result, err := a.scoreApplicant(ctx, scorer, jobDesc, doc, 0)
+	if err != nil {
+		return models.ApplicantResult{}, fmt.Errorf("failed to score %s: %w", doc.Name, err)
+	}
+
+	a.mu.Lock()
+	// A result scored against another job or merged into new results would be wrong
+	if a.generation != generation {
+		a.mu.Unlock()
+		return models.ApplicantResult{}, ErrResultsChanged
+	}
+	results := make([]models.ApplicantResult, 0, len(a.results))
+	for _, r := range a.results {
+		if r.Name != doc.Name {
+			results = append(results, r)
+		}
+	}
+	results = append(results, result)
+	rankResults(results)
+	a.results = results
+	for _, r := range results {
+		if r.Name == doc.Name {
+			result = r
+		}
+	}
+	a.mu.Unlock()
+
+	a.reportProgress(100, 100, fmt.Sprintf("%s is now ranked #%d", result.Name, result.Rank))
+	return result, nil
+}
+
+// ensureScorer returns the scorer, job description and generation, creating the
+// scorer on first use. The lock is held throughout, so concurrent callers share one client.
+func (a *CVReviewAgent) ensureScorer() (Scorer, models.JobDescription, uint64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.scorer == nil {
+		scorer, llmClient, err := a.newScorer()
+		if err != nil {
+			return nil, models.JobDescription{}, 0, err
+		}
+		a.llmClient, a.scorer = llmClient, scorer
+	}
+	return a.scorer, a.jobDesc, a.generation, nil
+}
+
+// initScorer creates the LLM client and scorer, closing the previous client
+func (a *CVReviewAgent) initScorer() error {
+	a.mu.RLock()
+	newScorer := a.newScorer
+	a.mu.RUnlock()
+	scorer, llmClient, err := newScorer()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	previous := a.llmClient
+	a.llmClient, a.scorer = llmClient, scorer
+	a.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// ErrInvalidQuery is returned for report queries with unknown fields or a bad cursor
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Report statuses accepted by ReportQuery.Status
+const (
+	StatusNeedsReview = "needs_review"
+	StatusOK          = "ok"
+)
+
+// SortFields lists the fields a report can be sorted by
+var SortFields = []string{"rank", "name", "total_score", "experience_score", "education_score", "duties_score", "cover_letter_score"}
+
+// reportCursor marks the last applicant of a page. It holds the sort key
+// rather than an offset, so pages stay consistent when applicants are rescored.
+// Set identifies the job and applicants the cursor was issued for.
+type reportCursor struct {
+	Sort  string  `json:"s"`
+	Desc  bool    `json:"d,omitempty"`
+	Set   string  `json:"j"`
+	Value float64 `json:"v,omitempty"`
+	Name  string  `json:"n,omitempty"`
+	Rank  int     `json:"r,omitempty"`
+	ID    string  `json:"i"`
+}
+
+// QueryReport returns the report with its applicants filtered, sorted and
+// paged by q
+func (a *CVReviewAgent) QueryReport(q models.ReportQuery) (models.ReportResponse, error) {
+	report, err := a.GetReport()
+	if err != nil {
+		return report, err
+	}
+	page, total, next, err := queryResults(report.Applicants, jobIDFor(*report.JobDescription), q)
+	if err != nil {
+		return models.ReportResponse{}, err
+	}
+	report.Applicants = page
+	report.Total = total
+	report.NextCursor = next
+	return report, nil
+}
+
+// queryResults applies q to the ranked results of a job. Ties on the sort
+// field are ordered by applicant ID, which does not change when rescoring.
+func queryResults(results []models.ApplicantResult, jobID string, q models.ReportQuery) ([]models.ApplicantResult, int, string, error) {
+	if q.Sort == "" {
+		q.Sort = "rank"
+	}
+	if !isSortField(q.Sort) {
+		return nil, 0, "", fmt.Errorf("%w: unknown sort field %q (use one of %s)", ErrInvalidQuery, q.Sort, strings.Join(SortFields, ", "))
+	}
+	if q.Status != "" && q.Status != StatusNeedsReview && q.Status != StatusOK {
+		return nil, 0, "", fmt.Errorf("%w: unknown status %q (use %s or %s)", ErrInvalidQuery, q.Status, StatusNeedsReview, StatusOK)
+	}
+	if q.Limit < 0 {
+		return nil, 0, "", fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery)
+	}
+
+	set := resultSetKey(jobID, results)
+	var after *reportCursor
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil || c.Sort != q.Sort || c.Desc != q.Descending {
+			return nil, 0, "", fmt.Errorf("%w: cursor does not match this query", ErrInvalidQuery)
+		}
+		if c.Set != set {
+			return nil, 0, "", fmt.Errorf("%w: cursor was issued for other results, start from the first page", ErrInvalidQuery)
+		}
+		after = &c
+	}
+
+	matched := make([]models.ApplicantResult, 0, len(results))
+	for _, result := range results {
+		if matchesQuery(result, q) {
+			matched = append(matched, result)
+		}
+	}
+
+	less := func(x, y reportCursor) bool {
+		if q.Sort == "name" && x.Name != y.Name {
+			return (x.Name < y.Name) != q.Descending
+		}
+		if q.Sort != "name" && q.Sort != "rank" && x.Value != y.Value {
+			return (x.Value < y.Value) != q.Descending
+		}
+		if q.Sort == "rank" && x.Rank != y.Rank {
+			return (x.Rank < y.Rank) != q.Descending
+		}
+		return x.ID < y.ID
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return less(cursorFor(matched[i], set, q), cursorFor(matched[j], set, q))
+	})
+
+	total := len(matched)
+	if after != nil {
+		start := sort.Search(len(matched), func(i int) bool {
+			return less(*after, cursorFor(matched[i], set, q))
+		})
+		matched = matched[start:]
+	}
+
+	next := ""
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+		next = encodeCursor(cursorFor(matched[len(matched)-1], set, q))
+	}
+	return matched, total, next, nil
+}
+
+// matchesQuery reports whether a result passes the filters of q
+func matchesQuery(result models.ApplicantResult, q models.ReportQuery) bool {
+	s := result.Scores
+	if s.TotalScore < q.MinTotal || s.ExperienceScore < q.MinExperience ||
+		s.EducationScore < q.MinEducation || s.DutiesScore < q.MinDuties ||
+		s.CoverLetterScore < q.MinCoverLetter {
+		return false
+	}
+	if q.Name != "" && !strings.Contains(strings.ToLower(result.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	switch q.Status {
+	case StatusNeedsReview:
+		return result.NeedsReview
+	case StatusOK:
+		return !result.NeedsReview
+	}
+	return true
+}
+
+// cursorFor returns the sort key of a result
+func cursorFor(result models.ApplicantResult, set string, q models.ReportQuery) reportCursor {
+	c := reportCursor{Sort: q.Sort, Desc: q.Descending, Set: set, ID: result.ID}
+	switch q.Sort {
+	case "rank":
+		c.Rank = result.Rank
+	case "name":
+		c.Name = strings.ToLower(result.Name)
+	case "total_score":
+		c.Value = result.Scores.TotalScore
+	case "experience_score":
+		c.Value = result.Scores.ExperienceScore
+	case "education_score":
+		c.Value = result.Scores.EducationScore
+	case "duties_score":
+		c.Value = result.Scores.DutiesScore
+	case "cover_letter_score":
+		c.Value = result.Scores.CoverLetterScore
+	}
+	return c
+}
+
+// resultSetKey identifies a job and its applicants, whatever their scores
+func resultSetKey(jobID string, results []models.ApplicantResult) string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	h.Write([]byte(jobID))
+	for _, id := range ids {
+		h.Write([]byte("\x00" + id))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// isSortField reports whether name is one of SortFields
+func isSortField(name string) bool {
+	for _, field := range SortFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor makes an opaque cursor string
+func encodeCursor(c reportCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor made by encodeCursor
+func decodeCursor(s string) (reportCursor, error) {
+	var c reportCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// queryTestResults returns ranked results for the query tests
+func queryTestResults() []models.ApplicantResult {
+	results := []models.ApplicantResult{
+		{Name: "Alice", Scores: models.Scores{TotalScore: 90, ExperienceScore: 45, EducationScore: 20}},
+		{Name: "Bob", Scores: models.Scores{TotalScore: 70, ExperienceScore: 30, EducationScore: 15}, NeedsReview: true},
+		{Name: "Carol", Scores: models.Scores{TotalScore: 70, ExperienceScore: 35, EducationScore: 10}},
+		{Name: "Dave", Scores: models.Scores{TotalScore: 40, ExperienceScore: 20, EducationScore: 20}},
+		{Name: "Eve", Scores: models.Scores{TotalScore: 55, ExperienceScore: 25, EducationScore: 5}, NeedsReview: true},
+	}
+	rankResults(results)
+	return results
+}
+
+// names returns the names of results in order
+func names(results []models.ApplicantResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.Name
+	}
+	return out
+}
+
+// TestQueryResults tests report filtering and sorting
+func TestQueryResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    models.ReportQuery
+		expected []string
+	}{
+		{"Default is the ranking", models.ReportQuery{}, []string{"Alice", "Carol", "Bob", "Eve", "Dave"}},
+		{"Total score descending orders ties by ID", models.ReportQuery{Sort: "total_score", Descending: true}, []string{"Alice", "Bob", "Carol", "Eve", "Dave"}},
+		{"Total score ascending orders ties by ID", models.ReportQuery{Sort: "total_score"}, []string{"Dave", "Eve", "Bob", "Carol", "Alice"}},
+		{"Minimum total", models.ReportQuery{MinTotal: 70}, []string{"Alice", "Carol", "Bob"}},
+		{"Category minimum", models.ReportQuery{MinEducation: 15}, []string{"Alice", "Bob", "Dave"}},
+		{"Name search", models.ReportQuery{Name: "A"}, []string{"Alice", "Carol", "Dave"}},
+		{"Needs review", models.ReportQuery{Status: StatusNeedsReview}, []string{"Bob", "Eve"}},
+		{"OK", models.ReportQuery{Status: StatusOK, Sort: "name", Descending: true}, []string{"Dave", "Carol", "Alice"}},
+		{"Rank descending", models.ReportQuery{Sort: "rank", Descending: true}, []string{"Dave", "Eve", "Bob", "Carol", "Alice"}},
+		{"Education descending", models.ReportQuery{Sort: "education_score", Descending: true}, []string{"Alice", "Dave", "Bob", "Carol", "Eve"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, next, err := queryResults(queryTestResults(), "go-engineer", tt.query)
+			if err != nil {
+				t.Fatalf("queryResults() error = %v", err)
+			}
+			got := names(page)
+			if len(got) != len(tt.expected) || total != len(tt.expected) || next != "" {
+				t.Fatalf("got %v (total %d, next %q), want %v", got, total, next, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("got %v, want %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+// TestQueryResultsPagination tests that following cursors visits every applicant once
+func TestQueryResultsPagination(t *testing.T) {
+	for _, query := range []models.ReportQuery{
+		{Limit: 2},
+		{Limit: 2, Sort: "total_score"},
+		{Limit: 1, Sort: "name", Descending: true},
+		{Limit: 3, Sort: "experience_score", Descending: true},
+	} {
+		full, _, _, err := queryResults(queryTestResults(), "go-engineer", models.ReportQuery{Sort: query.Sort, Descending: query.Descending})
+		if err != nil {
+			t.Fatalf("queryResults() error = %v", err)
+		}
+
+		var got []string
+		for pages := 0; ; pages++ {
+			if pages > len(full) {
+				t.Fatalf("%+v: cursor did not end", query)
+			}
+			page, total, next, err := queryResults(queryTestResults(), "go-engineer", query)
+			if err != nil {
+				t.Fatalf("%+v: queryResults() error = %v", query, err)
+			}
+			if total != len(full) {
+				t.Errorf("%+v: total = %d, want %d", query, total, len(full))
+			}
+			got = append(got, names(page)...)
+			if next == "" {
+				break
+			}
+			query.Cursor = next
+		}
+
+		want := names(full)
+		if len(got) != len(want) {
+			t.Fatalf("%+v: pages = %v, want %v", query, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%+v: pages = %v, want %v", query, got, want)
+			}
+		}
+	}
+}
+
+// TestQueryResultsInvalid tests that bad queries are rejected
+func TestQueryResultsInvalid(t *testing.T) {
+	_, _, next, _ := queryResults(queryTestResults(), "go-engineer", models.ReportQuery{Limit: 1, Sort: "name"})
+
+	for _, query := range []models.ReportQuery{
+		{Sort: "salary"},
+		{Status: "hired"},
+		{Limit: -1},
+		{Cursor: "not a cursor"},
+		{Cursor: next, Sort: "total_score"},
+	} {
+		if _, _, _, err := queryResults(queryTestResults(), "go-engineer", query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%+v: error = %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+// TestQueryResultsCursorResultSet tests that cursors are only accepted for the
+// job and applicants they were issued for, whatever their scores
+func TestQueryResultsCursorResultSet(t *testing.T) {
+	query := models.ReportQuery{Sort: "total_score", Descending: true, Limit: 2}
+	first, _, next, err := queryResults(queryTestResults(), "go-engineer", query)
+	if err != nil || next == "" {
+		t.Fatalf("queryResults() = %v, %q, %v", names(first), next, err)
+	}
+	query.Cursor = next
+
+	if _, _, _, err := queryResults(queryTestResults(), "data-engineer", query); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Other job: error = %v, want ErrInvalidQuery", err)
+	}
+	if _, _, _, err := queryResults(queryTestResults()[:4], "go-engineer", query); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("Other applicants: error = %v, want ErrInvalidQuery", err)
+	}
+
+	// Rescoring Dave above the cursor re-ranks him but does not invalidate it
+	results := queryTestResults()
+	for i := range results {
+		if results[i].Name == "Dave" {
+			results[i].Scores.TotalScore = 95
+		}
+	}
+	rankResults(results)
+	page, _, _, err := queryResults(results, "go-engineer", query)
+	if err != nil {
+		t.Fatalf("After rescoring: error = %v", err)
+	}
+	if got := names(page); len(got) != 2 || got[0] != "Carol" || got[1] != "Eve" {
+		t.Errorf("After rescoring: page = %v, want [Carol Eve]", got)
+	}
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// watchDebounce is how long a watched folder must be quiet before it is scored
+var watchDebounce = ingestion.DefaultWatchDebounce
+
+// watchSession is the state of one folder watch. It is kept apart from the
+// agent's ingestion results, so watching does not replace them.
+type watchSession struct {
+	fh      *ingestion.FileHandler
+	scorer  Scorer
+	jobDesc models.JobDescription
+	scored  map[string]models.ApplicantResult // by applicantFingerprint
+}
+
+// WatchFolderWithContext keeps a ranking of the applications in dir up to date.
+// The folder is evaluated once at start and again whenever files land in it;
+// only new or changed applicants are scored, and applicants whose files are
+// removed drop out of the ranking. onUpdate (if set) receives the new ranking
+// after each pass. Watching runs until ctx is cancelled. The agent's own
+// results, as returned by GetResults, are not changed.
+func (a *CVReviewAgent) WatchFolderWithContext(ctx context.Context, dir string, jobDescJSON string, onUpdate func([]models.ApplicantResult)) error {
+	// Parse job description
+	var jobDesc models.JobDescription
+	if err := json.Unmarshal([]byte(jobDescJSON), &jobDesc); err != nil {
+		return fmt.Errorf("failed to parse job description: %w", err)
+	}
+
+	a.reportProgress(0, 100, "Initializing LLM client...")
+
+	// The watch has its own LLM client, closed when watching stops
+	a.mu.RLock()
+	newScorer := a.newScorer
+	a.mu.RUnlock()
+	scorer, llmClient, err := newScorer()
+	if err != nil {
+		return err
+	}
+	defer llmClient.Close()
+
+	// Watch before the first pass, so files landing during it are not missed
+	watcher, err := ingestion.NewFolderWatcher(dir)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	session := &watchSession{
+		fh:      ingestion.NewFileHandler(dir),
+		scorer:  scorer,
+		jobDesc: jobDesc,
+		scored:  make(map[string]models.ApplicantResult),
+	}
+	update := func() error {
+		results, err := a.updateFolderResults(ctx, session)
+		if err != nil {
+			return err
+		}
+		if onUpdate != nil {
+			onUpdate(results)
+		}
+		return nil
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+
+	return watcher.Run(ctx, watchDebounce, func() {
+		if err := update(); err != nil && ctx.Err() == nil {
+			log.Printf("Warning: failed to update results for %s: %v", dir, err)
+		}
+	})
+}
+
+// updateFolderResults loads the applicants in the session's folder, scores
+// those not scored yet and returns the new ranking. The session's scored
+// applicants are updated to hold only the applicants still present.
+func (a *CVReviewAgent) updateFolderResults(ctx context.Context, session *watchSession) ([]models.ApplicantResult, error) {
+	documents, err := session.fh.LoadDocuments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load documents: %w", err)
+	}
+	scored := session.scored
+
+	var pending []models.ApplicantDocument
+	current := make(map[string]bool, len(documents))
+	for _, doc := range documents {
+		key := applicantFingerprint(doc)
+		current[key] = true
+		if _, ok := scored[key]; !ok {
+			pending = append(pending, doc)
+		}
+	}
+
+	// Forget applicants whose files were removed or replaced
+	for key := range scored {
+		if !current[key] {
+			delete(scored, key)
+		}
+	}
+
+	if len(pending) > 0 {
+		log.Printf("Found %d new or changed applicant(s) to evaluate", len(pending))
+	}
+	for i, doc := range pending {
+		if i > 0 {
+			// Rate limiting delay between requests
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(requestDelay):
+			}
+		}
+
+		progress := 100 * i / len(pending)
+		a.reportProgress(progress, 100, fmt.Sprintf("Evaluating %s (%d/%d new)", doc.Name, i+1, len(pending)))
+
+		// Failed applicants are not cached, so the next change retries them
+		if result, err := a.scoreApplicant(ctx, session.scorer, session.jobDesc, doc, progress); err == nil {
+			scored[applicantFingerprint(doc)] = result
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	results := make([]models.ApplicantResult, 0, len(scored))
+	for _, result := range scored {
+		results = append(results, result)
+	}
+	rankResults(results)
+
+	a.reportProgress(100, 100, fmt.Sprintf("Watching folder - %d applicant(s) ranked", len(results)))
+
+	return results, nil
+}
+
+// applicantFingerprint identifies an applicant's documents, so an applicant is
+// scored again only when a file is added, removed or changed
+func applicantFingerprint(doc models.ApplicantDocument) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", doc.Name)
+	for _, d := range doc.Documents {
+		fmt.Fprintf(h, "%s\x00%s\x00%d\x00", d.Type, d.Path, len(d.Content))
+		h.Write([]byte(d.Content))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
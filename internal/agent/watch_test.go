@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// fakeScorer calls onScore, then scores every applicant 50 except fail, which gets an error
+type fakeScorer struct {
+	calls   atomic.Int32
+	fail    string
+	onScore func(doc models.ApplicantDocument)
+}
+
+func (f *fakeScorer) ScoreApplicant(ctx context.Context, doc models.ApplicantDocument, jobDesc models.JobDescription) (models.Scores, error) {
+	f.calls.Add(1)
+	if f.onScore != nil {
+		f.onScore(doc)
+	}
+	if doc.Name == f.fail {
+		return models.Scores{}, errors.New("invalid response")
+	}
+	return models.Scores{ExperienceScore: 25, TotalScore: 50, ExperienceReasoning: "ok"}, nil
+}
+
+// fakeCloser records whether the LLM client was closed
+type fakeCloser struct{ closed atomic.Bool }
+
+func (f *fakeCloser) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+
+// useFakeScorer makes the agent score with scorer, using closer as its client
+func useFakeScorer(a *CVReviewAgent, scorer Scorer, closer io.Closer) {
+	a.newScorer = func() (Scorer, io.Closer, error) { return scorer, closer, nil }
+}
+
+// TestWatchFolder_KeepsStateSeparate tests that a folder watch ranks files
+// landing during its first pass and never replaces the agent's own results.
+// Run with -race: the agent is read and rescored while the watch updates.
+func TestWatchFolder_KeepsStateSeparate(t *testing.T) {
+	previousDebounce := watchDebounce
+	watchDebounce = 100 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = previousDebounce })
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "JaneSmith_CV.txt"), []byte("Jane Smith, five years of Go"), 0644)
+
+	// A second CV lands while the first pass is scoring
+	var once sync.Once
+	scorer := &fakeScorer{onScore: func(models.ApplicantDocument) {
+		once.Do(func() {
+			os.WriteFile(filepath.Join(dir, "JohnDoe_CV.txt"), []byte("John Doe, three years of Go"), 0644)
+		})
+	}}
+	closer := &fakeCloser{}
+	a := NewCVReviewAgent()
+	useFakeScorer(a, scorer, closer)
+	ingested := []models.ApplicantResult{{Name: "Ingested", Scores: models.Scores{TotalScore: 90}}}
+	rankResults(ingested)
+	a.results = ingested
+	a.jobDesc = models.JobDescription{Title: "Data Analyst"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan []models.ApplicantResult, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- a.WatchFolderWithContext(ctx, dir, `{"title": "Go Engineer"}`, func(results []models.ApplicantResult) {
+			updates <- results
+		})
+	}()
+
+	// Use the agent concurrently with the watch
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			a.GetResults()
+			a.JobID()
+			a.GetReport()
+			a.SetProgressCallback(func(int, int, string) {})
+			a.setJobDescription(`{"title": "Data Analyst"}`)
+		}
+	}()
+
+	deadline := time.After(5 * time.Second)
+	var results []models.ApplicantResult
+	for len(results) < 2 {
+		select {
+		case results = <-updates:
+		case <-deadline:
+			t.Fatalf("The CV dropped during the first pass was not ranked, last ranking: %+v", results)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !closer.closed.Load() {
+		t.Error("Expected the watch's LLM client to be closed")
+	}
+	if got := scorer.calls.Load(); got != 2 {
+		t.Errorf("Expected each applicant to be scored once, got %d calls", got)
+	}
+
+	if got := a.GetResults(); len(got) != 1 || got[0].Name != "Ingested" {
+		t.Errorf("Watching replaced the agent's results: %+v", got)
+	}
+	if got := a.GetJobDescription().Title; got != "Data Analyst" {
+		t.Errorf("Watching replaced the agent's job description: %q", got)
+	}
+}
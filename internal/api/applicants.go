@@ -0,0 +1,155 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/fmuoria/CV-Review-agent/internal/agent"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// currentJob may be used in place of the job ID to address the latest results
+const currentJob = "current"
+
+// checkJob verifies that the {id} path value names the job of the current
+// results, responding with 404 otherwise
+func (s *Server) checkJob(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if len(s.agent.GetResults()) == 0 {
+		s.respondError(w, http.StatusNotFound, "no results available, run ingestion first")
+		return "", false
+	}
+	jobID := s.agent.JobID()
+	if id := r.PathValue("id"); id != jobID && id != currentJob {
+		s.respondError(w, http.StatusNotFound, fmt.Sprintf("job %q not found", id))
+		return "", false
+	}
+	return jobID, true
+}
+
+// applicantURL is the API path of an applicant
+func applicantURL(jobID, applicantID string) string {
+	return "/jobs/" + url.PathEscape(jobID) + "/applicants/" + url.PathEscape(applicantID)
+}
+
+// handleListApplicants lists the ranked applicants of a job
+func (s *Server) handleListApplicants(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := s.checkJob(w, r)
+	if !ok {
+		return
+	}
+
+	results := s.agent.GetResults()
+	applicants := make([]models.ApplicantSummary, 0, len(results))
+	for _, result := range results {
+		applicants = append(applicants, models.ApplicantSummary{
+			ID:               result.ID,
+			Name:             result.Name,
+			Rank:             result.Rank,
+			TotalScore:       result.Scores.TotalScore,
+			ExperienceScore:  result.Scores.ExperienceScore,
+			EducationScore:   result.Scores.EducationScore,
+			DutiesScore:      result.Scores.DutiesScore,
+			CoverLetterScore: result.Scores.CoverLetterScore,
+			NeedsReview:      result.NeedsReview,
+			URL:              applicantURL(jobID, result.ID),
+		})
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_id":     jobID,
+		"job_title":  s.agent.GetJobDescription().Title,
+		"applicants": applicants,
+	})
+}
+
+// handleGetApplicant returns an applicant's scores, reasoning, profile and documents
+func (s *Server) handleGetApplicant(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := s.checkJob(w, r)
+	if !ok {
+		return
+	}
+
+	detail, err := s.agent.GetApplicant(r.PathValue("applicantId"))
+	if err != nil {
+		s.respondApplicantError(w, err)
+		return
+	}
+	for i := range detail.Documents {
+		detail.Documents[i].DownloadURL = applicantURL(jobID, detail.ID) + "/documents/" + url.PathEscape(detail.Documents[i].Name)
+	}
+
+	s.respondJSON(w, http.StatusOK, detail)
+}
+
+// handleGetApplicantDocument downloads one of an applicant's original files
+func (s *Server) handleGetApplicantDocument(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.checkJob(w, r); !ok {
+		return
+	}
+
+	path, err := s.agent.ApplicantDocumentPath(r.PathValue("applicantId"), r.PathValue("doc"))
+	if err != nil {
+		s.respondApplicantError(w, err)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		s.respondError(w, http.StatusNotFound, "document is no longer available")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		s.respondError(w, http.StatusNotFound, "document is no longer available")
+		return
+	}
+
+	name := filepath.Base(path)
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// handleRescoreApplicant scores one applicant again and returns the new result
+func (s *Server) handleRescoreApplicant(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := s.checkJob(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.agent.RescoreApplicantWithContext(r.Context(), r.PathValue("applicantId"))
+	if err != nil {
+		s.respondApplicantError(w, err)
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"status":    "rescored",
+		"applicant": result,
+		"url":       applicantURL(jobID, result.ID),
+	})
+}
+
+// respondApplicantError maps applicant lookup errors to a status code
+func (s *Server) respondApplicantError(w http.ResponseWriter, err error) {
+	if errors.Is(err, agent.ErrApplicantNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, agent.ErrResultsChanged) {
+		s.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
+}
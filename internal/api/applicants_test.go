@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// fakeScorer gives every applicant the same score without calling an LLM
+type fakeScorer struct{ total float64 }
+
+func (f fakeScorer) ScoreApplicant(ctx context.Context, doc models.ApplicantDocument, jobDesc models.JobDescription) (models.Scores, error) {
+	return models.Scores{TotalScore: f.total, ExperienceReasoning: "rescored"}, nil
+}
+
+// newApplicantsServer returns a test server whose agent holds results for two
+// applicants with CVs in a temporary uploads folder
+func newApplicantsServer(t *testing.T) (*Server, http.Handler, testKeys) {
+	t.Helper()
+	s, handler, keys := newTestServer(t)
+
+	uploads := t.TempDir()
+	cvs := map[string]string{
+		"Jane Smith": "Jane Smith\nSix years of Go and PostgreSQL",
+		"John Doe":   "John Doe\nTwo years of Python",
+	}
+	var previous []models.ApplicantResult
+	for name, text := range cvs {
+		path := filepath.Join(uploads, name, "CV.txt")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		total := 40.0
+		if name == "Jane Smith" {
+			total = 80
+		}
+		previous = append(previous, models.ApplicantResult{
+			Name:      name,
+			Scores:    models.Scores{TotalScore: total},
+			Documents: []models.Document{{Type: models.DocumentCV, Path: path}},
+		})
+	}
+
+	// Keep the previous results, as a rescore naming nobody does
+	s.agent.FileHandler = ingestion.NewFileHandler(uploads)
+	s.agent.SetScorer(fakeScorer{total: 95})
+	jobJSON := `{"title": "Go Engineer", "description": "Build APIs"}`
+	if err := s.agent.RescoreWithContext(context.Background(), jobJSON, previous, []string{"nobody"}); err != nil {
+		t.Fatalf("RescoreWithContext() returned error: %v", err)
+	}
+	return s, handler, keys
+}
+
+// TestApplicantEndpoints tests listing, reading, downloading and rescoring applicants
+func TestApplicantEndpoints(t *testing.T) {
+	s, handler, keys := newApplicantsServer(t)
+	jobID := s.agent.JobID()
+
+	w := serve(handler, http.MethodGet, "/jobs/current/applicants", keys.viewer, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("List status = %d: %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		JobID      string                    `json:"job_id"`
+		JobTitle   string                    `json:"job_title"`
+		Applicants []models.ApplicantSummary `json:"applicants"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	if list.JobID != jobID || list.JobTitle != "Go Engineer" || len(list.Applicants) != 2 {
+		t.Fatalf("List = %+v", list)
+	}
+	jane, john := list.Applicants[0], list.Applicants[1]
+	if jane.Name != "Jane Smith" || jane.Rank != 1 || john.Name != "John Doe" || john.Rank != 2 {
+		t.Fatalf("Applicants = %+v", list.Applicants)
+	}
+	if jane.URL != "/jobs/"+jobID+"/applicants/"+jane.ID {
+		t.Errorf("URL = %q", jane.URL)
+	}
+
+	// The job can be addressed by its ID as well as "current"
+	w = serve(handler, http.MethodGet, jane.URL, keys.viewer, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Get status = %d: %s", w.Code, w.Body.String())
+	}
+	var detail models.ApplicantDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("Failed to decode applicant: %v", err)
+	}
+	if detail.ID != jane.ID || detail.JobID != jobID || len(detail.Documents) != 1 {
+		t.Fatalf("Applicant = %+v", detail)
+	}
+	doc := detail.Documents[0]
+	if doc.Name != "CV.txt" || doc.DownloadURL != jane.URL+"/documents/CV.txt" {
+		t.Errorf("Document = %+v", doc)
+	}
+
+	w = serve(handler, http.MethodGet, doc.DownloadURL, keys.viewer, "")
+	if w.Code != http.StatusOK || w.Body.String() != "Jane Smith\nSix years of Go and PostgreSQL" {
+		t.Errorf("Download = %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename=CV.txt` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	// Rescoring moves John to the top without changing his ID
+	w = serve(handler, http.MethodPost, "/jobs/current/applicants/"+john.ID+"/rescore", keys.recruiter, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Rescore status = %d: %s", w.Code, w.Body.String())
+	}
+	var rescored struct {
+		Applicant models.ApplicantResult `json:"applicant"`
+		URL       string                 `json:"url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &rescored); err != nil {
+		t.Fatalf("Failed to decode rescore: %v", err)
+	}
+	if rescored.Applicant.ID != john.ID || rescored.Applicant.Rank != 1 || rescored.Applicant.Scores.TotalScore != 95 {
+		t.Errorf("Rescored = %+v", rescored.Applicant)
+	}
+	if rescored.URL != "/jobs/"+jobID+"/applicants/"+john.ID {
+		t.Errorf("Rescore URL = %q", rescored.URL)
+	}
+	if w = serve(handler, http.MethodGet, jane.URL, keys.viewer, ""); w.Code != http.StatusOK {
+		t.Errorf("Jane's URL after rescoring: status = %d", w.Code)
+	}
+}
+
+// TestApplicantEndpoints_Errors tests unknown jobs, applicants and documents and role checks
+func TestApplicantEndpoints_Errors(t *testing.T) {
+	s, handler, keys := newApplicantsServer(t)
+	jane := s.agent.GetResults()[0].ID
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		key    string
+		want   int
+	}{
+		{"unknown job", http.MethodGet, "/jobs/other-job/applicants", keys.viewer, http.StatusNotFound},
+		{"unknown applicant", http.MethodGet, "/jobs/current/applicants/nobody", keys.viewer, http.StatusNotFound},
+		{"unknown document", http.MethodGet, "/jobs/current/applicants/" + jane + "/documents/other.pdf", keys.viewer, http.StatusNotFound},
+		{"rescore unknown applicant", http.MethodPost, "/jobs/current/applicants/nobody/rescore", keys.recruiter, http.StatusNotFound},
+		{"rescore as viewer", http.MethodPost, "/jobs/current/applicants/" + jane + "/rescore", keys.viewer, http.StatusForbidden},
+		{"list without key", http.MethodGet, "/jobs/current/applicants", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, tt.method, tt.target, tt.key, "")
+			if w.Code != tt.want {
+				t.Errorf("Status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestApplicantEndpoints_NoResults tests that applicants are not found before ingestion
+func TestApplicantEndpoints_NoResults(t *testing.T) {
+	_, handler, keys := newTestServer(t)
+
+	w := serve(handler, http.MethodGet, "/jobs/current/applicants", keys.viewer, "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/auth"
+	"github.com/fmuoria/CV-Review-agent/internal/config"
+)
+
+// bootstrapKeyFile holds the admin key created when no keys exist, next to the key store
+const bootstrapKeyFile = "bootstrap_admin_key"
+
+// loadAPIKeys opens the API key store: keys from API_KEYS plus those saved in
+// API_KEYS_FILE (default <config dir>/api_keys.json). When there are none an
+// admin key is created and written to a file only the server's user can read;
+// the log names the file but never shows the key. API_AUTH=off disables
+// authentication.
+func loadAPIKeys() (*auth.KeyStore, error) {
+	if strings.EqualFold(os.Getenv("API_AUTH"), "off") {
+		log.Printf("WARNING: API authentication is disabled (API_AUTH=off); anyone who can reach the server can read candidate data")
+		return nil, nil
+	}
+
+	path := os.Getenv("API_KEYS_FILE")
+	if path == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(configDir, "api_keys.json")
+	}
+
+	keys, err := auth.NewKeyStore(path, os.Getenv("API_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	if keys.Len() == 0 {
+		apiKey, key, err := keys.Create("bootstrap-admin", auth.RoleAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create admin API key: %w", err)
+		}
+		keyPath := filepath.Join(filepath.Dir(path), bootstrapKeyFile)
+		if err := writeBootstrapKey(keyPath, key); err != nil {
+			keys.Revoke(apiKey.ID)
+			return nil, err
+		}
+		log.Printf("No API keys configured; created admin key %s and saved it in %s. Copy it from there, then delete the file.", apiKey.ID, keyPath)
+	}
+	return keys, nil
+}
+
+// writeBootstrapKey saves the bootstrap admin key in a file readable only by
+// the server's user
+func writeBootstrapKey(path, key string) error {
+	// Replace any old file so its permissions are not kept
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to save admin API key: %w", err)
+	}
+	if _, err := f.WriteString(key + "\n"); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save admin API key: %w", err)
+	}
+	return f.Close()
+}
+
+// require allows a request only for clients with at least the given role.
+// Clients send an API key or an SSO ID token as "Authorization: Bearer <token>"
+// (API keys also as "X-API-Key: <key>"); browsers signed in through /oauth/login
+// send the session cookie. Allowed requests are then validated against the
+// OpenAPI document.
+func (s *Server) require(role auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	next = s.validate(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.keys == nil {
+			next(w, r)
+			return
+		}
+
+		principal, err := s.authenticate(r)
+		if errors.Is(err, auth.ErrNoRole) {
+			s.respondError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cv-review-agent"`)
+			s.respondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !principal.Role.Allows(role) {
+			s.respondError(w, http.StatusForbidden, fmt.Sprintf("the %s role cannot access this endpoint", principal.Role))
+			return
+		}
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// authenticate identifies the client of a request by API key or SSO ID token
+func (s *Server) authenticate(r *http.Request) (auth.Principal, error) {
+	token := credentialFromRequest(r)
+	if token == "" && s.oidc != nil {
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return auth.Principal{}, fmt.Errorf("missing API key or token")
+	}
+
+	if principal, ok := s.keys.Authenticate(token); ok {
+		return principal, nil
+	}
+	if s.oidc != nil && strings.Count(token, ".") == 2 {
+		principal, err := s.oidc.Authenticate(r.Context(), token)
+		if err == nil || errors.Is(err, auth.ErrNoRole) {
+			return principal, err
+		}
+		log.Printf("Rejected SSO token: %v", err)
+		return auth.Principal{}, fmt.Errorf("invalid or expired token")
+	}
+	return auth.Principal{}, fmt.Errorf("missing or invalid API key")
+}
+
+// credentialFromRequest returns the API key or token sent with a request
+func credentialFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return strings.TrimSpace(key)
+	}
+	header := r.Header.Get("Authorization")
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+	return ""
+}
+
+// createAPIKeyRequest is the body of POST /admin/api-keys
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// handleListAPIKeys lists the API keys (without the keys themselves)
+func (s *Server) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if s.keys == nil {
+		s.respondError(w, http.StatusNotFound, "API authentication is disabled")
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": s.keys.List(),
+	})
+}
+
+// handleCreateAPIKey creates an API key; the key is only returned in this response
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.keys == nil {
+		s.respondError(w, http.StatusNotFound, "API authentication is disabled")
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	apiKey, key, err := s.keys.Create(req.Name, role)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		log.Printf("API key %s (%s, %s) created by %s", apiKey.ID, apiKey.Name, apiKey.Role, principal.Name)
+	}
+
+	s.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":         apiKey.ID,
+		"name":       apiKey.Name,
+		"role":       apiKey.Role,
+		"created_at": apiKey.CreatedAt,
+		"key":        key,
+	})
+}
+
+// handleRevokeAPIKey deletes an API key
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	if s.keys == nil {
+		s.respondError(w, http.StatusNotFound, "API authentication is disabled")
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := s.keys.Revoke(id); err != nil {
+		if errors.Is(err, auth.ErrKeyNotFound) {
+			s.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if principal, ok := auth.PrincipalFrom(r.Context()); ok {
+		log.Printf("API key %s revoked by %s", id, principal.Name)
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]string{
+		"status": "revoked",
+	})
+}
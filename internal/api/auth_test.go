@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/auth"
+)
+
+// TestLoadAPIKeys_BootstrapKey tests that the first admin key is saved to a
+// private file and never written to the log
+func TestLoadAPIKeys_BootstrapKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("API_AUTH", "")
+	t.Setenv("API_KEYS", "")
+	t.Setenv("API_KEYS_FILE", filepath.Join(dir, "api_keys.json"))
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	keys, err := loadAPIKeys()
+	if err != nil {
+		t.Fatalf("loadAPIKeys() returned error: %v", err)
+	}
+
+	keyPath := filepath.Join(dir, bootstrapKeyFile)
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatalf("Expected the admin key in %s: %v", keyPath, err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Key file permissions = %o, want 600", perm)
+	}
+	data, _ := os.ReadFile(keyPath)
+	key := strings.TrimSpace(string(data))
+
+	principal, ok := keys.Authenticate(key)
+	if !ok || principal.Role != auth.RoleAdmin {
+		t.Fatalf("Saved key does not authenticate as admin: %+v, %v", principal, ok)
+	}
+	if strings.Contains(logs.String(), key) {
+		t.Errorf("The admin key was logged:\n%s", logs.String())
+	}
+	if id := keys.List()[0].ID; !strings.Contains(logs.String(), id) || !strings.Contains(logs.String(), keyPath) {
+		t.Errorf("Expected the log to name the key ID and file:\n%s", logs.String())
+	}
+
+	// Once a key exists no new one is created
+	os.Remove(keyPath)
+	if _, err := loadAPIKeys(); err != nil {
+		t.Fatalf("Second loadAPIKeys() returned error: %v", err)
+	}
+	if _, err := os.Stat(keyPath); !os.IsNotExist(err) {
+		t.Error("Expected no new admin key when keys exist")
+	}
+}
+
+// TestRequire_Roles tests that each role reaches only its endpoints, with 401
+// for missing or unknown keys and 403 for keys without the role
+func TestRequire_Roles(t *testing.T) {
+	_, handler, keys := newTestServer(t)
+	jobDesc := `{"title": "Go Engineer", "required_experience": ["Go"]}`
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		body   string
+		want   int
+	}{
+		{"no key", "GET", "/job-descriptions", "", "", http.StatusUnauthorized},
+		{"unknown key", "GET", "/job-descriptions", "cvr_unknown", "", http.StatusUnauthorized},
+		{"viewer reads", "GET", "/job-descriptions", keys.viewer, "", http.StatusOK},
+		{"viewer cannot edit", "POST", "/job-descriptions", keys.viewer, jobDesc, http.StatusForbidden},
+		{"viewer cannot watch", "DELETE", "/watch", keys.viewer, "", http.StatusForbidden},
+		{"recruiter edits", "POST", "/job-descriptions", keys.recruiter, jobDesc, http.StatusCreated},
+		{"recruiter cannot manage keys", "GET", "/admin/api-keys", keys.recruiter, "", http.StatusForbidden},
+		{"admin edits", "POST", "/job-descriptions", keys.admin, jobDesc, http.StatusCreated},
+		{"admin manages keys", "GET", "/admin/api-keys", keys.admin, "", http.StatusOK},
+		{"public health", "GET", "/health", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, tt.method, tt.path, tt.key, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.want, w.Body.String())
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate header with 401")
+			}
+		})
+	}
+
+	// Keys are also accepted in X-API-Key
+	r, _ := http.NewRequest("GET", "/job-descriptions", nil)
+	r.Header.Set("X-API-Key", keys.viewer)
+	if got := credentialFromRequest(r); got != keys.viewer {
+		t.Errorf("credentialFromRequest() = %q, want the X-API-Key value", got)
+	}
+}
+
+// TestAPIKeys_Revoke tests creating, using and revoking a key through the admin endpoints
+func TestAPIKeys_Revoke(t *testing.T) {
+	s, handler, keys := newTestServer(t)
+
+	w := serve(handler, "POST", "/admin/api-keys", keys.admin, `{"name": "hr-dashboard", "role": "viewer"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /admin/api-keys = %d: %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	if w := serve(handler, "GET", "/job-descriptions", created.Key, ""); w.Code != http.StatusOK {
+		t.Fatalf("New key was rejected: %d", w.Code)
+	}
+	if w := serve(handler, "GET", "/admin/api-keys", keys.admin, ""); strings.Contains(w.Body.String(), created.Key) {
+		t.Error("Listing keys must not return the keys themselves")
+	}
+
+	if w := serve(handler, "DELETE", "/admin/api-keys/"+created.ID, keys.viewer, ""); w.Code != http.StatusForbidden {
+		t.Errorf("Viewer revoking a key = %d, want 403", w.Code)
+	}
+	if w := serve(handler, "DELETE", "/admin/api-keys/"+created.ID, keys.admin, ""); w.Code != http.StatusOK {
+		t.Fatalf("DELETE /admin/api-keys/%s = %d: %s", created.ID, w.Code, w.Body.String())
+	}
+	if w := serve(handler, "GET", "/job-descriptions", created.Key, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Revoked key = %d, want 401", w.Code)
+	}
+	if w := serve(handler, "DELETE", "/admin/api-keys/"+created.ID, keys.admin, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Revoking a revoked key = %d, want 404", w.Code)
+	}
+
+	// Revocation is kept across restarts
+	reloaded, err := loadAPIKeys()
+	if err != nil {
+		t.Fatalf("loadAPIKeys() returned error: %v", err)
+	}
+	if _, ok := reloaded.Authenticate(created.Key); ok {
+		t.Error("Revoked key is valid after reloading the key store")
+	}
+	if _, ok := s.keys.Authenticate(keys.admin); !ok {
+		t.Error("Revoking one key must not affect the others")
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/jobs"
+	"github.com/fmuoria/CV-Review-agent/internal/llm"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// jobDescriptionFromForm returns the job description JSON of an ingest or
+// watch request: either the job_description field, which must be a valid job
+// description, or the stored job description named by job_description_id (and
+// optionally job_description_version)
+func (s *Server) jobDescriptionFromForm(r *http.Request) (string, error) {
+	id := r.FormValue("job_description_id")
+	if id == "" {
+		jobDescJSON := r.FormValue("job_description")
+		if jobDescJSON == "" {
+			return "", fmt.Errorf("job_description or job_description_id is required")
+		}
+		var jobDesc models.JobDescription
+		if err := json.Unmarshal([]byte(jobDescJSON), &jobDesc); err != nil {
+			return "", fmt.Errorf("invalid job_description: %w", err)
+		}
+		if err := jobDesc.ValidateField("job_description"); err != nil {
+			return "", err
+		}
+		return jobDescJSON, nil
+	}
+
+	version := 0
+	if raw := r.FormValue("job_description_version"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return "", fmt.Errorf("job_description_version must be a positive number")
+		}
+		version = v
+	}
+	jobDesc, err := s.jobs.Get(id, version)
+	if err != nil {
+		return "", fmt.Errorf("job_description_id: %w", err)
+	}
+	data, err := json.Marshal(jobDesc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job description: %w", err)
+	}
+	return string(data), nil
+}
+
+// handleListJobDescriptions lists the stored job descriptions
+func (s *Server) handleListJobDescriptions(w http.ResponseWriter, r *http.Request) {
+	summaries, err := s.jobs.List()
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"job_descriptions": summaries,
+	})
+}
+
+// handleGetJobDescription returns the latest version of a job description,
+// or the one given by ?version=
+func (s *Server) handleGetJobDescription(w http.ResponseWriter, r *http.Request) {
+	version := 0
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			s.respondError(w, http.StatusBadRequest, "version must be a positive number")
+			return
+		}
+		version = v
+	}
+
+	jobDesc, err := s.jobs.Get(r.PathValue("id"), version)
+	if err != nil {
+		s.respondJobDescriptionError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, jobDesc)
+}
+
+// handleListJobDescriptionVersions returns every version of a job description
+func (s *Server) handleListJobDescriptionVersions(w http.ResponseWriter, r *http.Request) {
+	entry, err := s.jobs.Entry(r.PathValue("id"))
+	if err != nil {
+		s.respondJobDescriptionError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, entry)
+}
+
+// handleCreateJobDescription stores a new job description
+func (s *Server) handleCreateJobDescription(w http.ResponseWriter, r *http.Request) {
+	jobDesc, ok := s.decodeJobDescription(w, r)
+	if !ok {
+		return
+	}
+	created, err := s.jobs.Create(jobDesc)
+	if err != nil {
+		s.respondJobDescriptionError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/job-descriptions/"+created.ID)
+	s.respondJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdateJobDescription saves a new version of a job description
+func (s *Server) handleUpdateJobDescription(w http.ResponseWriter, r *http.Request) {
+	jobDesc, ok := s.decodeJobDescription(w, r)
+	if !ok {
+		return
+	}
+	updated, err := s.jobs.Update(r.PathValue("id"), jobDesc)
+	if err != nil {
+		s.respondJobDescriptionError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteJobDescription deletes a job description with all its versions
+func (s *Server) handleDeleteJobDescription(w http.ResponseWriter, r *http.Request) {
+	if err := s.jobs.Delete(r.PathValue("id")); err != nil {
+		s.respondJobDescriptionError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{
+		"status": "deleted",
+	})
+}
+
+// handleParseJobDescription turns a job posting, sent as the "text" field or
+// as a "file" upload (text, PDF, DOCX, HTML, ...), into a job description.
+// Nothing is stored: the result is meant to be reviewed and then saved with
+// POST /job-descriptions. Fields that still need attention are listed in "issues".
+func (s *Server) handleParseJobDescription(w http.ResponseWriter, r *http.Request) {
+	text, err := jobPostingFromForm(r)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	llmClient, err := llm.NewVertexAIClient()
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, fmt.Sprintf("failed to initialize LLM client: %v", err))
+		return
+	}
+	defer llmClient.Close()
+
+	jobDesc, err := jobs.NewParser(llmClient).ParseText(r.Context(), text)
+	if err != nil {
+		s.respondError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"job_description": jobDesc,
+	}
+	var invalid *models.ValidationError
+	if errors.As(jobDesc.Validate(), &invalid) {
+		response["issues"] = invalid.Fields
+	}
+	s.respondJSON(w, http.StatusOK, response)
+}
+
+// jobPostingFromForm returns the posting text of a parse request
+func jobPostingFromForm(r *http.Request) (string, error) {
+	if text := strings.TrimSpace(r.FormValue("text")); text != "" {
+		return text, nil
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", fmt.Errorf("send the job posting as the text field or a file upload")
+	}
+	defer file.Close()
+
+	// Extractors work on files, so the upload is written to a temporary file
+	// that keeps its extension
+	ext := strings.ToLower(filepath.Ext(filepath.Base(header.Filename)))
+	if ext != ".txt" && !ingestion.IsSupportedFile("posting"+ext) {
+		return "", fmt.Errorf("unsupported file type: %s", ext)
+	}
+	tmp, err := os.CreateTemp("", "job-posting-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to store upload: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to store upload: %w", err)
+	}
+
+	return jobs.PostingText(tmp.Name())
+}
+
+// decodeJobDescription reads a job description request body
+func (s *Server) decodeJobDescription(w http.ResponseWriter, r *http.Request) (models.JobDescription, bool) {
+	var jobDesc models.JobDescription
+	if err := json.NewDecoder(r.Body).Decode(&jobDesc); err != nil {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return jobDesc, false
+	}
+	return jobDesc, true
+}
+
+// respondJobDescriptionError maps library errors to a status code; validation
+// errors list the invalid fields
+func (s *Server) respondJobDescriptionError(w http.ResponseWriter, err error) {
+	var invalid *models.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		s.respondInvalidRequest(w, err)
+	case errors.Is(err, jobs.ErrNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	default:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
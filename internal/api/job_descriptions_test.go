@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestJobDescriptionBodyLimits tests that the body limits of the OpenAPI
+// document apply to the job description endpoints
+func TestJobDescriptionBodyLimits(t *testing.T) {
+	_, handler, keys := newTestServer(t)
+
+	bigJSON := `{"title": "Go Engineer", "description": "` + strings.Repeat("x", 1<<20) + `"}`
+	bigPosting := url.Values{"text": {strings.Repeat("x", 10<<20)}}.Encode()
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"create over 1 MB", http.MethodPost, "/job-descriptions", "application/json", bigJSON, http.StatusRequestEntityTooLarge},
+		{"update over 1 MB", http.MethodPut, "/job-descriptions/go-engineer", "application/json", bigJSON, http.StatusRequestEntityTooLarge},
+		{"parse over 10 MB", http.MethodPost, "/job-descriptions/parse", "application/x-www-form-urlencoded", bigPosting, http.StatusRequestEntityTooLarge},
+		{"parse without a posting", http.MethodPost, "/job-descriptions/parse", "application/x-www-form-urlencoded", "text=", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			r.Header.Set("Authorization", "Bearer "+keys.recruiter)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("Status = %d, want %d: %.200s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/auth"
+)
+
+const (
+	// loginCallbackPath is where the SSO provider sends users back to
+	loginCallbackPath = "/oauth/login/callback"
+	// sessionCookie holds the ID token of users signed in through the browser
+	sessionCookie = "cvr_session"
+)
+
+// loadOIDCProvider configures SSO login from OIDC_* environment variables, or
+// returns nil when OIDC_ISSUER is not set
+func loadOIDCProvider() (*auth.OIDCProvider, error) {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	provider, err := auth.NewOIDCProvider(auth.OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		RoleGroups: map[auth.Role][]string{
+			auth.RoleAdmin:     splitList(os.Getenv("OIDC_ADMIN_GROUPS")),
+			auth.RoleRecruiter: splitList(os.Getenv("OIDC_RECRUITER_GROUPS")),
+			auth.RoleViewer:    splitList(os.Getenv("OIDC_VIEWER_GROUPS")),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("SSO login enabled through %s", issuer)
+	return provider, nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loginRedirectURL is the callback URL registered with the SSO provider:
+// OIDC_REDIRECT_URL, or this server's callback as reached by the browser
+func loginRedirectURL(r *http.Request) string {
+	if redirectURL := os.Getenv("OIDC_REDIRECT_URL"); redirectURL != "" {
+		return redirectURL
+	}
+	return serverURL(r) + loginCallbackPath
+}
+
+// handleLogin sends the browser to the SSO provider's login page, or returns
+// its URL as JSON to clients that accept it
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		s.respondError(w, http.StatusNotFound, "SSO login is not configured")
+		return
+	}
+
+	loginURL, err := s.oidc.Start(r.Context(), loginRedirectURL(r))
+	if err != nil {
+		log.Printf("Failed to start SSO login: %v", err)
+		s.respondError(w, http.StatusBadGateway, "SSO provider is unavailable")
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		s.respondJSON(w, http.StatusOK, map[string]string{"auth_url": loginURL})
+		return
+	}
+	http.Redirect(w, r, loginURL, http.StatusFound)
+}
+
+// handleLoginCallback completes the SSO login. The ID token is set as a session
+// cookie for the browser and returned for use as a bearer token.
+func (s *Server) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		s.respondError(w, http.StatusNotFound, "SSO login is not configured")
+		return
+	}
+	if reason := r.URL.Query().Get("error"); reason != "" {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("SSO login failed: %s", reason))
+		return
+	}
+
+	principal, token, expires, err := s.oidc.Complete(r.Context(), r.URL.Query().Get("state"), r.URL.Query().Get("code"))
+	if errors.Is(err, auth.ErrNoRole) {
+		log.Printf("SSO login refused for %s: no role for their groups", principal.Name)
+		s.respondError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("%s signed in with SSO as %s", principal.Name, principal.Role)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"name":       principal.Name,
+		"role":       principal.Role,
+		"token":      token,
+		"expires_at": expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// handleLogout clears the session cookie. ID tokens stay valid until they
+// expire, so clients holding a token should discard it too.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	s.respondJSON(w, http.StatusOK, map[string]string{
+		"status": "signed out",
+	})
+}
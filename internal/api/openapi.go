@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+	"github.com/fmuoria/CV-Review-agent/internal/openapi"
+)
+
+// handleOpenAPI serves the OpenAPI document, for generating typed clients
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openapi.Document())
+}
+
+// validate checks a request against its operation in the OpenAPI document
+// before passing it on, limiting the body to the operation's x-max-body-size
+func (s *Server) validate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		method, path, _ := strings.Cut(r.Pattern, " ")
+		op := s.spec.Operation(method, path)
+		if op == nil {
+			next(w, r)
+			return
+		}
+
+		if op.MaxBodySize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, op.MaxBodySize)
+		}
+		if err := s.spec.ValidateRequest(r, path, op); err != nil {
+			s.respondInvalidRequest(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// respondInvalidRequest sends 400 with the invalid fields of a validation
+// error, or 413 when the body was too large
+func (s *Server) respondInvalidRequest(w http.ResponseWriter, err error) {
+	var invalid *models.ValidationError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &invalid):
+		s.respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":  invalid.Error(),
+			"fields": invalid.Fields,
+		})
+	case errors.As(err, &tooLarge):
+		s.respondError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+	default:
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
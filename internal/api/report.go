@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// maxReportLimit caps the page size of GET /report
+const maxReportLimit = 500
+
+// reportQueryFromRequest reads the GET /report query parameters:
+//
+//	min_score, min_experience, min_education, min_duties, min_cover_letter
+//	q (name search), status (needs_review or ok)
+//	sort (rank, name or a score field), order (asc or desc)
+//	limit, cursor (next_cursor of the previous page)
+//
+// Scores sort highest first and rank and name ascending unless order is given.
+func reportQueryFromRequest(r *http.Request) (models.ReportQuery, error) {
+	params := r.URL.Query()
+	query := models.ReportQuery{
+		Name:   strings.TrimSpace(params.Get("q")),
+		Status: params.Get("status"),
+		Sort:   params.Get("sort"),
+		Cursor: params.Get("cursor"),
+	}
+
+	for param, target := range map[string]*float64{
+		"min_score":        &query.MinTotal,
+		"min_experience":   &query.MinExperience,
+		"min_education":    &query.MinEducation,
+		"min_duties":       &query.MinDuties,
+		"min_cover_letter": &query.MinCoverLetter,
+	} {
+		raw := params.Get(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return query, fmt.Errorf("%s must be a number", param)
+		}
+		*target = value
+	}
+
+	switch order := params.Get("order"); order {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	case "":
+		query.Descending = strings.HasSuffix(query.Sort, "_score")
+	default:
+		return query, fmt.Errorf("order must be asc or desc")
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxReportLimit {
+			return query, fmt.Errorf("limit must be between 1 and %d", maxReportLimit)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
@@ -1,51 +1,121 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"path/filepath"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fmuoria/CV-Review-agent/internal/agent"
+	"github.com/fmuoria/CV-Review-agent/internal/auth"
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/jobs"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+	"github.com/fmuoria/CV-Review-agent/internal/openapi"
 )
 
 // Server handles HTTP requests
 type Server struct {
-	agent *agent.CVReviewAgent
+	agent     *agent.CVReviewAgent
+	gmailAuth *ingestion.GmailAuthenticator
+	keys      *auth.KeyStore     // nil when authentication is disabled
+	oidc      *auth.OIDCProvider // nil when SSO login is not configured
+	jobs      *jobs.Library
+	spec      *openapi.Spec
+
+	watchMu     sync.Mutex
+	watchCancel context.CancelFunc
+	watch       watchStatus
 }
 
 // NewServer creates a new API server
-func NewServer(agent *agent.CVReviewAgent) *Server {
-	return &Server{
-		agent: agent,
+func NewServer(agent *agent.CVReviewAgent) (*Server, error) {
+	keys, err := loadAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
 	}
+
+	var oidc *auth.OIDCProvider
+	if keys != nil {
+		if oidc, err = loadOIDCProvider(); err != nil {
+			return nil, fmt.Errorf("failed to configure OIDC login: %w", err)
+		}
+	}
+
+	jobsPath, err := jobs.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate job descriptions: %w", err)
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API document: %w", err)
+	}
+
+	return &Server{
+		agent:     agent,
+		gmailAuth: ingestion.NewGmailAuthenticator("credentials.json", ingestion.NewGmailTokenStore("")),
+		keys:      keys,
+		oidc:      oidc,
+		jobs:      jobs.NewLibrary(jobsPath),
+		spec:      spec,
+	}, nil
 }
 
 // Router returns the HTTP router
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("POST /ingest", s.handleIngest)
-	mux.HandleFunc("GET /report", s.handleReport)
+	mux.HandleFunc("POST /ingest", s.require(auth.RoleRecruiter, s.handleIngest))
+	mux.HandleFunc("GET /report", s.require(auth.RoleViewer, s.handleReport))
+	mux.HandleFunc("GET /job-descriptions", s.require(auth.RoleViewer, s.handleListJobDescriptions))
+	mux.HandleFunc("POST /job-descriptions", s.require(auth.RoleRecruiter, s.handleCreateJobDescription))
+	mux.HandleFunc("POST /job-descriptions/parse", s.require(auth.RoleRecruiter, s.handleParseJobDescription))
+	mux.HandleFunc("GET /job-descriptions/{id}", s.require(auth.RoleViewer, s.handleGetJobDescription))
+	mux.HandleFunc("GET /job-descriptions/{id}/versions", s.require(auth.RoleViewer, s.handleListJobDescriptionVersions))
+	mux.HandleFunc("PUT /job-descriptions/{id}", s.require(auth.RoleRecruiter, s.handleUpdateJobDescription))
+	mux.HandleFunc("DELETE /job-descriptions/{id}", s.require(auth.RoleRecruiter, s.handleDeleteJobDescription))
+	mux.HandleFunc("GET /jobs/{id}/applicants", s.require(auth.RoleViewer, s.handleListApplicants))
+	mux.HandleFunc("GET /jobs/{id}/applicants/{applicantId}", s.require(auth.RoleViewer, s.handleGetApplicant))
+	mux.HandleFunc("GET /jobs/{id}/applicants/{applicantId}/documents/{doc}", s.require(auth.RoleViewer, s.handleGetApplicantDocument))
+	mux.HandleFunc("POST /jobs/{id}/applicants/{applicantId}/rescore", s.require(auth.RoleRecruiter, s.handleRescoreApplicant))
+	mux.HandleFunc("POST /gmail/follow-up", s.require(auth.RoleRecruiter, s.handleGmailFollowUp))
+	mux.HandleFunc("GET /oauth/gmail/start", s.require(auth.RoleRecruiter, s.handleGmailAuthStart))
+	mux.HandleFunc("POST /watch", s.require(auth.RoleRecruiter, s.handleWatchStart))
+	mux.HandleFunc("GET /watch", s.require(auth.RoleViewer, s.handleWatchStatus))
+	mux.HandleFunc("DELETE /watch", s.require(auth.RoleRecruiter, s.handleWatchStop))
+	mux.HandleFunc("GET /admin/api-keys", s.require(auth.RoleAdmin, s.handleListAPIKeys))
+	mux.HandleFunc("POST /admin/api-keys", s.require(auth.RoleAdmin, s.handleCreateAPIKey))
+	mux.HandleFunc("DELETE /admin/api-keys/{id}", s.require(auth.RoleAdmin, s.handleRevokeAPIKey))
+
+	// Public: login pages and the OAuth callbacks, which browsers reach without a key
+	// (the state parameter protects the callbacks)
+	mux.HandleFunc("GET "+ingestion.GmailCallbackPath, s.handleGmailAuthCallback)
+	mux.HandleFunc("GET /oauth/login", s.handleLogin)
+	mux.HandleFunc("GET "+loginCallbackPath, s.handleLoginCallback)
+	mux.HandleFunc("POST /oauth/logout", s.handleLogout)
 	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
 	mux.HandleFunc("GET /", s.handleRoot)
 
 	return s.loggingMiddleware(mux)
 }
 
-// handleRoot provides API information
+// handleRoot provides API information; the endpoints come from the OpenAPI document
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"service": "CV Review Agent",
-		"version": "1.0.0",
-		"endpoints": map[string]string{
-			"POST /ingest": "Upload documents or fetch from Gmail",
-			"GET /report":  "Get ranked applicant results",
-			"GET /health":  "Health check",
-		},
+		"service":   "CV Review Agent",
+		"version":   s.spec.Info.Version,
+		"openapi":   "/openapi.json",
+		"endpoints": s.spec.Endpoints(),
 	})
 }
 
@@ -60,85 +130,283 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 // handleIngest processes document ingestion
 func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request) {
-	// Parse multipart form
-	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB max
-		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Failed to parse form: %v", err))
-		return
-	}
-
 	method := r.FormValue("method")
-	jobDescJSON := r.FormValue("job_description")
-
-	if jobDescJSON == "" {
-		s.respondError(w, http.StatusBadRequest, "job_description is required")
+	jobDescJSON, err := s.jobDescriptionFromForm(r)
+	if err != nil {
+		s.respondInvalidRequest(w, err)
 		return
 	}
 
+	var uploads []models.UploadResult
 	switch method {
 	case "upload":
-		if err := s.handleUploadMethod(r, jobDescJSON); err != nil {
-			s.respondError(w, http.StatusInternalServerError, err.Error())
+		if r.MultipartForm == nil || len(r.MultipartForm.File["files"]) == 0 {
+			s.respondError(w, http.StatusBadRequest, "no files uploaded")
+			return
+		}
+		uploads = s.saveUploads(r.MultipartForm.File["files"])
+		if countUploads(uploads, models.UploadAccepted) == 0 {
+			s.respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error": "none of the uploaded files were accepted",
+				"files": uploads,
+			})
+			return
+		}
+		if err := s.agent.IngestFromUpload(jobDescJSON); err != nil {
+			s.respondJSON(w, http.StatusInternalServerError, map[string]interface{}{
+				"error": err.Error(),
+				"files": uploads,
+			})
 			return
 		}
 	case "gmail":
-		gmailSubject := r.FormValue("gmail_subject")
-		if gmailSubject == "" {
-			s.respondError(w, http.StatusBadRequest, "gmail_subject is required for gmail method")
+		filter, err := gmailFilterFromForm(r)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var followUp *models.GmailFollowUp
+		if raw := r.FormValue("gmail_follow_up"); raw != "" {
+			followUp = &models.GmailFollowUp{}
+			if err := json.Unmarshal([]byte(raw), followUp); err != nil {
+				s.respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid gmail_follow_up: %v", err))
+				return
+			}
+			if err := ingestion.ValidateGmailFollowUp(*followUp); err != nil {
+				s.respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if err := s.agent.IngestFromGmailWithContext(r.Context(), filter, jobDescJSON); err != nil {
+			s.respondGmailError(w, err)
+			return
+		}
+		if followUp != nil {
+			if _, err := s.agent.FollowUpGmailWithContext(r.Context(), *followUp); err != nil {
+				s.respondGmailError(w, fmt.Errorf("documents were scored but the Gmail follow-up failed: %w", err))
+				return
+			}
+		}
+	case "imap":
+		imapConfig, err := imapConfigFromForm(r)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		if err := s.agent.IngestFromGmail(gmailSubject, jobDescJSON); err != nil {
+		if err := s.agent.IngestFromIMAPWithContext(r.Context(), imapConfig, jobDescJSON); err != nil {
 			s.respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 	default:
-		s.respondError(w, http.StatusBadRequest, "method must be 'upload' or 'gmail'")
+		s.respondError(w, http.StatusBadRequest, "method must be 'upload', 'gmail' or 'imap'")
 		return
 	}
 
-	s.respondJSON(w, http.StatusOK, map[string]string{
+	response := map[string]interface{}{
 		"status":  "success",
 		"message": "Documents ingested and evaluated successfully",
-	})
+	}
+	if uploads != nil {
+		response["files"] = uploads
+		response["accepted"] = countUploads(uploads, models.UploadAccepted)
+		response["rejected"] = countUploads(uploads, models.UploadRejected)
+	}
+	s.respondJSON(w, http.StatusOK, response)
 }
 
-// handleUploadMethod processes file uploads
-func (s *Server) handleUploadMethod(r *http.Request, jobDescJSON string) error {
-	files := r.MultipartForm.File["files"]
-	if len(files) == 0 {
-		return fmt.Errorf("no files uploaded")
+// gmailFilterFromForm reads the Gmail search from a gmail_filter JSON object or
+// from the individual gmail_* fields
+func gmailFilterFromForm(r *http.Request) (models.GmailFilter, error) {
+	var filter models.GmailFilter
+	if raw := r.FormValue("gmail_filter"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+			return filter, fmt.Errorf("invalid gmail_filter: %w", err)
+		}
+	} else {
+		filter = models.GmailFilter{
+			Account: r.FormValue("gmail_account"),
+			Subject: r.FormValue("gmail_subject"),
+			From:    r.FormValue("gmail_from"),
+			To:      r.FormValue("gmail_to"),
+			Label:   r.FormValue("gmail_label"),
+			After:   r.FormValue("gmail_after"),
+			Before:  r.FormValue("gmail_before"),
+			Query:   r.FormValue("gmail_query"),
+
+			IncludeBody: r.FormValue("gmail_include_body") == "true",
+		}
+		// gmail_exclude may be repeated or comma-separated
+		for _, value := range r.Form["gmail_exclude"] {
+			for _, term := range strings.Split(value, ",") {
+				if term = strings.TrimSpace(term); term != "" {
+					filter.Exclude = append(filter.Exclude, term)
+				}
+			}
+		}
 	}
 
-	// Create file handler
-	fileHandler := s.agent.FileHandler
+	if _, err := ingestion.GmailQuery(filter); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
 
-	// Save uploaded files
-	for _, fileHeader := range files {
-		file, err := fileHeader.Open()
-		if err != nil {
-			return fmt.Errorf("failed to open uploaded file: %w", err)
+// imapConfigFromForm reads the IMAP mailbox and search criteria from the form;
+// connection settings that are not sent fall back to the IMAP_* environment.
+// The server's credentials are only used with the server's host, port and
+// security, so a request naming its own server must also send its own login.
+func imapConfigFromForm(r *http.Request) (ingestion.IMAPConfig, error) {
+	cfg := ingestion.IMAPConfigFromEnv()
+	if r.FormValue("imap_host") != "" || r.FormValue("imap_port") != "" || r.FormValue("imap_security") != "" {
+		if r.FormValue("imap_username") == "" || r.FormValue("imap_password") == "" {
+			return cfg, fmt.Errorf("imap_username and imap_password are required when imap_host, imap_port or imap_security is set")
 		}
-		defer file.Close()
+		cfg = ingestion.IMAPConfig{Folder: cfg.Folder}
+	}
+	if r.FormValue("imap_username") != "" || r.FormValue("imap_password") != "" {
+		// Never pair a caller's username with the server's password or vice versa
+		cfg.Username, cfg.Password = "", ""
+	}
 
-		// Validate file extension
-		ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-		if ext != ".pdf" && ext != ".txt" && ext != ".doc" && ext != ".docx" {
-			log.Printf("Skipping unsupported file type: %s", fileHeader.Filename)
-			continue
+	for field, target := range map[string]*string{
+		"imap_host":     &cfg.Host,
+		"imap_security": &cfg.Security,
+		"imap_username": &cfg.Username,
+		"imap_password": &cfg.Password,
+		"imap_auth":     &cfg.Auth,
+		"imap_folder":   &cfg.Folder,
+		"imap_from":     &cfg.From,
+	} {
+		if v := r.FormValue(field); v != "" {
+			*target = v
 		}
+	}
 
-		if _, err := fileHandler.SaveUploadedFile(fileHeader.Filename, file); err != nil {
-			return fmt.Errorf("failed to save file %s: %w", fileHeader.Filename, err)
+	if port := r.FormValue("imap_port"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return cfg, fmt.Errorf("imap_port must be a number")
 		}
-		log.Printf("Saved file: %s", fileHeader.Filename)
+		cfg.Port = n
 	}
+	if since := r.FormValue("imap_since"); since != "" {
+		t, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			return cfg, fmt.Errorf("imap_since must be a date (YYYY-MM-DD)")
+		}
+		cfg.Since = t
+	}
+	cfg.Subject = r.FormValue("imap_subject")
+	cfg.UnseenOnly = r.FormValue("imap_unseen") == "true"
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// handleGmailFollowUp labels and acknowledges the Gmail messages of the current results
+func (s *Server) handleGmailFollowUp(w http.ResponseWriter, r *http.Request) {
+	var followUp models.GmailFollowUp
+	if err := json.NewDecoder(r.Body).Decode(&followUp); err != nil {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if err := ingestion.ValidateGmailFollowUp(followUp); err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := s.agent.FollowUpGmailWithContext(r.Context(), followUp)
+	if err != nil {
+		s.respondGmailError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, summary)
+}
+
+// handleGmailAuthStart redirects the browser to Google to authorize a Gmail account, or
+// returns the URL as JSON to clients that accept it.
+// ?access=follow-up also requests label and reply permissions; ?account= preselects the account.
+func (s *Server) handleGmailAuthStart(w http.ResponseWriter, r *http.Request) {
+	followUp := r.URL.Query().Get("access") == "follow-up"
+	authURL, err := s.gmailAuth.Start(gmailRedirectURL(r), ingestion.GmailScopes(followUp), r.URL.Query().Get("account"))
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// API clients get the URL to open in a browser, since browsers cannot send the API key
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		s.respondJSON(w, http.StatusOK, map[string]string{"auth_url": authURL})
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// handleGmailAuthCallback stores the token of the account the user authorized
+func (s *Server) handleGmailAuthCallback(w http.ResponseWriter, r *http.Request) {
+	if reason := r.URL.Query().Get("error"); reason != "" {
+		s.respondError(w, http.StatusBadRequest, fmt.Sprintf("Gmail authorization was declined: %s", reason))
+		return
+	}
+
+	account, err := s.gmailAuth.Complete(r.Context(), r.URL.Query().Get("state"), r.URL.Query().Get("code"))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{
+		"status":  "authorized",
+		"account": account,
+	})
+}
+
+// gmailRedirectURL is the OAuth callback URL registered with Google: GMAIL_OAUTH_REDIRECT_URL,
+// or this server's callback as reached by the browser
+func gmailRedirectURL(r *http.Request) string {
+	if redirectURL := os.Getenv("GMAIL_OAUTH_REDIRECT_URL"); redirectURL != "" {
+		return redirectURL
+	}
+	return serverURL(r) + ingestion.GmailCallbackPath
+}
+
+// serverURL is this server's address as reached by the browser
+func serverURL(r *http.Request) string {
+	scheme := "http"
+	if isHTTPS(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// isHTTPS reports whether the browser reached the server over HTTPS
+func isHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
 
-	// Process the uploaded documents
-	return s.agent.IngestFromUpload(jobDescJSON)
+// respondGmailError reports a Gmail failure, pointing to the authorization flow when access is missing
+func (s *Server) respondGmailError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ingestion.ErrGmailNotAuthorized) {
+		s.respondError(w, http.StatusUnauthorized, err.Error()+"; authorize at /oauth/gmail/start (add ?access=follow-up for labels and replies)")
+		return
+	}
+	s.respondError(w, http.StatusInternalServerError, err.Error())
 }
 
-// handleReport returns the evaluation report
+// handleReport returns the evaluation report, filtered, sorted and paged by
+// the query parameters (see reportQueryFromRequest)
 func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
-	report, err := s.agent.GetReport()
+	query, err := reportQueryFromRequest(r)
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := s.agent.QueryReport(query)
+	if errors.Is(err, agent.ErrInvalidQuery) {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		s.respondError(w, http.StatusNotFound, err.Error())
 		return
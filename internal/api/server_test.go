@@ -0,0 +1,153 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/agent"
+	"github.com/fmuoria/CV-Review-agent/internal/auth"
+)
+
+// testKeys are API keys of each role created by newTestServer
+type testKeys struct {
+	viewer, recruiter, admin string
+}
+
+// newTestServer returns a server with authentication enabled, keeping its API
+// keys and job descriptions in a temporary folder
+func newTestServer(t *testing.T) (*Server, http.Handler, testKeys) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("API_AUTH", "")
+	t.Setenv("API_KEYS", "")
+	t.Setenv("API_KEYS_FILE", filepath.Join(dir, "api_keys.json"))
+	t.Setenv("JOB_DESCRIPTIONS_FILE", filepath.Join(dir, "job_descriptions.json"))
+	t.Setenv("OIDC_ISSUER", "")
+
+	s, err := NewServer(agent.NewCVReviewAgent())
+	if err != nil {
+		t.Fatalf("NewServer() returned error: %v", err)
+	}
+
+	var keys testKeys
+	for role, key := range map[auth.Role]*string{
+		auth.RoleViewer:    &keys.viewer,
+		auth.RoleRecruiter: &keys.recruiter,
+		auth.RoleAdmin:     &keys.admin,
+	} {
+		if _, *key, err = s.keys.Create("test-"+string(role), role); err != nil {
+			t.Fatalf("Failed to create %s key: %v", role, err)
+		}
+	}
+	return s, s.Router(), keys
+}
+
+// serve sends a request with an optional API key and JSON body to handler
+func serve(handler http.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	r := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// TestIMAPConfigFromForm tests that the server's IMAP credentials are only used
+// with the server's own connection settings
+func TestIMAPConfigFromForm(t *testing.T) {
+	t.Setenv("IMAP_HOST", "imap.company.com")
+	t.Setenv("IMAP_PORT", "993")
+	t.Setenv("IMAP_SECURITY", "tls")
+	t.Setenv("IMAP_USERNAME", "jobs@company.com")
+	t.Setenv("IMAP_PASSWORD", "server-secret")
+	t.Setenv("IMAP_AUTH", "")
+	t.Setenv("IMAP_FOLDER", "Applications")
+
+	tests := []struct {
+		name         string
+		form         url.Values
+		wantErr      bool
+		wantHost     string
+		wantUsername string
+		wantPassword string
+	}{
+		{
+			name:         "server mailbox",
+			form:         url.Values{"imap_subject": {"Go Engineer"}},
+			wantHost:     "imap.company.com",
+			wantUsername: "jobs@company.com",
+			wantPassword: "server-secret",
+		},
+		{
+			name:    "other host with server login",
+			form:    url.Values{"imap_host": {"attacker.example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "other port with server login",
+			form:    url.Values{"imap_port": {"143"}},
+			wantErr: true,
+		},
+		{
+			name:    "plain connection with server login",
+			form:    url.Values{"imap_security": {"none"}},
+			wantErr: true,
+		},
+		{
+			name:    "other host with only a username",
+			form:    url.Values{"imap_host": {"mail.example.com"}, "imap_username": {"me@example.com"}},
+			wantErr: true,
+		},
+		{
+			name: "other host with own login",
+			form: url.Values{
+				"imap_host":     {"mail.example.com"},
+				"imap_username": {"me@example.com"},
+				"imap_password": {"my-secret"},
+			},
+			wantHost:     "mail.example.com",
+			wantUsername: "me@example.com",
+			wantPassword: "my-secret",
+		},
+		{
+			name:    "own username with server password",
+			form:    url.Values{"imap_username": {"ceo@company.com"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			cfg, err := imapConfigFromForm(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got host %q user %q", cfg.Host, cfg.Username)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("imapConfigFromForm() returned error: %v", err)
+			}
+			if cfg.Host != tt.wantHost || cfg.Username != tt.wantUsername || cfg.Password != tt.wantPassword {
+				t.Errorf("got host %q user %q password %q; want %q %q %q",
+					cfg.Host, cfg.Username, cfg.Password, tt.wantHost, tt.wantUsername, tt.wantPassword)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"path/filepath"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// saveUploads saves the uploaded files that pass the checks and reports
+// whether each one was accepted
+func (s *Server) saveUploads(files []*multipart.FileHeader) []models.UploadResult {
+	results := make([]models.UploadResult, 0, len(files))
+	for _, header := range files {
+		result := s.saveUpload(header)
+		if result.Status == models.UploadRejected {
+			log.Printf("Rejected upload %q: %s", header.Filename, result.Reason)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// saveUpload sanitizes the name of one upload, checks its size and that its
+// content matches its extension, then saves it. ZIP bundles (e.g. job board
+// exports) are unpacked and exported emails (.eml, .mbox) contribute their attachments.
+func (s *Server) saveUpload(header *multipart.FileHeader) models.UploadResult {
+	result := models.UploadResult{
+		File:   header.Filename,
+		Size:   header.Size,
+		Status: models.UploadRejected,
+	}
+
+	name, err := ingestion.SanitizeUploadName(header.Filename)
+	if err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	if limit := ingestion.MaxUploadSize(name); header.Size > limit {
+		result.Reason = fmt.Sprintf("file exceeds maximum size of %d MB", limit>>20)
+		return result
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		result.Reason = fmt.Sprintf("failed to open uploaded file: %v", err)
+		return result
+	}
+	defer file.Close()
+
+	head := make([]byte, ingestion.UploadSniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		result.Reason = fmt.Sprintf("failed to read uploaded file: %v", err)
+		return result
+	}
+	if err := ingestion.CheckUploadContent(name, head[:n]); err != nil {
+		result.Reason = err.Error()
+		return result
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		result.Reason = fmt.Sprintf("failed to read uploaded file: %v", err)
+		return result
+	}
+
+	fileHandler := s.agent.FileHandler
+	switch {
+	case ingestion.IsArchiveFile(name):
+		saved, err := fileHandler.SaveUploadedArchive(file)
+		if err != nil {
+			result.Reason = fmt.Sprintf("failed to extract archive: %v", err)
+			return result
+		}
+		if len(saved) == 0 {
+			result.Reason = "archive contains no supported documents"
+			return result
+		}
+		log.Printf("Extracted %d file(s) from archive: %s", len(saved), name)
+		result.Documents = len(saved)
+
+	case ingestion.IsMailFile(name):
+		saved, err := fileHandler.ImportMail(name, file)
+		if err != nil {
+			result.Reason = fmt.Sprintf("failed to import: %v", err)
+			return result
+		}
+		if saved == 0 {
+			result.Reason = "no supported attachments found"
+			return result
+		}
+		log.Printf("Imported %d attachment(s) from: %s", saved, name)
+		result.Documents = saved
+
+	default:
+		path, err := fileHandler.SaveUploadedFile(name, file)
+		if err != nil {
+			result.Reason = fmt.Sprintf("failed to save file: %v", err)
+			return result
+		}
+		log.Printf("Saved file: %s", filepath.Base(path))
+		result.SavedAs = filepath.Base(path)
+	}
+
+	result.Status = models.UploadAccepted
+	return result
+}
+
+// countUploads counts the upload results with the given status
+func countUploads(results []models.UploadResult, status string) int {
+	count := 0
+	for _, result := range results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
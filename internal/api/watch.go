@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// watchStatus describes the folder watch started through POST /watch
+type watchStatus struct {
+	Running    bool   `json:"running"`
+	Dir        string `json:"dir,omitempty"`
+	StartedAt  string `json:"started_at,omitempty"`
+	LastUpdate string `json:"last_update,omitempty"`
+	Applicants int    `json:"applicants"`
+	Error      string `json:"error,omitempty"`
+	// Results is the ranking of the watched folder, kept apart from GET /report
+	Results []models.ApplicantResult `json:"results,omitempty"`
+}
+
+// handleWatchStart starts watching WATCH_DIR, scoring applications as they land.
+// The folder is configured on the server so clients cannot point it elsewhere.
+func (s *Server) handleWatchStart(w http.ResponseWriter, r *http.Request) {
+	dir := os.Getenv("WATCH_DIR")
+	if dir == "" {
+		s.respondError(w, http.StatusBadRequest, "watch mode is not configured; set WATCH_DIR on the server")
+		return
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		s.respondError(w, http.StatusInternalServerError, "WATCH_DIR is not a readable directory")
+		return
+	}
+
+	jobDescJSON, err := s.jobDescriptionFromForm(r)
+	if err != nil {
+		s.respondInvalidRequest(w, err)
+		return
+	}
+
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	if s.watchCancel != nil {
+		s.respondError(w, http.StatusConflict, "a folder is already being watched; DELETE /watch to stop it")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.watchCancel = cancel
+	s.watch = watchStatus{
+		Running:   true,
+		Dir:       dir,
+		StartedAt: time.Now().Format(time.RFC3339),
+	}
+
+	go func() {
+		err := s.agent.WatchFolderWithContext(ctx, dir, jobDescJSON, func(results []models.ApplicantResult) {
+			s.watchMu.Lock()
+			s.watch.LastUpdate = time.Now().Format(time.RFC3339)
+			s.watch.Applicants = len(results)
+			s.watch.Results = results
+			s.watchMu.Unlock()
+		})
+
+		s.watchMu.Lock()
+		defer s.watchMu.Unlock()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Folder watch stopped: %v", err)
+			s.watch.Error = err.Error()
+		}
+		s.watch.Running = false
+		s.watchCancel = nil
+	}()
+
+	s.respondJSON(w, http.StatusAccepted, s.watch)
+}
+
+// handleWatchStatus reports whether a folder is being watched
+func (s *Server) handleWatchStatus(w http.ResponseWriter, r *http.Request) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	s.respondJSON(w, http.StatusOK, s.watch)
+}
+
+// handleWatchStop stops the folder watch. The last ranking stays available from GET /watch.
+func (s *Server) handleWatchStop(w http.ResponseWriter, r *http.Request) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	if s.watchCancel == nil {
+		s.respondError(w, http.StatusNotFound, "no folder is being watched")
+		return
+	}
+	s.watchCancel()
+	s.respondJSON(w, http.StatusOK, map[string]string{
+		"status": "stopping",
+	})
+}
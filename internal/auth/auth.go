@@ -0,0 +1,62 @@
+// Package auth authenticates API clients and defines the roles they act under
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Role is what an API client is allowed to do. Each role includes the
+// permissions of the roles below it: admin > recruiter > viewer.
+type Role string
+
+const (
+	// RoleViewer can read reports and status
+	RoleViewer Role = "viewer"
+	// RoleRecruiter can also ingest and score applications and use Gmail
+	RoleRecruiter Role = "recruiter"
+	// RoleAdmin can also manage API keys
+	RoleAdmin Role = "admin"
+)
+
+// roleRank orders the roles from least to most privileged
+var roleRank = map[Role]int{
+	RoleViewer:    1,
+	RoleRecruiter: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (use admin, recruiter or viewer)", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants the permissions of required
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+// Principal is the authenticated client of a request
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated client
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the authenticated client of a request context
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,35 @@
+package auth
+
+import "testing"
+
+// TestRoleAllows tests the role hierarchy
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{RoleAdmin, RoleViewer, true},
+		{RoleAdmin, RoleAdmin, true},
+		{RoleRecruiter, RoleViewer, true},
+		{RoleRecruiter, RoleAdmin, false},
+		{RoleViewer, RoleRecruiter, false},
+		{Role("guest"), RoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+// TestParseRole tests role name validation
+func TestParseRole(t *testing.T) {
+	if role, err := ParseRole(" Recruiter "); err != nil || role != RoleRecruiter {
+		t.Errorf("ParseRole() = %q, %v", role, err)
+	}
+	if _, err := ParseRole("owner"); err == nil {
+		t.Error("Expected error for unknown role")
+	}
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksCacheTTL is how long signing keys are used before they are fetched again
+	jwksCacheTTL = time.Hour
+	// jwksMinRefresh limits refetches triggered by tokens signed with an unknown key
+	jwksMinRefresh = time.Minute
+)
+
+// jsonWebKey is a public key from a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksCache fetches an issuer's signing keys and keeps them for jwksCacheTTL.
+// A token signed with an unknown key triggers a refetch, so key rotation is
+// picked up without a restart.
+type jwksCache struct {
+	uri    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// key returns the public key with the given ID
+func (c *jwksCache) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok && time.Since(c.fetched) < jwksCacheTTL {
+		return key, nil
+	}
+	if c.keys == nil || time.Since(c.fetched) >= jwksMinRefresh {
+		if err := c.refresh(ctx); err != nil {
+			// Keep using the cached keys if the issuer is briefly unavailable
+			if key, ok := c.keys[kid]; ok {
+				log.Printf("Warning: failed to refresh signing keys, using cached keys: %v", err)
+				return key, nil
+			}
+			return nil, err
+		}
+	}
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh downloads the key set; callers hold the lock
+func (c *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.uri, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch signing keys: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("failed to parse signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Warning: skipping signing key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetched = time.Now()
+	return nil
+}
+
+// publicKey decodes an RSA or EC key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("EC point is not on the curve")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// jwtHeader is the protected header of a signed JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// splitJWT decodes the header of a compact JWT and returns it with the signed
+// part, the payload and the signature
+func splitJWT(token string) (jwtHeader, []byte, []byte, []byte, error) {
+	var header jwtHeader
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return header, nil, nil, nil, fmt.Errorf("malformed token")
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return header, nil, nil, nil, fmt.Errorf("malformed token header")
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return header, nil, nil, nil, fmt.Errorf("malformed token header")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return header, nil, nil, nil, fmt.Errorf("malformed token payload")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return header, nil, nil, nil, fmt.Errorf("malformed token signature")
+	}
+	return header, []byte(parts[0] + "." + parts[1]), payload, signature, nil
+}
+
+// verifySignature checks a JWS signature. Only asymmetric algorithms are
+// accepted, so "none" and HMAC tokens signed with a public key are rejected.
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var h hash.Hash
+	var cryptoHash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		h, cryptoHash = sha256.New(), crypto.SHA256
+	case "RS384", "ES384":
+		h, cryptoHash = sha512.New384(), crypto.SHA384
+	case "RS512", "ES512":
+		h, cryptoHash = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm %s does not match the RSA key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(pub, cryptoHash, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm %s does not match the EC key", alg)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type %T", key)
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix makes keys recognizable in logs and secret scanners
+const apiKeyPrefix = "cvr_"
+
+// ErrKeyNotFound is returned when revoking a key that does not exist
+var ErrKeyNotFound = errors.New("API key not found")
+
+// APIKey describes an API key. Only a hash of the key itself is kept.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	Hash      string    `json:"hash,omitempty"`
+	// FromConfig marks keys set through API_KEYS, which cannot be revoked through the API
+	FromConfig bool `json:"from_config,omitempty"`
+}
+
+// KeyStore holds the API keys from configuration and those created through
+// the admin endpoint, which are saved to a JSON file
+type KeyStore struct {
+	path   string
+	mu     sync.RWMutex
+	keys   []APIKey          // stored keys
+	config []APIKey          // keys from configuration
+	byHash map[string]APIKey // all keys by hash
+}
+
+// NewKeyStore loads the stored keys from path (if it exists) and adds the
+// configured keys, given as comma-separated name:role:key entries
+func NewKeyStore(path, configKeys string) (*KeyStore, error) {
+	s := &KeyStore{path: path}
+
+	for _, entry := range strings.Split(configKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, expected name:role:key", parts[0])
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		s.config = append(s.config, APIKey{
+			ID:         "config-" + parts[0],
+			Name:       parts[0],
+			Role:       role,
+			Hash:       hashKey(parts[2]),
+			FromConfig: true,
+		})
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read API keys: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &s.keys); err != nil {
+				return nil, fmt.Errorf("failed to parse API keys %s: %w", path, err)
+			}
+		}
+	}
+
+	s.index()
+	return s, nil
+}
+
+// index rebuilds the hash lookup; callers hold the write lock
+func (s *KeyStore) index() {
+	s.byHash = make(map[string]APIKey, len(s.keys)+len(s.config))
+	for _, key := range s.keys {
+		s.byHash[key.Hash] = key
+	}
+	for _, key := range s.config {
+		s.byHash[key.Hash] = key
+	}
+}
+
+// Len returns the number of keys
+func (s *KeyStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.byHash)
+}
+
+// Authenticate returns the client a key belongs to
+func (s *KeyStore) Authenticate(key string) (Principal, bool) {
+	if key == "" {
+		return Principal{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	apiKey, ok := s.byHash[hashKey(key)]
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{Name: apiKey.Name, Role: apiKey.Role}, true
+}
+
+// List returns the keys without their hashes, oldest first
+func (s *KeyStore) List() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.config)+len(s.keys))
+	keys = append(keys, s.config...)
+	keys = append(keys, s.keys...)
+	for i := range keys {
+		keys[i].Hash = ""
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// Create generates and saves a new key. The key is returned once; only its hash is stored.
+func (s *KeyStore) Create(name string, role Role) (APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return APIKey{}, "", fmt.Errorf("name is required")
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return APIKey{}, "", err
+	}
+
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return APIKey{}, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Role:      role,
+		CreatedAt: time.Now().UTC(),
+		Hash:      hashKey(key),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := append(append([]APIKey{}, s.keys...), apiKey)
+	if err := s.save(keys); err != nil {
+		return APIKey{}, "", err
+	}
+	s.keys = keys
+	s.index()
+
+	apiKey.Hash = ""
+	return apiKey, key, nil
+}
+
+// Revoke deletes a stored key
+func (s *KeyStore) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.config {
+		if key.ID == id {
+			return fmt.Errorf("key %s is set in configuration; remove it from API_KEYS instead", id)
+		}
+	}
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if key.ID != id {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == len(s.keys) {
+		return ErrKeyNotFound
+	}
+	if err := s.save(keys); err != nil {
+		return err
+	}
+	s.keys = keys
+	s.index()
+	return nil
+}
+
+// save writes the stored keys to a private file, replacing it atomically
+func (s *KeyStore) save(keys []APIKey) error {
+	if s.path == "" {
+		return fmt.Errorf("no API key file configured")
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".api-keys-*")
+	if err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	return nil
+}
+
+// hashKey returns the stored form of a key
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
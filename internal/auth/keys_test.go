@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestKeyStore_CreateAuthenticateRevoke tests the lifecycle of a stored key
+func TestKeyStore_CreateAuthenticateRevoke(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_keys.json")
+	store, err := NewKeyStore(path, "")
+	if err != nil {
+		t.Fatalf("NewKeyStore() returned error: %v", err)
+	}
+
+	apiKey, key, err := store.Create("recruiting", RoleRecruiter)
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		t.Errorf("Expected key to start with %s", apiKeyPrefix)
+	}
+
+	// Only the hash is written, with owner-only permissions
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), key) {
+		t.Error("Key must not be stored in plain text")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected key file with 0600 permissions, got %v", info)
+	}
+
+	// A new store sees the saved key
+	reloaded, err := NewKeyStore(path, "")
+	if err != nil {
+		t.Fatalf("NewKeyStore() returned error: %v", err)
+	}
+	principal, ok := reloaded.Authenticate(key)
+	if !ok || principal.Name != "recruiting" || principal.Role != RoleRecruiter {
+		t.Fatalf("Authenticate() = %+v, %v", principal, ok)
+	}
+	if _, ok := reloaded.Authenticate(key + "x"); ok {
+		t.Error("Wrong key should not authenticate")
+	}
+	if keys := reloaded.List(); len(keys) != 1 || keys[0].Hash != "" {
+		t.Errorf("List() should return one key without its hash, got %+v", keys)
+	}
+
+	if err := reloaded.Revoke(apiKey.ID); err != nil {
+		t.Fatalf("Revoke() returned error: %v", err)
+	}
+	if _, ok := reloaded.Authenticate(key); ok {
+		t.Error("Revoked key should not authenticate")
+	}
+	if err := reloaded.Revoke(apiKey.ID); err != ErrKeyNotFound {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+// TestKeyStore_ConfigKeys tests keys set through configuration
+func TestKeyStore_ConfigKeys(t *testing.T) {
+	store, err := NewKeyStore("", "ci:viewer:s3cret, ops:ADMIN:0ther")
+	if err != nil {
+		t.Fatalf("NewKeyStore() returned error: %v", err)
+	}
+	if principal, ok := store.Authenticate("0ther"); !ok || principal.Role != RoleAdmin {
+		t.Errorf("Expected ops to be an admin, got %+v", principal)
+	}
+	if err := store.Revoke("config-ci"); err == nil {
+		t.Error("Configured keys should not be revocable")
+	}
+
+	for _, bad := range []string{"ci:s3cret", "ci:owner:s3cret", ":viewer:s3cret"} {
+		if _, err := NewKeyStore("", bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		} else if strings.Contains(err.Error(), "s3cret") {
+			t.Errorf("Error must not reveal the key: %v", err)
+		}
+	}
+}
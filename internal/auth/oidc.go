@@ -0,0 +1,414 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oidcLoginTimeout is how long a login may take between redirect and callback
+	oidcLoginTimeout = 10 * time.Minute
+	// oidcClockSkew tolerates small clock differences with the issuer
+	oidcClockSkew = time.Minute
+	// maxPendingLogins caps the logins waiting for their callback; the oldest
+	// are dropped first, as /oauth/login can be called without credentials
+	maxPendingLogins = 1000
+)
+
+// ErrNoRole is returned for valid identities whose groups grant no role
+var ErrNoRole = errors.New("your groups do not grant access to the CV Review API")
+
+// OIDCConfig configures login through an OpenID Connect provider (company SSO)
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// GroupsClaim is the ID token claim listing the user's groups (default "groups")
+	GroupsClaim string
+	// RoleGroups maps each role to the groups that grant it
+	RoleGroups map[Role][]string
+	// HTTPClient is used for discovery, keys and token exchange (default http.DefaultClient)
+	HTTPClient *http.Client
+}
+
+// Validate checks the required settings
+func (c OIDCConfig) Validate() error {
+	if c.Issuer == "" || c.ClientID == "" {
+		return fmt.Errorf("OIDC issuer and client ID are required")
+	}
+	total := 0
+	for role, groups := range c.RoleGroups {
+		if _, err := ParseRole(string(role)); err != nil {
+			return err
+		}
+		total += len(groups)
+	}
+	if total == 0 {
+		return fmt.Errorf("no OIDC groups are mapped to a role")
+	}
+	return nil
+}
+
+// oidcDiscovery is the part of the provider metadata that is used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims are the standard ID token claims that are checked
+type idTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	Expiry            float64  `json:"exp"`
+	NotBefore         float64  `json:"nbf"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// audience accepts the "aud" claim as a string or a list
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// OIDCProvider logs users in with the authorization-code flow and validates
+// their ID tokens against the provider's published signing keys
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	jwks      *jwksCache
+	pending   map[string]oidcLogin
+}
+
+// oidcLogin is a login waiting for its callback
+type oidcLogin struct {
+	redirectURL string
+	nonce       string
+	verifier    string
+	expires     time.Time
+}
+
+// NewOIDCProvider creates a provider. The provider metadata is fetched on
+// first use, so an unreachable issuer does not stop the server from starting.
+func NewOIDCProvider(config OIDCConfig) (*OIDCProvider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &OIDCProvider{
+		config:  config,
+		client:  client,
+		now:     time.Now,
+		pending: make(map[string]oidcLogin),
+	}, nil
+}
+
+// discover fetches and caches the provider metadata. The lock is not held
+// while fetching, so a slow issuer does not block logins already under way.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, *jwksCache, error) {
+	p.mu.Lock()
+	if p.discovery != nil {
+		defer p.mu.Unlock()
+		return p.discovery, p.jwks, nil
+	}
+	p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch OIDC provider metadata: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch OIDC provider metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to fetch OIDC provider metadata: %s", resp.Status)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&discovery); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OIDC provider metadata: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.config.Issuer {
+		return nil, nil, fmt.Errorf("OIDC provider reports issuer %q, expected %q", discovery.Issuer, p.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("OIDC provider metadata is missing endpoints")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Keep the first result if requests fetched the metadata at the same time
+	if p.discovery == nil {
+		p.discovery = &discovery
+		p.jwks = &jwksCache{uri: discovery.JWKSURI, client: p.client}
+	}
+	return p.discovery, p.jwks, nil
+}
+
+// oauthConfig returns the OAuth client for the provider
+func (p *OIDCProvider) oauthConfig(discovery *oidcDiscovery, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+}
+
+// Start returns the provider's login page URL. The provider sends the user
+// back to redirectURL, whose handler calls Complete.
+func (p *OIDCProvider) Start(ctx context.Context, redirectURL string) (string, error) {
+	discovery, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	p.mu.Lock()
+	now := p.now()
+	for key, login := range p.pending {
+		if now.After(login.expires) {
+			delete(p.pending, key)
+		}
+	}
+	for len(p.pending) >= maxPendingLogins {
+		p.dropOldestLogin()
+	}
+	p.pending[state] = oidcLogin{
+		redirectURL: redirectURL,
+		nonce:       nonce,
+		verifier:    verifier,
+		expires:     now.Add(oidcLoginTimeout),
+	}
+	p.mu.Unlock()
+
+	return p.oauthConfig(discovery, redirectURL).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	), nil
+}
+
+// dropOldestLogin forgets the pending login that expires first; callers hold p.mu
+func (p *OIDCProvider) dropOldestLogin() {
+	var oldest string
+	var expires time.Time
+	for key, login := range p.pending {
+		if oldest == "" || login.expires.Before(expires) {
+			oldest, expires = key, login.expires
+		}
+	}
+	delete(p.pending, oldest)
+}
+
+// Complete exchanges the code the provider returned for an ID token and
+// returns the user with the token, which the client sends as a bearer token
+func (p *OIDCProvider) Complete(ctx context.Context, state, code string) (Principal, string, time.Time, error) {
+	p.mu.Lock()
+	login, ok := p.pending[state]
+	delete(p.pending, state)
+	p.mu.Unlock()
+	if !ok || p.now().After(login.expires) {
+		return Principal{}, "", time.Time{}, fmt.Errorf("unknown or expired login request; please start again")
+	}
+	if code == "" {
+		return Principal{}, "", time.Time{}, fmt.Errorf("login was not completed")
+	}
+
+	discovery, _, err := p.discover(ctx)
+	if err != nil {
+		return Principal{}, "", time.Time{}, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	tok, err := p.oauthConfig(discovery, login.redirectURL).Exchange(ctx, code, oauth2.VerifierOption(login.verifier))
+	if err != nil {
+		return Principal{}, "", time.Time{}, fmt.Errorf("unable to exchange authorization code: %w", err)
+	}
+	rawIDToken, _ := tok.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return Principal{}, "", time.Time{}, fmt.Errorf("OIDC provider did not return an ID token")
+	}
+
+	claims, groups, err := p.verify(ctx, rawIDToken)
+	if err != nil {
+		return Principal{}, "", time.Time{}, err
+	}
+	if claims.Nonce != login.nonce {
+		return Principal{}, "", time.Time{}, fmt.Errorf("ID token nonce does not match the login request")
+	}
+	principal, err := p.principal(claims, groups)
+	if err != nil {
+		return Principal{}, "", time.Time{}, err
+	}
+	return principal, rawIDToken, time.Unix(int64(claims.Expiry), 0), nil
+}
+
+// Authenticate validates an ID token sent as a bearer token and returns its user.
+// ErrNoRole is returned when the token is valid but its groups grant no role.
+func (p *OIDCProvider) Authenticate(ctx context.Context, rawToken string) (Principal, error) {
+	claims, groups, err := p.verify(ctx, rawToken)
+	if err != nil {
+		return Principal{}, err
+	}
+	return p.principal(claims, groups)
+}
+
+// verify checks an ID token's signature, issuer, audience and validity period
+func (p *OIDCProvider) verify(ctx context.Context, rawToken string) (idTokenClaims, []string, error) {
+	var claims idTokenClaims
+
+	header, signed, payload, signature, err := splitJWT(rawToken)
+	if err != nil {
+		return claims, nil, err
+	}
+	_, jwks, err := p.discover(ctx)
+	if err != nil {
+		return claims, nil, err
+	}
+	key, err := jwks.key(ctx, header.Kid)
+	if err != nil {
+		return claims, nil, err
+	}
+	if err := verifySignature(header.Alg, key, signed, signature); err != nil {
+		return claims, nil, err
+	}
+
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, nil, fmt.Errorf("malformed token claims")
+	}
+	if strings.TrimSuffix(claims.Issuer, "/") != p.config.Issuer {
+		return claims, nil, fmt.Errorf("token was issued by %q", claims.Issuer)
+	}
+	if !containsString(claims.Audience, p.config.ClientID) {
+		return claims, nil, fmt.Errorf("token is not intended for this client")
+	}
+	now := p.now()
+	if claims.Expiry == 0 || now.After(time.Unix(int64(claims.Expiry), 0).Add(oidcClockSkew)) {
+		return claims, nil, fmt.Errorf("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Add(oidcClockSkew).Before(time.Unix(int64(claims.NotBefore), 0)) {
+		return claims, nil, fmt.Errorf("token is not valid yet")
+	}
+
+	groups, err := groupsClaim(payload, p.config.GroupsClaim)
+	if err != nil {
+		return claims, nil, err
+	}
+	return claims, groups, nil
+}
+
+// principal maps the user's groups to the most privileged role they grant
+func (p *OIDCProvider) principal(claims idTokenClaims, groups []string) (Principal, error) {
+	name := claims.Email
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = claims.Subject
+	}
+
+	var best Role
+	for role, roleGroups := range p.config.RoleGroups {
+		for _, group := range roleGroups {
+			if containsString(groups, group) && (best == "" || role.Allows(best)) {
+				best = role
+			}
+		}
+	}
+	if best == "" {
+		return Principal{Name: name}, ErrNoRole
+	}
+	return Principal{Name: name, Role: best}, nil
+}
+
+// groupsClaim reads the groups claim, which may be a list or a single string
+func groupsClaim(payload []byte, claim string) ([]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("malformed token claims")
+	}
+	value, ok := raw[claim]
+	if !ok {
+		return nil, nil
+	}
+	var groups []string
+	if err := json.Unmarshal(value, &groups); err == nil {
+		return groups, nil
+	}
+	var group string
+	if err := json.Unmarshal(value, &group); err != nil {
+		return nil, fmt.Errorf("claim %q is not a list of groups", claim)
+	}
+	return []string{group}, nil
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// randomToken returns an unguessable URL-safe string
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create login state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIssuer is a local OpenID Connect provider that signs ID tokens with RSA
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	mu         sync.Mutex
+	jwksHits   int
+	codes      map[string]string // code -> nonce
+	challenges map[string]string // code -> PKCE challenge
+	groups     []string
+}
+
+// newFakeIssuer starts a fake provider whose users belong to groups
+func newFakeIssuer(t *testing.T, groups ...string) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	f := &fakeIssuer{key: key, kid: "key-1", codes: map[string]string{}, challenges: map[string]string{}, groups: groups}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.server.URL,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksHits++
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": f.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		code := r.PostForm.Get("code")
+		f.mu.Lock()
+		nonce, ok := f.codes[code]
+		challenge := f.challenges[code]
+		f.mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     f.token(t, f.claims(map[string]interface{}{"nonce": nonce})),
+		})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// claims returns valid ID token claims with overrides applied
+func (f *fakeIssuer) claims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":    f.server.URL,
+		"sub":    "user-1",
+		"aud":    "cv-review",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"iat":    time.Now().Unix(),
+		"email":  "jane@example.com",
+		"groups": f.groups,
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	return claims
+}
+
+// token signs claims with the issuer's current key
+func (f *fakeIssuer) token(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	f.mu.Lock()
+	key, kid := f.key, f.kid
+	f.mu.Unlock()
+	return signRS256(t, key, kid, claims)
+}
+
+// signRS256 builds a compact RS256 JWT
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// newTestProvider creates a provider for the fake issuer
+func newTestProvider(t *testing.T, f *fakeIssuer) *OIDCProvider {
+	t.Helper()
+	p, err := NewOIDCProvider(OIDCConfig{
+		Issuer:   f.server.URL,
+		ClientID: "cv-review",
+		RoleGroups: map[Role][]string{
+			RoleRecruiter: {"hr-recruiters"},
+			RoleViewer:    {"hiring-managers"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider() returned error: %v", err)
+	}
+	return p
+}
+
+// TestOIDCProvider_Authenticate tests ID token validation and group mapping
+func TestOIDCProvider_Authenticate(t *testing.T) {
+	f := newFakeIssuer(t, "hiring-managers", "hr-recruiters")
+	p := newTestProvider(t, f)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Role
+		wantErr string
+	}{
+		{"valid", f.token(t, f.claims(nil)), RoleRecruiter, ""},
+		{"audience list", f.token(t, f.claims(map[string]interface{}{"aud": []string{"other", "cv-review"}})), RoleRecruiter, ""},
+		{"viewer group", f.token(t, f.claims(map[string]interface{}{"groups": "hiring-managers"})), RoleViewer, ""},
+		{"no matching group", f.token(t, f.claims(map[string]interface{}{"groups": []string{"sales"}})), "", "do not grant access"},
+		{"wrong audience", f.token(t, f.claims(map[string]interface{}{"aud": "other-app"})), "", "not intended"},
+		{"wrong issuer", f.token(t, f.claims(map[string]interface{}{"iss": "https://evil.example.com"})), "", "issued by"},
+		{"expired", f.token(t, f.claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})), "", "expired"},
+		{"not yet valid", f.token(t, f.claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})), "", "not valid yet"},
+		{"forged signature", signRS256(t, otherKey, "key-1", f.claims(nil)), "", "signature"},
+		{"alg none", unsignedToken(f.claims(nil)), "", "unsupported signing algorithm"},
+		{"malformed", "not-a-jwt", "", "malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := p.Authenticate(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() returned error: %v", err)
+			}
+			if principal.Role != tt.want || principal.Name != "jane@example.com" {
+				t.Errorf("Authenticate() = %+v, want role %s", principal, tt.want)
+			}
+		})
+	}
+}
+
+// unsignedToken builds a JWT with alg "none"
+func unsignedToken(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "kid": "key-1"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+// TestOIDCProvider_CachesAndRotatesKeys tests that signing keys are cached and refetched for unknown key IDs
+func TestOIDCProvider_CachesAndRotatesKeys(t *testing.T) {
+	f := newFakeIssuer(t, "hr-recruiters")
+	p := newTestProvider(t, f)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Authenticate(ctx, f.token(t, f.claims(nil))); err != nil {
+			t.Fatalf("Authenticate() returned error: %v", err)
+		}
+	}
+	if f.jwksHits != 1 {
+		t.Errorf("Expected keys to be fetched once, got %d", f.jwksHits)
+	}
+
+	// The issuer rotates its key; once the refetch interval has passed the new key is picked up
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	f.mu.Lock()
+	f.key, f.kid = newKey, "key-2"
+	f.mu.Unlock()
+	token := f.token(t, f.claims(nil))
+
+	if _, err := p.Authenticate(ctx, token); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Errorf("Expected unknown key before the refetch interval, got %v", err)
+	}
+	p.jwks.fetched = time.Now().Add(-2 * jwksMinRefresh)
+	if _, err := p.Authenticate(ctx, token); err != nil {
+		t.Errorf("Expected rotated key to be accepted, got %v", err)
+	}
+	if f.jwksHits != 2 {
+		t.Errorf("Expected keys to be fetched again, got %d fetches", f.jwksHits)
+	}
+}
+
+// TestOIDCProvider_LoginFlow tests the authorization-code flow with PKCE and nonce
+func TestOIDCProvider_LoginFlow(t *testing.T) {
+	f := newFakeIssuer(t, "hr-recruiters")
+	p := newTestProvider(t, f)
+	ctx := context.Background()
+
+	authURL, err := p.Start(ctx, "http://localhost:8080/oauth/login/callback")
+	if err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if !strings.HasPrefix(authURL, f.server.URL+"/authorize") || q.Get("code_challenge_method") != "S256" || q.Get("nonce") == "" {
+		t.Fatalf("Unexpected login URL: %s", authURL)
+	}
+
+	// The user logs in and the provider issues a code for this login
+	f.mu.Lock()
+	f.codes["code-1"] = q.Get("nonce")
+	f.challenges["code-1"] = q.Get("code_challenge")
+	f.mu.Unlock()
+
+	principal, token, expires, err := p.Complete(ctx, q.Get("state"), "code-1")
+	if err != nil {
+		t.Fatalf("Complete() returned error: %v", err)
+	}
+	if principal.Role != RoleRecruiter || token == "" || time.Until(expires) < 30*time.Minute {
+		t.Errorf("Unexpected login result: %+v, expires %v", principal, expires)
+	}
+
+	// The returned token authenticates API requests
+	if got, err := p.Authenticate(ctx, token); err != nil || got != principal {
+		t.Errorf("Authenticate(token) = %+v, %v", got, err)
+	}
+
+	// States are single-use
+	if _, _, _, err := p.Complete(ctx, q.Get("state"), "code-1"); err == nil {
+		t.Error("Expected a reused state to be rejected")
+	}
+}
+
+// TestOIDCProvider_PendingLoginsCapped tests that unauthenticated login starts
+// cannot grow the pending logins without bound, dropping the oldest first
+func TestOIDCProvider_PendingLoginsCapped(t *testing.T) {
+	f := newFakeIssuer(t, "hr-recruiters")
+	p := newTestProvider(t, f)
+	ctx := context.Background()
+
+	now := time.Now()
+	p.now = func() time.Time { return now }
+
+	var states []string
+	for i := 0; i < maxPendingLogins+10; i++ {
+		now = now.Add(time.Millisecond)
+		authURL, err := p.Start(ctx, "http://localhost:8080/oauth/login/callback")
+		if err != nil {
+			t.Fatalf("Start() returned error: %v", err)
+		}
+		u, _ := url.Parse(authURL)
+		states = append(states, u.Query().Get("state"))
+	}
+
+	p.mu.Lock()
+	pending := len(p.pending)
+	_, oldest := p.pending[states[0]]
+	_, newest := p.pending[states[len(states)-1]]
+	p.mu.Unlock()
+
+	if pending != maxPendingLogins {
+		t.Errorf("pending logins = %d, want %d", pending, maxPendingLogins)
+	}
+	if oldest || !newest {
+		t.Errorf("Expected the oldest login to be dropped and the newest kept (oldest %v, newest %v)", oldest, newest)
+	}
+}
+
+// TestVerifySignature_ES256 tests EC signatures and algorithm/key mismatches
+func TestVerifySignature_ES256(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signed := []byte("header.payload")
+	digest := sha256.Sum256(signed)
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	if err := verifySignature("ES256", &key.PublicKey, signed, sig); err != nil {
+		t.Errorf("Expected valid ES256 signature, got %v", err)
+	}
+	if err := verifySignature("RS256", &key.PublicKey, signed, sig); err == nil {
+		t.Error("Expected RS256 with an EC key to be rejected")
+	}
+	if err := verifySignature("HS256", &key.PublicKey, signed, sig); err == nil {
+		t.Error("Expected HS256 to be rejected")
+	}
+}
+
+// TestOIDCConfig_Validate tests required settings
+func TestOIDCConfig_Validate(t *testing.T) {
+	groups := map[Role][]string{RoleViewer: {"staff"}}
+	tests := []struct {
+		name    string
+		config  OIDCConfig
+		wantErr bool
+	}{
+		{"valid", OIDCConfig{Issuer: "https://sso.example.com", ClientID: "cv-review", RoleGroups: groups}, false},
+		{"missing issuer", OIDCConfig{ClientID: "cv-review", RoleGroups: groups}, true},
+		{"no groups", OIDCConfig{Issuer: "https://sso.example.com", ClientID: "cv-review"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
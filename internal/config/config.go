@@ -14,6 +14,12 @@ type Config struct {
 	GoogleCredentialsPath string `json:"google_credentials_path"`
 	GmailCredentialsPath  string `json:"gmail_credentials_path"`
 	UploadsDir            string `json:"uploads_dir"`
+	// IMAP mailbox used by the GUI; the password is never stored
+	IMAPHost     string `json:"imap_host,omitempty"`
+	IMAPUsername string `json:"imap_username,omitempty"`
+	IMAPFolder   string `json:"imap_folder,omitempty"`
+	// Folder watched for new applications (e.g. a shared drive)
+	WatchDir string `json:"watch_dir,omitempty"`
 }
 
 // DefaultConfig returns a new config with default values
@@ -24,10 +30,10 @@ func DefaultConfig() *Config {
 	}
 }
 
-// GetConfigPath returns the path to the configuration file
-// On Windows: %APPDATA%/CVReviewAgent/config.json
-// On Unix: ~/.config/CVReviewAgent/config.json
-func GetConfigPath() (string, error) {
+// GetConfigDir returns the application's configuration directory, creating it if needed
+// On Windows: %APPDATA%/CVReviewAgent
+// On Unix: ~/.config/CVReviewAgent
+func GetConfigDir() (string, error) {
 	var configDir string
 
 	if os.Getenv("APPDATA") != "" {
@@ -47,6 +53,18 @@ func GetConfigPath() (string, error) {
 		return "", fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	return configDir, nil
+}
+
+// GetConfigPath returns the path to the configuration file
+// On Windows: %APPDATA%/CVReviewAgent/config.json
+// On Unix: ~/.config/CVReviewAgent/config.json
+func GetConfigPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
 	return filepath.Join(configDir, "config.json"), nil
 }
 
@@ -118,8 +118,8 @@ func createSummarySheet(f *excelize.File, sheetName string, results []models.App
 	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Note:")
 	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), labelStyle)
 	noteText := "If fewer candidates than emails/files, files may have been skipped due to: " +
-		"scanned images (no text), certificate-only PDFs, duplicates, unsupported formats, " +
-		"or naming conventions not matching expected pattern (Name_CV.pdf / Name_CoverLetter.pdf)."
+		"unreadable scans, certificates/transcripts/portfolios (not scored), duplicates, unsupported formats, " +
+		"or documents that could not be classified as a CV or cover letter."
 	f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), noteText)
 	row += 2
 
@@ -224,6 +224,18 @@ func createSummarySheet(f *excelize.File, sheetName string, results []models.App
 			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Candidates without Cover Letter:")
 			f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), len(results)-withCL)
 			row++
+
+			// Count candidates flagged for manual review (e.g. low OCR confidence)
+			needsReview := 0
+			for _, r := range results {
+				if r.NeedsReview {
+					needsReview++
+				}
+			}
+
+			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Candidates Needing Manual Review:")
+			f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), needsReview)
+			row++
 		}
 	}
 
@@ -242,6 +254,7 @@ func createRankedCandidatesSheet(f *excelize.File, sheetName string, results []m
 	f.SetColWidth(sheetName, "G", "G", 15)
 	f.SetColWidth(sheetName, "H", "H", 12)
 	f.SetColWidth(sheetName, "I", "I", 12)
+	f.SetColWidth(sheetName, "J", "J", 40)
 
 	// Create header style
 	headerStyle, err := f.NewStyle(&excelize.Style{
@@ -301,7 +314,7 @@ func createRankedCandidatesSheet(f *excelize.File, sheetName string, results []m
 	})
 
 	// Set headers
-	headers := []string{"Rank", "Candidate", "Total Score", "Experience", "Education", "Duties", "Cover Letter", "CV Link", "CL Link"}
+	headers := []string{"Rank", "Candidate", "Total Score", "Experience", "Education", "Duties", "Cover Letter", "CV Link", "CL Link", "Needs Review"}
 	for col, header := range headers {
 		cell := fmt.Sprintf("%s1", string(rune('A'+col)))
 		f.SetCellValue(sheetName, cell, header)
@@ -467,11 +480,20 @@ func createRankedCandidatesSheet(f *excelize.File, sheetName string, results []m
 			f.SetCellValue(sheetName, fmt.Sprintf("I%d", row), "")
 			f.SetCellStyle(sheetName, fmt.Sprintf("I%d", row), fmt.Sprintf("I%d", row), style)
 		}
+
+		// Needs Review (Column J) lists why the candidate's documents should be checked by hand
+		reviewCell := fmt.Sprintf("J%d", row)
+		if result.NeedsReview {
+			f.SetCellValue(sheetName, reviewCell, "Yes: "+strings.Join(result.ReviewReasons, "; "))
+		} else {
+			f.SetCellValue(sheetName, reviewCell, "")
+		}
+		f.SetCellStyle(sheetName, reviewCell, reviewCell, style)
 	}
 
 	// Enable auto-filter
 	if len(results) > 0 {
-		f.AutoFilter(sheetName, fmt.Sprintf("A1:I%d", len(results)+1), []excelize.AutoFilterOptions{})
+		f.AutoFilter(sheetName, fmt.Sprintf("A1:J%d", len(results)+1), []excelize.AutoFilterOptions{})
 	}
 
 	// Freeze top row
@@ -567,6 +589,17 @@ func createDetailedAnalysisSheet(f *excelize.File, sheetName string, results []m
 		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("D%d", row), wrapStyle)
 		f.SetRowHeight(sheetName, row, 60)
 		row++
+
+		// Manual review notes
+		if result.NeedsReview {
+			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), result.Rank)
+			f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), result.Name)
+			f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), "Manual Review")
+			f.SetCellValue(sheetName, fmt.Sprintf("D%d", row), strings.Join(result.ReviewReasons, "\n"))
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("D%d", row), wrapStyle)
+			f.SetRowHeight(sheetName, row, 30)
+			row++
+		}
 	}
 
 	// Freeze top row
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/fmuoria/CV-Review-agent/internal/models"
+	"github.com/xuri/excelize/v2"
 )
 
 // TestExportToExcel_EnsuresXlsxExtension tests that .xlsx extension is added if missing
@@ -150,3 +151,49 @@ func TestExportToExcel_EmptyResults(t *testing.T) {
 		t.Errorf("Expected file at %s but it doesn't exist", outputPath)
 	}
 }
+
+// TestExportToExcel_NeedsReviewColumn tests that flagged candidates are marked in the ranked sheet
+func TestExportToExcel_NeedsReviewColumn(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	results := []models.ApplicantResult{
+		{
+			Name:          "ScannedCandidate",
+			Rank:          1,
+			Scores:        models.Scores{TotalScore: 72},
+			NeedsReview:   true,
+			ReviewReasons: []string{"Low OCR confidence in ScannedCandidate_CV.pdf: page 2 (41%)"},
+		},
+		{
+			Name:   "TypedCandidate",
+			Rank:   2,
+			Scores: models.Scores{TotalScore: 65},
+		},
+	}
+
+	outputPath := filepath.Join(tmpDir, "review_report.xlsx")
+	if err := ExportToExcel(results, models.JobDescription{Title: "Test Job"}, outputPath); err != nil {
+		t.Fatalf("ExportToExcel() returned error: %v", err)
+	}
+
+	f, err := excelize.OpenFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to open exported file: %v", err)
+	}
+	defer f.Close()
+
+	header, _ := f.GetCellValue("Ranked Candidates", "J1")
+	if header != "Needs Review" {
+		t.Errorf("Expected J1 header 'Needs Review', got %q", header)
+	}
+
+	flagged, _ := f.GetCellValue("Ranked Candidates", "J2")
+	if !strings.Contains(flagged, "page 2") {
+		t.Errorf("Expected review reason in J2, got %q", flagged)
+	}
+
+	unflagged, _ := f.GetCellValue("Ranked Candidates", "J3")
+	if unflagged != "" {
+		t.Errorf("Expected empty J3 for unflagged candidate, got %q", unflagged)
+	}
+}
@@ -5,24 +5,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"github.com/fmuoria/CV-Review-agent/internal/agent"
 	"github.com/fmuoria/CV-Review-agent/internal/config"
 	"github.com/fmuoria/CV-Review-agent/internal/export"
 	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/jobs"
+	"github.com/fmuoria/CV-Review-agent/internal/llm"
 	"github.com/fmuoria/CV-Review-agent/internal/models"
 )
 
 const (
 	// gmailCredentialsFilename is the expected filename for Gmail API credentials
 	gmailCredentialsFilename = "credentials.json"
+
+	// Document sources offered on the Process CVs tab
+	sourceGmail   = "Gmail"
+	sourceArchive = "ZIP / Email Export"
+	sourceIMAP    = "IMAP Mailbox"
+	sourceWatch   = "Watch Folder"
 )
 
 // App represents the main GUI application
@@ -31,13 +45,32 @@ type App struct {
 	mainWindow fyne.Window
 	config     *config.Config
 	agent      *agent.CVReviewAgent
+	jobLibrary *jobs.Library
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 
 	// UI Components
+	sourceRadio          *widget.RadioGroup
+	archiveLabel         *widget.Label
+	archivePath          string
+	watchLabel           *widget.Label
+	imapHostEntry        *widget.Entry
+	imapUserEntry        *widget.Entry
+	imapPasswordEntry    *widget.Entry
+	imapAuthSelect       *widget.Select
+	imapFolderEntry      *widget.Entry
 	gmailStatusLabel     *widget.Label
 	authenticateBtn      *widget.Button
 	subjectEntry         *widget.Entry
+	gmailFromEntry       *widget.Entry
+	gmailLabelEntry      *widget.Entry
+	gmailAfterEntry      *widget.Entry
+	gmailBeforeEntry     *widget.Entry
+	gmailExcludeEntry    *widget.Entry
+	gmailQueryEntry      *widget.Entry
+	gmailBodyCheck       *widget.Check
+	gmailLabelCheck      *widget.Check
+	gmailDraftCheck      *widget.Check
 	jobTitleEntry        *widget.Entry
 	requiredExpText      *widget.Entry
 	requiredEduText      *widget.Entry
@@ -46,6 +79,8 @@ type App struct {
 	niceToHaveEduText    *widget.Entry
 	niceToHaveDutiesText *widget.Entry
 	jobDescText          *widget.Entry
+	jobLibraryLabel      *widget.Label
+	jobDescID            string // library ID of the loaded job description
 	processBtn           *widget.Button
 	cancelBtn            *widget.Button
 	progressBar          *widget.ProgressBar
@@ -54,6 +89,8 @@ type App struct {
 	exportBtn            *widget.Button
 
 	results []models.ApplicantResult
+	// resultsJobDesc is the job description results were scored against
+	resultsJobDesc models.JobDescription
 }
 
 // NewApp creates a new GUI application
@@ -79,6 +116,13 @@ func NewApp() *App {
 	// Apply config to environment
 	cfg.ApplyToEnv()
 
+	// Job descriptions are shared with the API server's library
+	if path, err := jobs.DefaultPath(); err != nil {
+		log.Printf("Failed to locate job description library: %v", err)
+	} else {
+		guiApp.jobLibrary = jobs.NewLibrary(path)
+	}
+
 	// Setup UI
 	guiApp.setupUI()
 
@@ -103,6 +147,27 @@ func (a *App) setupUI() {
 
 // createProcessTab creates the main processing tab
 func (a *App) createProcessTab() fyne.CanvasObject {
+	// Document source section
+	a.sourceRadio = widget.NewRadioGroup([]string{sourceGmail, sourceArchive, sourceIMAP, sourceWatch}, nil)
+	a.sourceRadio.Horizontal = true
+	a.sourceRadio.SetSelected(sourceGmail)
+
+	a.archiveLabel = widget.NewLabel("No file selected")
+	archiveBtn := widget.NewButton("Select File", a.handleSelectArchive)
+
+	a.watchLabel = widget.NewLabel("No folder selected")
+	if a.config.WatchDir != "" {
+		a.watchLabel.SetText(a.config.WatchDir)
+	}
+	watchBtn := widget.NewButton("Select Folder", a.handleSelectWatchFolder)
+
+	sourceSection := container.NewVBox(
+		widget.NewLabel("Documents Source"),
+		a.sourceRadio,
+		container.NewHBox(a.archiveLabel, archiveBtn),
+		container.NewHBox(a.watchLabel, watchBtn),
+	)
+
 	// Gmail authentication section
 	a.gmailStatusLabel = widget.NewLabel("Gmail: Not Authenticated")
 	a.authenticateBtn = widget.NewButton("Authenticate Gmail", a.handleAuthenticate)
@@ -112,13 +177,66 @@ func (a *App) createProcessTab() fyne.CanvasObject {
 		container.NewHBox(a.gmailStatusLabel, a.authenticateBtn),
 	)
 
+	// IMAP mailbox section (Exchange, self-hosted mail)
+	a.imapHostEntry = widget.NewEntry()
+	a.imapHostEntry.SetPlaceHolder("imap.example.com or imap.example.com:993")
+	a.imapHostEntry.SetText(a.config.IMAPHost)
+	a.imapUserEntry = widget.NewEntry()
+	a.imapUserEntry.SetText(a.config.IMAPUsername)
+	a.imapPasswordEntry = widget.NewPasswordEntry()
+	a.imapPasswordEntry.SetPlaceHolder("Password, app password or OAuth access token")
+	a.imapAuthSelect = widget.NewSelect([]string{ingestion.IMAPAuthLogin, ingestion.IMAPAuthXOAuth2}, nil)
+	a.imapAuthSelect.SetSelected(ingestion.IMAPAuthLogin)
+	a.imapFolderEntry = widget.NewEntry()
+	a.imapFolderEntry.SetPlaceHolder("INBOX")
+	a.imapFolderEntry.SetText(a.config.IMAPFolder)
+
+	imapSection := container.NewVBox(
+		widget.NewLabel("IMAP Mailbox"),
+		widget.NewForm(
+			widget.NewFormItem("Server", a.imapHostEntry),
+			widget.NewFormItem("Username", a.imapUserEntry),
+			widget.NewFormItem("Password", a.imapPasswordEntry),
+			widget.NewFormItem("Authentication", a.imapAuthSelect),
+			widget.NewFormItem("Folder", a.imapFolderEntry),
+		),
+	)
+
 	// Email filter section
 	a.subjectEntry = widget.NewEntry()
 	a.subjectEntry.SetPlaceHolder("e.g., Job Application")
 
+	a.gmailFromEntry = widget.NewEntry()
+	a.gmailFromEntry.SetPlaceHolder("e.g., careers@jobboard.com")
+	a.gmailLabelEntry = widget.NewEntry()
+	a.gmailLabelEntry.SetPlaceHolder("e.g., Applications")
+	a.gmailAfterEntry = widget.NewEntry()
+	a.gmailAfterEntry.SetPlaceHolder("YYYY-MM-DD")
+	a.gmailBeforeEntry = widget.NewEntry()
+	a.gmailBeforeEntry.SetPlaceHolder("YYYY-MM-DD")
+	a.gmailExcludeEntry = widget.NewEntry()
+	a.gmailExcludeEntry.SetPlaceHolder("Comma-separated, e.g., newsletter, unsubscribe")
+	a.gmailQueryEntry = widget.NewEntry()
+	a.gmailQueryEntry.SetPlaceHolder("Raw Gmail search, e.g., filename:pdf")
+	a.gmailBodyCheck = widget.NewCheck("Use the email text as the cover letter", nil)
+	a.gmailLabelCheck = widget.NewCheck("Label emails CV-Review/Scored and CV-Review/Shortlisted (top 5)", nil)
+	a.gmailDraftCheck = widget.NewCheck("Create acknowledgment reply drafts", nil)
+
+	gmailFilters := widget.NewAccordion(widget.NewAccordionItem("More Gmail Filters", widget.NewForm(
+		widget.NewFormItem("From", a.gmailFromEntry),
+		widget.NewFormItem("Label", a.gmailLabelEntry),
+		widget.NewFormItem("Received after", a.gmailAfterEntry),
+		widget.NewFormItem("Received before", a.gmailBeforeEntry),
+		widget.NewFormItem("Exclude", a.gmailExcludeEntry),
+		widget.NewFormItem("Gmail query", a.gmailQueryEntry),
+		widget.NewFormItem("Email body", a.gmailBodyCheck),
+		widget.NewFormItem("After scoring", container.NewVBox(a.gmailLabelCheck, a.gmailDraftCheck)),
+	)))
+
 	filterSection := container.NewVBox(
 		widget.NewLabel("Email Subject Filter"),
 		a.subjectEntry,
+		gmailFilters,
 	)
 
 	// Job description section
@@ -153,8 +271,18 @@ func (a *App) createProcessTab() fyne.CanvasObject {
 	a.niceToHaveDutiesText.SetPlaceHolder("One per line, e.g.:\nMentor junior developers")
 	a.niceToHaveDutiesText.SetMinRowsVisible(2)
 
+	a.jobLibraryLabel = widget.NewLabel("Not saved in the library")
+	libraryRow := container.NewHBox(
+		widget.NewButton("Import Job Ad...", a.handleImportJobAd),
+		widget.NewButton("Load from Library...", a.handleLoadJobDescription),
+		widget.NewButton("Save to Library", a.handleSaveJobDescription),
+		widget.NewButton("New", a.handleNewJobDescription),
+		a.jobLibraryLabel,
+	)
+
 	jobSection := container.NewVBox(
 		widget.NewLabel("Job Description"),
+		libraryRow,
 		widget.NewForm(
 			widget.NewFormItem("Job Title", a.jobTitleEntry),
 			widget.NewFormItem("Description", a.jobDescText),
@@ -235,8 +363,12 @@ func (a *App) createProcessTab() fyne.CanvasObject {
 	// Main layout with scrolling
 	content := container.NewVScroll(
 		container.NewVBox(
+			sourceSection,
+			widget.NewSeparator(),
 			authSection,
 			widget.NewSeparator(),
+			imapSection,
+			widget.NewSeparator(),
 			filterSection,
 			widget.NewSeparator(),
 			jobSection,
@@ -335,13 +467,16 @@ func (a *App) handleAuthenticate() {
 
 	// Show loading dialog
 	progressDialog := dialog.NewCustomWithoutButtons("Authenticating",
-		widget.NewLabel("Authenticating with Gmail...\nCheck the console for the OAuth URL if your browser doesn't open."),
+		widget.NewLabel("Authenticating with Gmail...\nComplete the sign-in in your browser. Check the console for the link if it doesn't open."),
 		a.mainWindow)
 	progressDialog.Show()
 
 	// Disable authenticate button
 	a.authenticateBtn.Disable()
 
+	// Labels and reply drafts need more than read access
+	followUp := a.gmailLabelCheck.Checked || a.gmailDraftCheck.Checked
+
 	// Run authentication in background
 	go func() {
 		// Handle credentials path - Gmail handler expects credentials.json in current directory
@@ -402,14 +537,14 @@ func (a *App) handleAuthenticate() {
 			}
 		}()
 
-		// Try to create Gmail handler (this will trigger OAuth flow if token.json doesn't exist)
-		// The handler creation verifies that authentication works
-		uploadsDir := a.config.UploadsDir
-		if uploadsDir == "" {
-			uploadsDir = "uploads"
-		}
-
-		_, err := ingestion.NewGmailHandlerWithCallback(uploadsDir, nil)
+		// Open the consent page in the browser; Google redirects back to a local listener
+		account, err := ingestion.AuthorizeGmailLocal(context.Background(), ingestion.GmailScopes(followUp), func(link string) error {
+			u, err := url.Parse(link)
+			if err != nil {
+				return err
+			}
+			return fyne.CurrentApp().OpenURL(u)
+		})
 
 		// All UI updates must be done on the main thread using fyne.Do
 		if err != nil {
@@ -425,7 +560,7 @@ func (a *App) handleAuthenticate() {
 		fyne.Do(func() {
 			progressDialog.Hide()
 			a.authenticateBtn.Enable()
-			a.gmailStatusLabel.SetText("Gmail: Authenticated")
+			a.gmailStatusLabel.SetText("Gmail: Authenticated as " + account)
 			dialog.ShowInformation("Success", "Gmail authenticated successfully!\nYou can now process CVs from Gmail.", a.mainWindow)
 		})
 	}()
@@ -433,8 +568,34 @@ func (a *App) handleAuthenticate() {
 
 // handleProcess handles the processing of CVs
 func (a *App) handleProcess() {
+	source := a.sourceRadio.Selected
+
 	// Validate inputs
-	if a.subjectEntry.Text == "" {
+	if source == sourceArchive && a.archivePath == "" {
+		dialog.ShowError(fmt.Errorf("please select a ZIP archive or email export"), a.mainWindow)
+		return
+	}
+	if source == sourceWatch && a.config.WatchDir == "" {
+		dialog.ShowError(fmt.Errorf("please select a folder to watch"), a.mainWindow)
+		return
+	}
+
+	var imapConfig ingestion.IMAPConfig
+	if source == sourceIMAP {
+		var err error
+		if imapConfig, err = a.imapConfig(); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+	}
+
+	gmailFilter := a.gmailFilter()
+	if source == sourceGmail {
+		if _, err := ingestion.GmailQuery(gmailFilter); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+	} else if source == sourceIMAP && a.subjectEntry.Text == "" {
 		dialog.ShowError(fmt.Errorf("please enter an email subject filter"), a.mainWindow)
 		return
 	}
@@ -444,16 +605,13 @@ func (a *App) handleProcess() {
 		return
 	}
 
-	// Build job description
-	jobDesc := models.JobDescription{
-		Title:                a.jobTitleEntry.Text,
-		Description:          a.jobDescText.Text,
-		RequiredExperience:   splitLines(a.requiredExpText.Text),
-		RequiredEducation:    splitLines(a.requiredEduText.Text),
-		RequiredDuties:       splitLines(a.requiredDutiesText.Text),
-		NiceToHaveExperience: splitLines(a.niceToHaveExpText.Text),
-		NiceToHaveEducation:  splitLines(a.niceToHaveEduText.Text),
-		NiceToHaveDuties:     splitLines(a.niceToHaveDutiesText.Text),
+	// Build job description; results are filed under the library ID when the
+	// form matches the saved version
+	jobDesc := a.jobDescriptionFromForm()
+	if a.jobDescID != "" && a.jobLibrary != nil {
+		if stored, err := a.jobLibrary.Get(a.jobDescID, 0); err == nil && sameJobDescription(stored, jobDesc) {
+			jobDesc = stored
+		}
 	}
 
 	jobDescJSON, err := json.Marshal(jobDesc)
@@ -478,9 +636,40 @@ func (a *App) handleProcess() {
 		})
 	})
 
+	// Gmail labels and replies requested for after scoring
+	followUp := a.gmailFollowUp()
+
 	// Process in background
 	go func() {
-		err := a.agent.IngestFromGmailWithContext(a.ctx, a.subjectEntry.Text, string(jobDescJSON))
+		var err, followUpErr error
+		switch source {
+		case sourceArchive:
+			err = a.ingestArchive(a.ctx, a.archivePath, string(jobDescJSON))
+		case sourceIMAP:
+			err = a.agent.IngestFromIMAPWithContext(a.ctx, imapConfig, string(jobDescJSON))
+		case sourceWatch:
+			// Runs until canceled, refreshing the ranking as applications land
+			err = a.agent.WatchFolderWithContext(a.ctx, a.config.WatchDir, string(jobDescJSON), func(results []models.ApplicantResult) {
+				fyne.Do(func() {
+					a.results = results
+					a.resultsJobDesc = jobDesc
+					a.resultsTable.Refresh()
+					a.exportBtn.Enable()
+					a.progressLabel.SetText(fmt.Sprintf("Watching folder - %d candidates ranked (last update %s)",
+						len(results), time.Now().Format("15:04:05")))
+				})
+			})
+		default:
+			err = a.agent.IngestFromGmailWithContext(a.ctx, gmailFilter, string(jobDescJSON))
+			if err == nil && followUp != nil {
+				fyne.Do(func() {
+					a.progressLabel.SetText("Updating Gmail...")
+				})
+				if _, ferr := a.agent.FollowUpGmailWithContext(a.ctx, *followUp); ferr != nil {
+					followUpErr = fmt.Errorf("documents were scored but the Gmail follow-up failed: %w", ferr)
+				}
+			}
+		}
 
 		// Wrap ALL UI updates in fyne.Do()
 		fyne.Do(func() {
@@ -488,7 +677,9 @@ func (a *App) handleProcess() {
 			a.cancelBtn.Disable()
 
 			if err != nil {
-				if err == context.Canceled {
+				if err == context.Canceled && source == sourceWatch {
+					a.progressLabel.SetText(fmt.Sprintf("Stopped watching folder - %d candidates ranked", len(a.results)))
+				} else if err == context.Canceled {
 					a.progressLabel.SetText("Processing canceled")
 				} else {
 					a.progressLabel.SetText("Error: " + err.Error())
@@ -499,10 +690,14 @@ func (a *App) handleProcess() {
 
 			// Get results and update UI
 			a.results = a.agent.GetResults()
+			a.resultsJobDesc = a.agent.GetJobDescription()
 			a.resultsTable.Refresh()
 			a.exportBtn.Enable()
 
 			a.progressLabel.SetText(fmt.Sprintf("Complete! Processed %d candidates", len(a.results)))
+			if followUpErr != nil {
+				dialog.ShowError(followUpErr, a.mainWindow)
+			}
 
 			fyne.CurrentApp().SendNotification(&fyne.Notification{
 				Title:   "Processing Complete",
@@ -512,6 +707,319 @@ func (a *App) handleProcess() {
 	}()
 }
 
+// gmailFollowUp returns the Gmail labels and reply drafts the user asked for, or nil
+func (a *App) gmailFollowUp() *models.GmailFollowUp {
+	var followUp models.GmailFollowUp
+	if a.gmailLabelCheck.Checked {
+		followUp.Labels = ingestion.DefaultGmailLabelRules()
+	}
+	if a.gmailDraftCheck.Checked {
+		followUp.Reply = models.GmailReplyDraft
+	}
+	if len(followUp.Labels) == 0 && followUp.Reply == "" {
+		return nil
+	}
+	return &followUp
+}
+
+// gmailFilter builds the Gmail search from the subject and the additional filters
+func (a *App) gmailFilter() models.GmailFilter {
+	filter := models.GmailFilter{
+		Subject: a.subjectEntry.Text,
+		From:    a.gmailFromEntry.Text,
+		Label:   a.gmailLabelEntry.Text,
+		After:   strings.TrimSpace(a.gmailAfterEntry.Text),
+		Before:  strings.TrimSpace(a.gmailBeforeEntry.Text),
+		Query:   a.gmailQueryEntry.Text,
+
+		IncludeBody: a.gmailBodyCheck.Checked,
+	}
+	for _, term := range strings.Split(a.gmailExcludeEntry.Text, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			filter.Exclude = append(filter.Exclude, term)
+		}
+	}
+	return filter
+}
+
+// imapConfig builds the IMAP settings from the form and remembers the mailbox (not the password)
+func (a *App) imapConfig() (ingestion.IMAPConfig, error) {
+	cfg := ingestion.IMAPConfig{
+		Host:     strings.TrimSpace(a.imapHostEntry.Text),
+		Username: strings.TrimSpace(a.imapUserEntry.Text),
+		Password: a.imapPasswordEntry.Text,
+		Auth:     a.imapAuthSelect.Selected,
+		Folder:   strings.TrimSpace(a.imapFolderEntry.Text),
+		Subject:  a.subjectEntry.Text,
+	}
+	if host, port, err := net.SplitHostPort(cfg.Host); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid IMAP port: %s", port)
+		}
+		cfg.Host, cfg.Port = host, n
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	a.config.IMAPHost = a.imapHostEntry.Text
+	a.config.IMAPUsername = cfg.Username
+	a.config.IMAPFolder = cfg.Folder
+	if err := a.config.Save(); err != nil {
+		log.Printf("Failed to save IMAP settings: %v", err)
+	}
+	return cfg, nil
+}
+
+// jobDescriptionFromForm builds a job description from the form fields
+func (a *App) jobDescriptionFromForm() models.JobDescription {
+	return models.JobDescription{
+		Title:                a.jobTitleEntry.Text,
+		Description:          a.jobDescText.Text,
+		RequiredExperience:   splitLines(a.requiredExpText.Text),
+		RequiredEducation:    splitLines(a.requiredEduText.Text),
+		RequiredDuties:       splitLines(a.requiredDutiesText.Text),
+		NiceToHaveExperience: splitLines(a.niceToHaveExpText.Text),
+		NiceToHaveEducation:  splitLines(a.niceToHaveEduText.Text),
+		NiceToHaveDuties:     splitLines(a.niceToHaveDutiesText.Text),
+	}
+}
+
+// setJobDescription fills the form with a job description
+func (a *App) setJobDescription(jobDesc models.JobDescription) {
+	a.jobTitleEntry.SetText(jobDesc.Title)
+	a.jobDescText.SetText(jobDesc.Description)
+	a.requiredExpText.SetText(strings.Join(jobDesc.RequiredExperience, "\n"))
+	a.requiredEduText.SetText(strings.Join(jobDesc.RequiredEducation, "\n"))
+	a.requiredDutiesText.SetText(strings.Join(jobDesc.RequiredDuties, "\n"))
+	a.niceToHaveExpText.SetText(strings.Join(jobDesc.NiceToHaveExperience, "\n"))
+	a.niceToHaveEduText.SetText(strings.Join(jobDesc.NiceToHaveEducation, "\n"))
+	a.niceToHaveDutiesText.SetText(strings.Join(jobDesc.NiceToHaveDuties, "\n"))
+
+	a.jobDescID = jobDesc.ID
+	if jobDesc.ID == "" {
+		a.jobLibraryLabel.SetText("Not saved in the library")
+	} else {
+		a.jobLibraryLabel.SetText(fmt.Sprintf("Library: %s (version %d)", jobDesc.ID, jobDesc.Version))
+	}
+}
+
+// handleLoadJobDescription lets the user pick a job description from the library
+func (a *App) handleLoadJobDescription() {
+	if a.jobLibrary == nil {
+		dialog.ShowError(fmt.Errorf("the job description library is not available"), a.mainWindow)
+		return
+	}
+	summaries, err := a.jobLibrary.List()
+	if err != nil {
+		dialog.ShowError(err, a.mainWindow)
+		return
+	}
+	if len(summaries) == 0 {
+		dialog.ShowInformation("Job Library", "No job descriptions have been saved yet", a.mainWindow)
+		return
+	}
+
+	options := make([]string, len(summaries))
+	for i, summary := range summaries {
+		options[i] = fmt.Sprintf("%s (v%d, %s)", summary.Title, summary.Version, summary.UpdatedAt.Local().Format("2006-01-02"))
+	}
+	selected := -1
+	list := widget.NewSelect(options, func(value string) {
+		for i, option := range options {
+			if option == value {
+				selected = i
+			}
+		}
+	})
+
+	dialog.ShowCustomConfirm("Load Job Description", "Load", "Cancel", list, func(ok bool) {
+		if !ok || selected < 0 {
+			return
+		}
+		jobDesc, err := a.jobLibrary.Get(summaries[selected].ID, 0)
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.setJobDescription(jobDesc)
+	}, a.mainWindow)
+}
+
+// handleSaveJobDescription saves the form as a new job description, or as a
+// new version of the loaded one
+func (a *App) handleSaveJobDescription() {
+	if a.jobLibrary == nil {
+		dialog.ShowError(fmt.Errorf("the job description library is not available"), a.mainWindow)
+		return
+	}
+
+	jobDesc := a.jobDescriptionFromForm()
+	var saved models.JobDescription
+	var err error
+	if a.jobDescID != "" {
+		saved, err = a.jobLibrary.Update(a.jobDescID, jobDesc)
+	} else {
+		saved, err = a.jobLibrary.Create(jobDesc)
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf("failed to save job description: %w", err), a.mainWindow)
+		return
+	}
+
+	a.jobDescID = saved.ID
+	a.jobLibraryLabel.SetText(fmt.Sprintf("Library: %s (version %d)", saved.ID, saved.Version))
+}
+
+// handleImportJobAd drafts the job description form from a pasted job ad or a
+// job posting file, for the user to review and edit before scoring
+func (a *App) handleImportJobAd() {
+	adText := widget.NewMultiLineEntry()
+	adText.SetPlaceHolder("Paste the job ad here, or choose a file")
+	adText.SetMinRowsVisible(12)
+
+	postingPath := ""
+	fileLabel := widget.NewLabel("No file selected")
+	chooseBtn := widget.NewButton("Choose File...", func() {
+		fileDialog := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+			if err != nil || uc == nil {
+				return
+			}
+			defer uc.Close()
+			postingPath = uc.URI().Path()
+			fileLabel.SetText(filepath.Base(postingPath))
+		}, a.mainWindow)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".txt", ".pdf", ".docx", ".doc", ".odt", ".rtf", ".html", ".htm", ".md"}))
+		fileDialog.Show()
+	})
+
+	content := container.NewBorder(nil, container.NewHBox(chooseBtn, fileLabel), nil, nil, adText)
+	importDialog := dialog.NewCustomConfirm("Import Job Ad", "Import", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		text := adText.Text
+		if strings.TrimSpace(text) == "" && postingPath == "" {
+			dialog.ShowError(fmt.Errorf("please paste a job ad or choose a file"), a.mainWindow)
+			return
+		}
+
+		a.progressLabel.SetText("Reading the job ad...")
+		go func() {
+			jobDesc, err := parseJobAd(context.Background(), text, postingPath)
+			fyne.Do(func() {
+				if err != nil {
+					a.progressLabel.SetText("Ready")
+					dialog.ShowError(fmt.Errorf("failed to import job ad: %w", err), a.mainWindow)
+					return
+				}
+				a.setJobDescription(jobDesc)
+				a.progressLabel.SetText("Job ad imported - review the job description before processing")
+				if err := jobDesc.Validate(); err != nil {
+					dialog.ShowInformation("Review Job Description", "Some fields need attention:\n"+err.Error(), a.mainWindow)
+				}
+			})
+		}()
+	}, a.mainWindow)
+	importDialog.Resize(fyne.NewSize(700, 500))
+	importDialog.Show()
+}
+
+// parseJobAd drafts a job description from pasted text, or from a file when no text is given
+func parseJobAd(ctx context.Context, text, path string) (models.JobDescription, error) {
+	if strings.TrimSpace(text) == "" {
+		var err error
+		if text, err = jobs.PostingText(path); err != nil {
+			return models.JobDescription{}, err
+		}
+	}
+
+	llmClient, err := llm.NewVertexAIClient()
+	if err != nil {
+		return models.JobDescription{}, fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	defer llmClient.Close()
+
+	return jobs.NewParser(llmClient).ParseText(ctx, text)
+}
+
+// handleNewJobDescription clears the form so the next save creates a new job description
+func (a *App) handleNewJobDescription() {
+	a.setJobDescription(models.JobDescription{})
+}
+
+// sameJobDescription reports whether two job descriptions have the same content
+func sameJobDescription(x, y models.JobDescription) bool {
+	lists := func(jd models.JobDescription) string {
+		return strings.Join([]string{
+			strings.Join(jd.RequiredExperience, "\n"),
+			strings.Join(jd.RequiredEducation, "\n"),
+			strings.Join(jd.RequiredDuties, "\n"),
+			strings.Join(jd.NiceToHaveExperience, "\n"),
+			strings.Join(jd.NiceToHaveEducation, "\n"),
+			strings.Join(jd.NiceToHaveDuties, "\n"),
+		}, "\x00")
+	}
+	return x.Title == y.Title && x.Description == y.Description && lists(x) == lists(y)
+}
+
+// handleSelectArchive lets the user pick a ZIP bundle or an .eml/.mbox email export
+func (a *App) handleSelectArchive() {
+	fileDialog := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+		if err != nil || uc == nil {
+			return
+		}
+		defer uc.Close()
+
+		a.archivePath = uc.URI().Path()
+		a.archiveLabel.SetText(filepath.Base(a.archivePath))
+		a.sourceRadio.SetSelected(sourceArchive)
+	}, a.mainWindow)
+	fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip", ".eml", ".mbox"}))
+	fileDialog.Show()
+}
+
+// handleSelectWatchFolder lets the user pick the folder to watch for new applications
+func (a *App) handleSelectWatchFolder() {
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil || uri == nil {
+			return
+		}
+
+		a.config.WatchDir = uri.Path()
+		a.watchLabel.SetText(a.config.WatchDir)
+		a.sourceRadio.SetSelected(sourceWatch)
+		if err := a.config.Save(); err != nil {
+			log.Printf("Failed to save watch folder: %v", err)
+		}
+	}, a.mainWindow)
+}
+
+// ingestArchive replaces the uploads with the documents of a ZIP archive or the
+// attachments of an email export and evaluates them
+func (a *App) ingestArchive(ctx context.Context, archivePath, jobDescJSON string) error {
+	if err := a.agent.FileHandler.ClearUploads(); err != nil {
+		return fmt.Errorf("failed to clear uploads: %w", err)
+	}
+
+	if ingestion.IsMailFile(archivePath) {
+		saved, err := a.agent.FileHandler.ImportMailFile(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to import email export: %w", err)
+		}
+		log.Printf("Imported %d attachment(s) from %s", saved, filepath.Base(archivePath))
+	} else {
+		saved, err := a.agent.FileHandler.ExtractArchive(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to extract archive: %w", err)
+		}
+		log.Printf("Extracted %d file(s) from %s", len(saved), filepath.Base(archivePath))
+	}
+
+	return a.agent.IngestFromUploadWithContext(ctx, jobDescJSON)
+}
+
 // handleCancel handles cancellation of processing
 func (a *App) handleCancel() {
 	if a.cancelFunc != nil {
@@ -547,8 +1055,7 @@ func (a *App) handleExport() {
 		outputPath := uc.URI().Path()
 
 		// Export to Excel
-		jobDesc := a.agent.GetJobDescription()
-		if err := export.ExportToExcel(a.results, jobDesc, outputPath); err != nil {
+		if err := export.ExportToExcel(a.results, a.resultsJobDesc, outputPath); err != nil {
 			dialog.ShowError(fmt.Errorf("failed to export: %w", err), a.mainWindow)
 			return
 		}
@@ -0,0 +1,248 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxArchiveSize is the largest ZIP file accepted for bulk upload
+	MaxArchiveSize = 200 << 20
+	// MaxArchiveEntries is the most files a ZIP may contain
+	MaxArchiveEntries = 1000
+	// MaxArchiveEntrySize is the largest uncompressed document accepted from a ZIP
+	MaxArchiveEntrySize = 25 << 20
+	// MaxArchiveTotalSize is the most uncompressed data extracted from one ZIP
+	MaxArchiveTotalSize = 500 << 20
+	// maxCompressionRatio rejects entries that expand suspiciously (zip bombs)
+	maxCompressionRatio = 100
+)
+
+// errEntryRejected is returned for an archive entry whose content does not
+// match its extension; the entry is skipped rather than the archive rejected
+var errEntryRejected = errors.New("rejected")
+
+// IsArchiveFile reports whether filename is a bulk-upload archive (.zip)
+func IsArchiveFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".zip")
+}
+
+// SaveUploadedArchive stores an uploaded ZIP in a temporary file and extracts
+// its documents into the uploads directory (see ExtractArchive)
+func (fh *FileHandler) SaveUploadedArchive(content io.Reader) ([]string, error) {
+	tmp, err := os.CreateTemp("", "cv-upload-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, io.LimitReader(content, MaxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary archive: %w", err)
+	}
+	if n > MaxArchiveSize {
+		return nil, fmt.Errorf("archive exceeds maximum size of %d MB", MaxArchiveSize>>20)
+	}
+
+	return fh.ExtractArchive(tmp.Name())
+}
+
+// ExtractArchive unpacks the supported documents of a ZIP archive into the
+// uploads directory and returns the saved paths. Files in folders are saved in
+// an applicant folder named after the innermost one ("export/Jane Smith/cv.pdf"
+// -> "Jane Smith/cv.pdf"), which LoadDocuments groups by. Names are sanitized and
+// content is checked as for single uploads; entries that fail the check are
+// skipped. Entries that escape the archive root or exceed the size limits
+// reject the whole archive.
+func (fh *FileHandler) ExtractArchive(archivePath string) ([]string, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	if info.Size() > MaxArchiveSize {
+		return nil, fmt.Errorf("archive exceeds maximum size of %d MB", MaxArchiveSize>>20)
+	}
+
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer r.Close()
+
+	if len(r.File) > MaxArchiveEntries {
+		return nil, fmt.Errorf("archive contains %d entries, maximum is %d", len(r.File), MaxArchiveEntries)
+	}
+
+	// Validate every entry before writing anything
+	for _, f := range r.File {
+		if _, err := archiveEntryPath(f.Name); err != nil {
+			return nil, err
+		}
+		if f.UncompressedSize64 > MaxArchiveEntrySize {
+			return nil, fmt.Errorf("archive entry %s exceeds maximum size of %d MB", f.Name, MaxArchiveEntrySize>>20)
+		}
+	}
+
+	if err := os.MkdirAll(fh.uploadsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create uploads directory: %w", err)
+	}
+
+	var saved, created []string
+	var total int64
+	for _, f := range r.File {
+		entryPath, _ := archiveEntryPath(f.Name)
+		if f.FileInfo().IsDir() || skipArchiveEntry(entryPath) {
+			continue
+		}
+		if !IsSupportedFile(entryPath) {
+			log.Printf("Skipping unsupported file in archive: %s", entryPath)
+			continue
+		}
+
+		folder, name, err := archiveTargetPath(entryPath)
+		if err != nil {
+			log.Printf("Skipping file in archive: %s: %v", entryPath, err)
+			continue
+		}
+
+		dir := fh.uploadsDir
+		if folder != "" {
+			dir = filepath.Join(fh.uploadsDir, folder)
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				created = append(created, dir)
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				removeExtracted(saved, created)
+				return nil, fmt.Errorf("failed to create applicant folder: %w", err)
+			}
+		}
+
+		filePath := uniqueFilePath(dir, name)
+		n, err := extractArchiveEntry(f, filePath, MaxArchiveTotalSize-total)
+		if errors.Is(err, errEntryRejected) {
+			log.Printf("Skipping file in archive: %v", err)
+			continue
+		}
+		if err != nil {
+			removeExtracted(saved, created)
+			return nil, err
+		}
+		total += n
+		saved = append(saved, filePath)
+		log.Printf("Extracted %s -> %s", entryPath, filepath.Join(folder, filepath.Base(filePath)))
+	}
+
+	return saved, nil
+}
+
+// removeExtracted deletes the files and the applicant folders an archive
+// already wrote, so a rejected archive leaves no partial upload
+func removeExtracted(saved, created []string) {
+	for _, p := range saved {
+		os.Remove(p)
+	}
+	for i := len(created) - 1; i >= 0; i-- {
+		os.Remove(created[i]) // only removes folders that are empty again
+	}
+}
+
+// archiveEntryPath cleans a ZIP entry name and rejects absolute paths and
+// parent-directory references (zip-slip)
+func archiveEntryPath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	cleaned := path.Clean(name)
+	if path.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("archive entry escapes extraction directory: %s", name)
+	}
+	return cleaned, nil
+}
+
+// skipArchiveEntry reports whether an entry is OS metadata rather than an applicant document
+func skipArchiveEntry(entryPath string) bool {
+	for _, part := range strings.Split(entryPath, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveTargetPath returns the sanitized applicant folder and file name of an
+// entry: "export/Jane Smith/cv.pdf" is saved as "Jane Smith/cv.pdf", since the
+// innermost folder names the applicant. Entries at the archive root have no folder.
+func archiveTargetPath(entryPath string) (string, string, error) {
+	dir, base := path.Split(entryPath)
+	name, err := SanitizeUploadName(base)
+	if err != nil {
+		return "", "", err
+	}
+	if dir == "" {
+		return "", name, nil
+	}
+	folder, err := SanitizeUploadName(path.Base(strings.TrimSuffix(dir, "/")))
+	if err != nil {
+		return "", name, nil
+	}
+	return folder, name, nil
+}
+
+// sanitizeFilename replaces path separators, characters Windows does not allow
+// in file names and control characters
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+// extractArchiveEntry copies one ZIP entry to filePath after checking that its
+// content matches the extension, enforcing the per-entry size limit, the
+// remaining archive budget and the compression ratio
+func extractArchiveEntry(f *zip.File, filePath string, budget int64) (int64, error) {
+	if f.CompressedSize64 > 0 && f.UncompressedSize64 > 1<<20 && f.UncompressedSize64/f.CompressedSize64 > maxCompressionRatio {
+		return 0, fmt.Errorf("archive entry %s has a suspicious compression ratio", f.Name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read archive entry %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	content := bufio.NewReaderSize(rc, UploadSniffLength)
+	head, _ := content.Peek(UploadSniffLength)
+	if err := CheckUploadContent(filePath, head); err != nil {
+		return 0, fmt.Errorf("%s %w: %v", f.Name, errEntryRejected, err)
+	}
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	// Header sizes can lie; count the bytes actually decompressed
+	limit := min(MaxArchiveEntrySize, int(budget))
+	n, err := io.Copy(out, io.LimitReader(content, int64(limit)+1))
+	out.Close()
+	if err == nil && n > int64(limit) {
+		err = fmt.Errorf("archive entry %s exceeds the extraction size limit", f.Name)
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return 0, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,206 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeTestZip creates a ZIP archive with the given entries (name -> content)
+func writeTestZip(t *testing.T, entries map[string]string) string {
+	t.Helper()
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(entries[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write ZIP: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "applications.zip")
+	os.WriteFile(path, buf.Bytes(), 0644)
+	return path
+}
+
+// relativePaths returns saved paths relative to dir in order, with forward slashes
+func relativePaths(t *testing.T, dir string, saved []string) []string {
+	t.Helper()
+	var names []string
+	for _, p := range saved {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			t.Fatalf("Saved path %s is outside %s", p, dir)
+		}
+		names = append(names, filepath.ToSlash(rel))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestExtractArchive_ApplicantFolders tests that archive folders become applicant folders
+func TestExtractArchive_ApplicantFolders(t *testing.T) {
+	archive := writeTestZip(t, map[string]string{
+		"export/Jane Smith/cv.txt":     testCV("Jane Smith", "jane@example.com", testExperience),
+		"export/Jane Smith/letter.txt": testCoverLetterText,
+		"JohnDoe/JohnDoe_CV.txt":       testCV("John Doe", "john@example.com", "Northwind, Analyst, 2015 - 2020"),
+		"__MACOSX/export/._cv.txt":     "resource fork",
+		"export/.DS_Store":             "metadata",
+		"export/notes.exe":             "binary",
+	})
+
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+	saved, err := fh.ExtractArchive(archive)
+	if err != nil {
+		t.Fatalf("ExtractArchive() returned error: %v", err)
+	}
+
+	names := relativePaths(t, uploadsDir, saved)
+	expected := []string{"Jane Smith/cv.txt", "Jane Smith/letter.txt", "JohnDoe/JohnDoe_CV.txt"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 applicants, got %d", len(docs))
+	}
+	for _, doc := range docs {
+		if doc.Name == "Jane Smith" && doc.CoverLetter().Content == "" {
+			t.Errorf("Jane Smith's letter should be grouped with her CV")
+		}
+	}
+}
+
+// TestExtractArchive_LowercaseFolders tests that applicants are told apart by
+// their folders even when the names in them would collapse to the same name
+func TestExtractArchive_LowercaseFolders(t *testing.T) {
+	archive := writeTestZip(t, map[string]string{
+		"john smith/cv.txt": "John Smith\nFive years of Go",
+		"john doe/cv.txt":   "John Doe\nThree years of Python",
+	})
+
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+	if _, err := fh.ExtractArchive(archive); err != nil {
+		t.Fatalf("ExtractArchive() returned error: %v", err)
+	}
+
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+	var names []string
+	for _, doc := range docs {
+		names = append(names, doc.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "john doe,john smith" {
+		t.Errorf("Expected applicants john doe and john smith, got %v", names)
+	}
+	if unprocessed := fh.UnprocessedFiles(); len(unprocessed) != 0 {
+		t.Errorf("Expected no unprocessed files, got %+v", unprocessed)
+	}
+}
+
+// TestExtractArchive_RejectsZipSlip tests that entries escaping the uploads directory reject the archive
+func TestExtractArchive_RejectsZipSlip(t *testing.T) {
+	tests := []string{"../evil.txt", "docs/../../evil.txt", "/etc/evil.txt", "..\\evil.txt"}
+
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive := writeTestZip(t, map[string]string{
+				"JaneSmith_CV.txt": "Jane Smith CV",
+				name:               "escaped",
+			})
+
+			uploadsDir := filepath.Join(t.TempDir(), "uploads")
+			fh := NewFileHandler(uploadsDir)
+			if _, err := fh.ExtractArchive(archive); err == nil || !strings.Contains(err.Error(), "escapes") {
+				t.Errorf("Expected zip-slip error, got %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(uploadsDir), "evil.txt")); !os.IsNotExist(err) {
+				t.Errorf("File was written outside the uploads directory")
+			}
+			if entries, _ := os.ReadDir(uploadsDir); len(entries) != 0 {
+				t.Errorf("Rejected archive should not leave files behind, got %d", len(entries))
+			}
+		})
+	}
+}
+
+// TestExtractArchive_RejectsZipBomb tests that highly compressible oversized entries are refused
+func TestExtractArchive_RejectsZipBomb(t *testing.T) {
+	archive := writeTestZip(t, map[string]string{
+		"JaneSmith_CV.txt": strings.Repeat("A", 5<<20),
+	})
+
+	fh := NewFileHandler(t.TempDir())
+	if _, err := fh.ExtractArchive(archive); err == nil || !strings.Contains(err.Error(), "compression ratio") {
+		t.Errorf("Expected compression ratio error, got %v", err)
+	}
+}
+
+// TestExtractArchive_ChecksEntries tests that entries are sanitized and that
+// entries whose content does not match the extension are skipped
+func TestExtractArchive_ChecksEntries(t *testing.T) {
+	longName := strings.Repeat("a", 300) + ".txt"
+	archive := writeTestZip(t, map[string]string{
+		"JaneSmith_CV.txt":    "Jane Smith CV",
+		"JohnDoe_CV.pdf":      "MZ\x90\x00\x03\x00\x00\x00 executable renamed to .pdf",
+		"JohnDoe_Letter.docx": "Dear Hiring Manager",
+		"Ann Lee/" + longName: "Ann Lee CV",
+	})
+
+	uploadsDir := t.TempDir()
+	saved, err := NewFileHandler(uploadsDir).ExtractArchive(archive)
+	if err != nil {
+		t.Fatalf("ExtractArchive() returned error: %v", err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("Expected 2 saved files, got %v", saved)
+	}
+	for _, p := range saved {
+		if name := filepath.Base(p); len(name) > maxUploadNameLength || strings.HasPrefix(name, "JohnDoe") {
+			t.Errorf("Unexpected saved file %s", name)
+		}
+	}
+	if entries, _ := os.ReadDir(uploadsDir); len(entries) != 2 {
+		t.Errorf("Skipped entries should not be written, got %d files", len(entries))
+	}
+}
+
+// TestSaveUploadedArchive tests extraction from an uploaded stream
+func TestSaveUploadedArchive(t *testing.T) {
+	archive := writeTestZip(t, map[string]string{"JaneSmith_CV.txt": "Jane Smith CV"})
+	data, _ := os.ReadFile(archive)
+
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+	saved, err := fh.SaveUploadedArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("SaveUploadedArchive() returned error: %v", err)
+	}
+	if len(saved) != 1 || filepath.Dir(saved[0]) != uploadsDir {
+		t.Errorf("Expected one file in uploads directory, got %v", saved)
+	}
+}
@@ -0,0 +1,221 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// DocumentType is the kind of applicant document detected by the classifier:
+// one of the models.Document* constants or DocumentTypeUnknown
+type DocumentType string
+
+// DocumentTypeUnknown is a document the classifier could not place
+const DocumentTypeUnknown DocumentType = "unknown"
+
+const (
+	// filenameHintWeight is the score a matching filename adds to a document type
+	filenameHintWeight = 3.0
+	// minClassificationScore is the score a document type needs before it is trusted
+	minClassificationScore = 3.0
+	// classifierSampleSize is how much leading text is inspected; long CVs need no more
+	classifierSampleSize = 20000
+)
+
+// Classification is the classifier's decision for a single document
+type Classification struct {
+	Type DocumentType
+	// Confidence is the winning type's share of all signal scores (0-1)
+	Confidence float64
+	// Reason summarizes the strongest signals, for logs and reports
+	Reason string
+}
+
+// classifierSignal is a phrase or pattern that suggests a document type
+type classifierSignal struct {
+	pattern *regexp.Regexp
+	weight  float64
+	label   string
+}
+
+// signal builds a case-insensitive, word-bounded classifier signal
+func signal(expr string, weight float64) classifierSignal {
+	return classifierSignal{
+		pattern: regexp.MustCompile(`(?i)\b(?:` + expr + `)\b`),
+		weight:  weight,
+		label:   strings.SplitN(expr, "|", 2)[0],
+	}
+}
+
+// contentSignals are the content cues for each document type; a signal counts
+// once per document no matter how often it appears
+var contentSignals = map[DocumentType][]classifierSignal{
+	models.DocumentCV: {
+		signal(`curriculum vitae|r[eé]sum[eé]`, 3),
+		signal(`work experience|professional experience|employment history|work history|career history`, 3),
+		signal(`education|academic background|qualifications`, 1.5),
+		signal(`skills|core competencies|technical skills`, 1.5),
+		signal(`references available|referees`, 1.5),
+		signal(`professional summary|career objective|personal profile|profile`, 1),
+		signal(`languages|certifications|achievements`, 1),
+	},
+	models.DocumentCoverLetter: {
+		signal(`dear (?:sir|madam|hiring manager|recruit\w*|mr|mrs|ms|dr)|to whom it may concern|dear`, 3),
+		signal(`yours (?:sincerely|faithfully)|sincerely|kind regards|best regards`, 2.5),
+		signal(`i am writing|i would like to apply|i wish to apply|i am applying`, 3),
+		signal(`cover letter|letter of application|motivation letter|letter of motivation`, 3),
+		signal(`thank you for (?:your )?(?:time|consideration)|i look forward to`, 2),
+		signal(`hiring manager|the position of|the role of|your organi[sz]ation`, 1),
+	},
+	models.DocumentCertificate: {
+		signal(`this is to certify|hereby certif(?:y|ies)|certify that`, 4),
+		signal(`certificate of (?:completion|achievement|attendance|participation)`, 4),
+		signal(`has successfully completed|is awarded|awarded to|presented to`, 3),
+		signal(`in recognition of`, 1.5),
+	},
+	models.DocumentReference: {
+		signal(`letter of (?:recommendation|reference)|(?:reference|recommendation|referee) letter|character reference`, 4),
+		signal(`(?:pleasure|pleased|delighted|happy|hesitation) (?:in |to )?recommend(?:ing)?|(?:highly|strongly|wholeheartedly) recommend|without reservation`, 4),
+		signal(`i have known|under my supervision|reported (?:directly )?to me|worked (?:with|for) me`, 3),
+		signal(`to whom it may concern`, 1),
+	},
+	models.DocumentTranscript: {
+		signal(`academic transcript|transcript of records|official transcript|transcript`, 4),
+		signal(`grade point average|cumulative gpa|gpa|cgpa`, 2.5),
+		signal(`course code|unit code|credit hours|credits earned`, 2.5),
+		signal(`semester|academic year`, 1),
+	},
+	models.DocumentPortfolio: {
+		signal(`portfolio`, 3),
+		signal(`case study|case studies|selected works|project gallery`, 3),
+		signal(`behance\.net|dribbble\.com`, 2),
+	},
+}
+
+// filenameHints map filename words to the document type they suggest
+var filenameHints = map[string]DocumentType{
+	"cv":             models.DocumentCV,
+	"resume":         models.DocumentCV,
+	"résumé":         models.DocumentCV,
+	"curriculum":     models.DocumentCV,
+	"vitae":          models.DocumentCV,
+	"cover":          models.DocumentCoverLetter,
+	"coverletter":    models.DocumentCoverLetter,
+	"letter":         models.DocumentCoverLetter,
+	"motivation":     models.DocumentCoverLetter,
+	"cl":             models.DocumentCoverLetter,
+	"certificate":    models.DocumentCertificate,
+	"certificates":   models.DocumentCertificate,
+	"cert":           models.DocumentCertificate,
+	"certification":  models.DocumentCertificate,
+	"reference":      models.DocumentReference,
+	"references":     models.DocumentReference,
+	"recommendation": models.DocumentReference,
+	"transcript":     models.DocumentTranscript,
+	"transcripts":    models.DocumentTranscript,
+	"grades":         models.DocumentTranscript,
+	"portfolio":      models.DocumentPortfolio,
+	"samples":        models.DocumentPortfolio,
+	"workingsamples": models.DocumentPortfolio,
+}
+
+// dateRangePattern matches employment-style date ranges such as "2018 - 2022" or "Jan 2020 – Present"
+var dateRangePattern = regexp.MustCompile(`(?i)\b(?:19|20)\d{2}\s*(?:-|–|—|to)\s*(?:(?:19|20)\d{2}|present|current|now|date)\b`)
+
+// ClassifyDocument decides whether a document is a CV, cover letter or supporting
+// document from its text, using words in the file name as a hint
+func ClassifyDocument(filename, text string) Classification {
+	if len(text) > classifierSampleSize {
+		text = text[:classifierSampleSize]
+	}
+
+	scores := make(map[DocumentType]float64)
+	reasons := make(map[DocumentType][]string)
+
+	for docType, signals := range contentSignals {
+		for _, s := range signals {
+			if s.pattern.MatchString(text) {
+				scores[docType] += s.weight
+				reasons[docType] = append(reasons[docType], "mentions \""+s.label+"\"")
+			}
+		}
+	}
+
+	// Several dated positions are the most reliable CV marker
+	if ranges := len(dateRangePattern.FindAllString(text, 10)); ranges >= 2 {
+		scores[models.DocumentCV] += float64(ranges)
+		reasons[models.DocumentCV] = append(reasons[models.DocumentCV], "lists dated positions")
+	}
+
+	if hint := filenameHint(filename); hint != DocumentTypeUnknown {
+		scores[hint] += filenameHintWeight
+		reasons[hint] = append(reasons[hint], "file name")
+	}
+
+	best := DocumentTypeUnknown
+	var bestScore, total float64
+	for _, docType := range []DocumentType{models.DocumentCV, models.DocumentCoverLetter, models.DocumentCertificate, models.DocumentReference, models.DocumentTranscript, models.DocumentPortfolio} {
+		total += scores[docType]
+		if scores[docType] > bestScore {
+			best, bestScore = docType, scores[docType]
+		}
+	}
+
+	if bestScore < minClassificationScore {
+		return Classification{Type: DocumentTypeUnknown, Reason: "no clear CV, cover letter or supporting document signals"}
+	}
+
+	return Classification{
+		Type:       best,
+		Confidence: bestScore / total,
+		Reason:     strings.Join(reasons[best], ", "),
+	}
+}
+
+// filenameHint returns the document type suggested by words in a file name
+func filenameHint(filename string) DocumentType {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	parts := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+
+	// Whole words first ("Cover_Letter", "jane-smith-resume"), then camel-case parts ("JaneSmithCV")
+	var words []string
+	for _, part := range parts {
+		words = append(words, strings.ToLower(part))
+	}
+	for _, part := range parts {
+		for _, w := range splitCamelCase(part) {
+			words = append(words, strings.ToLower(w))
+		}
+	}
+
+	for _, word := range words {
+		if docType, ok := filenameHints[word]; ok {
+			return docType
+		}
+	}
+	return DocumentTypeUnknown
+}
+
+// splitCamelCase splits "JaneSmithCV" into "Jane", "Smith", "CV" and "CVJane2024" into "CV", "Jane", "2024"
+func splitCamelCase(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsDigit(prev) != unicode.IsDigit(cur) ||
+			// End of an acronym: "CVJane" splits before the "J"
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
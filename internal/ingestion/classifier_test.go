@@ -0,0 +1,151 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+const (
+	testCVText = `Jane Smith
+Professional Summary
+Senior software engineer.
+
+Work Experience
+Acme Corp, Lead Developer, 2018 - Present
+Globex, Developer, 2014 - 2018
+
+Education
+BSc Computer Science
+
+Skills
+Go, Kubernetes`
+
+	testCoverLetterText = `Dear Hiring Manager,
+
+I am writing to apply for the Senior Engineer position at your organisation.
+Over the past decade I have led teams delivering distributed systems.
+
+Thank you for your consideration.
+
+Yours sincerely,
+Jane Smith`
+
+	testCertificateText = `CERTIFICATE OF COMPLETION
+This is to certify that Jane Smith has successfully completed
+the Advanced Kubernetes Administration course.`
+
+	testReferenceText = `To whom it may concern,
+
+It is my pleasure to recommend Jane Smith. I have known Jane for five years,
+during which she reported directly to me as a lead developer.
+
+Yours sincerely,
+Tom Baker, CTO`
+
+	testTranscriptText = `Official Transcript
+Course Code  Title              Credits  Grade
+CS101        Programming I      3        A
+Cumulative GPA: 3.8`
+)
+
+// TestClassifyDocument tests content-based classification with and without filename hints
+func TestClassifyDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		text     string
+		want     DocumentType
+	}{
+		{"CV by content", "JaneSmith_Application.pdf", testCVText, models.DocumentCV},
+		{"cover letter by content", "JaneSmith_Document1.pdf", testCoverLetterText, models.DocumentCoverLetter},
+		{"certificate by content", "JaneSmith_scan.pdf", testCertificateText, models.DocumentCertificate},
+		{"transcript by content", "JaneSmith_uni.pdf", testTranscriptText, models.DocumentTranscript},
+		{"reference by content", "JaneSmith_Document2.pdf", testReferenceText, models.DocumentReference},
+		{"content overrides misleading filename", "JaneSmith_CV.pdf", testCoverLetterText, models.DocumentCoverLetter},
+		{"filename hint for short text", "JohnDoe_Resume.txt", "John Doe", models.DocumentCV},
+		{"camel-case filename hint", "JohnDoeCoverLetter.txt", "John Doe", models.DocumentCoverLetter},
+		{"no signals", "JohnDoe_notes.txt", "Meeting at 10am tomorrow", DocumentTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyDocument(tt.filename, tt.text)
+			if got.Type != tt.want {
+				t.Errorf("ClassifyDocument() = %s (%s), want %s", got.Type, got.Reason, tt.want)
+			}
+			if tt.want != DocumentTypeUnknown && (got.Confidence <= 0 || got.Confidence > 1) {
+				t.Errorf("Confidence should be in (0, 1], got %v", got.Confidence)
+			}
+		})
+	}
+}
+
+// TestSplitCamelCase tests splitting of run-together file names
+func TestSplitCamelCase(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"JaneSmithCV", []string{"Jane", "Smith", "CV"}},
+		{"CVJane2024", []string{"CV", "Jane", "2024"}},
+		{"resume", []string{"resume"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := splitCamelCase(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCamelCase(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadDocuments_ClassifiesByContent tests that files are sorted by content and unusable ones reported
+func TestLoadDocuments_ClassifiesByContent(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_Application.txt"), []byte(testCVText), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_Letter.txt"), []byte(testCoverLetterText), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_Kubernetes.txt"), []byte(testCertificateText), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("Meeting at 10am tomorrow"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "JohnDoe_CoverLetter.txt"), []byte(testCoverLetterText), 0644)
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	if len(docs) != 1 || docs[0].Name != "JaneSmith" {
+		t.Fatalf("Expected only JaneSmith to be scored, got %+v", docs)
+	}
+	if !strings.Contains(docs[0].CV().Content, "Work Experience") || !strings.Contains(docs[0].CoverLetter().Content, "Dear Hiring Manager") {
+		t.Errorf("CV and cover letter not assigned by content")
+	}
+	if len(docs[0].AdditionalDocuments()) != 1 || docs[0].AdditionalDocuments()[0].Type != "certificate" {
+		t.Errorf("Certificate should be attached as a supporting document, got %+v", docs[0].AdditionalDocuments())
+	}
+
+	reasons := make(map[string]string)
+	for _, u := range fh.UnprocessedFiles() {
+		reasons[u.File] = u.DocumentType + ": " + u.Reason
+	}
+
+	expected := map[string]string{
+		"notes.txt":               "unknown",
+		"JohnDoe_CoverLetter.txt": "no CV found",
+	}
+	if len(reasons) != len(expected) {
+		t.Errorf("Expected %d unprocessed files, got %v", len(expected), reasons)
+	}
+	for file, want := range expected {
+		if !strings.Contains(reasons[file], want) {
+			t.Errorf("Expected %s to be reported with %q, got %q", file, want, reasons[file])
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package ingestion
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Word 97-2003 binary format constants (see [MS-DOC])
+const (
+	// wordFibIdent is the magic number at the start of the WordDocument stream
+	wordFibIdent = 0xA5EC
+	// wordMinFib is the nFib of Word 97; earlier versions use a different FIB layout
+	wordMinFib = 0x00C1
+	// fibFlagEncrypted marks password protected documents
+	fibFlagEncrypted = 0x0100
+	// fibFlagWhichTable selects 1Table (set) or 0Table (clear) as table stream
+	fibFlagWhichTable = 0x0200
+	// fibRgFcLcbClxIndex is the position of the fcClx/lcbClx pair in FibRgFcLcb97
+	fibRgFcLcbClxIndex = 33
+	// pcdCompressedFlag marks a piece stored as 8-bit text instead of UTF-16
+	pcdCompressedFlag = 0x40000000
+	// maxDocPieces guards against corrupt piece tables
+	maxDocPieces = 1 << 20
+)
+
+// oleSignature is the magic number of OLE compound documents (.doc, .xls, .ppt)
+const oleSignature = "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"
+
+// cp1252High maps bytes 0x80-0x9F of compressed Word text to Unicode
+var cp1252High = [32]rune{
+	0x20AC, 0xFFFD, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0xFFFD, 0x017D, 0xFFFD,
+	0xFFFD, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0xFFFD, 0x017E, 0x0178,
+}
+
+// wordFib holds the parts of the File Information Block needed for text extraction
+type wordFib struct {
+	flags    uint16
+	ccpText  uint32
+	ccpFtn   uint32
+	ccpHdd   uint32
+	fcClx    uint32
+	lcbClx   uint32
+	tableStm string
+}
+
+// wordPiece is one entry of the piece table mapping character positions to stream offsets
+type wordPiece struct {
+	cpStart    uint32
+	cpEnd      uint32
+	fc         uint32
+	compressed bool
+}
+
+// extractDOC extracts text from a legacy Word 97-2003 (.doc) file without external tools
+func extractDOC(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOC file: %w", err)
+	}
+	defer f.Close()
+
+	text, err := readWordBinary(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOC file %s: %w", filePath, err)
+	}
+
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// readWordBinary reads the main document and header/footer text of a Word binary file
+func readWordBinary(ra io.ReaderAt) (string, error) {
+	doc, err := mscfb.New(ra)
+	if err != nil {
+		return "", fmt.Errorf("not an OLE compound document: %w", err)
+	}
+
+	streams := make(map[string][]byte)
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "WordDocument", "0Table", "1Table":
+			data, err := io.ReadAll(entry)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s stream: %w", entry.Name, err)
+			}
+			streams[entry.Name] = data
+		}
+	}
+
+	wordDoc, ok := streams["WordDocument"]
+	if !ok {
+		return "", fmt.Errorf("WordDocument stream not found (not a Word file)")
+	}
+
+	fib, err := parseWordFib(wordDoc)
+	if err != nil {
+		return "", err
+	}
+
+	table, ok := streams[fib.tableStm]
+	if !ok {
+		return "", fmt.Errorf("%s stream not found", fib.tableStm)
+	}
+
+	pieces, err := parseWordPieceTable(table, fib.fcClx, fib.lcbClx)
+	if err != nil {
+		return "", err
+	}
+
+	// Main document text comes first, header/footer story follows footnotes
+	body := decodeWordRange(wordDoc, pieces, 0, fib.ccpText)
+	hddStart := fib.ccpText + fib.ccpFtn
+	headers := decodeWordRange(wordDoc, pieces, hddStart, hddStart+fib.ccpHdd)
+
+	text := cleanWordText(body)
+	if headerText := cleanWordText(headers); headerText != "" {
+		text = strings.TrimSpace(text + "\n\n" + headerText)
+	}
+
+	return text, nil
+}
+
+// parseWordFib parses the File Information Block at the start of the WordDocument stream
+func parseWordFib(data []byte) (wordFib, error) {
+	var fib wordFib
+
+	// FibBase is 32 bytes followed by the csw count
+	if len(data) < 34 {
+		return fib, fmt.Errorf("WordDocument stream too short")
+	}
+
+	if binary.LittleEndian.Uint16(data[0:2]) != wordFibIdent {
+		return fib, fmt.Errorf("invalid Word file signature")
+	}
+
+	nFib := binary.LittleEndian.Uint16(data[2:4])
+	if nFib < wordMinFib {
+		return fib, fmt.Errorf("unsupported Word version (nFib 0x%04X); only Word 97 and later are supported", nFib)
+	}
+
+	fib.flags = binary.LittleEndian.Uint16(data[10:12])
+	if fib.flags&fibFlagEncrypted != 0 {
+		return fib, fmt.Errorf("document is encrypted or password protected")
+	}
+
+	fib.tableStm = "0Table"
+	if fib.flags&fibFlagWhichTable != 0 {
+		fib.tableStm = "1Table"
+	}
+
+	// Walk the variable-length sections: csw/fibRgW, cslw/fibRgLw, cbRgFcLcb/fibRgFcLcb
+	pos := 32
+	csw := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2 + csw*2
+
+	if len(data) < pos+2 {
+		return fib, fmt.Errorf("truncated FIB")
+	}
+	cslw := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	rgLw := pos
+	pos += cslw * 4
+
+	// FibRgLw97: ccpText, ccpFtn and ccpHdd are the 4th, 5th and 6th fields
+	if cslw < 6 || len(data) < pos+2 {
+		return fib, fmt.Errorf("truncated FIB")
+	}
+	fib.ccpText = binary.LittleEndian.Uint32(data[rgLw+12:])
+	fib.ccpFtn = binary.LittleEndian.Uint32(data[rgLw+16:])
+	fib.ccpHdd = binary.LittleEndian.Uint32(data[rgLw+20:])
+
+	cbRgFcLcb := int(binary.LittleEndian.Uint16(data[pos:]))
+	pos += 2
+	if cbRgFcLcb <= fibRgFcLcbClxIndex || len(data) < pos+cbRgFcLcb*8 {
+		return fib, fmt.Errorf("truncated FIB")
+	}
+	clx := pos + fibRgFcLcbClxIndex*8
+	fib.fcClx = binary.LittleEndian.Uint32(data[clx:])
+	fib.lcbClx = binary.LittleEndian.Uint32(data[clx+4:])
+
+	return fib, nil
+}
+
+// parseWordPieceTable reads the piece table (PlcPcd) from the Clx structure in the table stream
+func parseWordPieceTable(table []byte, fcClx, lcbClx uint32) ([]wordPiece, error) {
+	end := uint64(fcClx) + uint64(lcbClx)
+	if lcbClx == 0 || end > uint64(len(table)) {
+		return nil, fmt.Errorf("invalid piece table location")
+	}
+	clx := table[fcClx:end]
+
+	// Skip Prc entries (0x01) until the Pcdt (0x02)
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			return nil, fmt.Errorf("truncated Clx")
+		}
+		cbGrpprl := int(int16(binary.LittleEndian.Uint16(clx[pos+1:])))
+		if cbGrpprl < 0 {
+			return nil, fmt.Errorf("invalid Prc size")
+		}
+		pos += 3 + cbGrpprl
+	}
+
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return nil, fmt.Errorf("piece table not found")
+	}
+	lcb := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+	pos += 5
+	if lcb < 4 || pos+lcb > len(clx) {
+		return nil, fmt.Errorf("truncated piece table")
+	}
+	plc := clx[pos : pos+lcb]
+
+	// PlcPcd holds n+1 character positions followed by n 8-byte piece descriptors
+	n := (lcb - 4) / 12
+	if n <= 0 || n > maxDocPieces {
+		return nil, fmt.Errorf("invalid piece count: %d", n)
+	}
+
+	pieces := make([]wordPiece, 0, n)
+	for i := 0; i < n; i++ {
+		pcd := plc[4*(n+1)+8*i:]
+		fc := binary.LittleEndian.Uint32(pcd[2:6])
+		pieces = append(pieces, wordPiece{
+			cpStart:    binary.LittleEndian.Uint32(plc[4*i:]),
+			cpEnd:      binary.LittleEndian.Uint32(plc[4*(i+1):]),
+			fc:         fc &^ (pcdCompressedFlag | 0x80000000),
+			compressed: fc&pcdCompressedFlag != 0,
+		})
+	}
+
+	return pieces, nil
+}
+
+// decodeWordRange decodes the characters in [cpStart, cpEnd) using the piece table
+func decodeWordRange(wordDoc []byte, pieces []wordPiece, cpStart, cpEnd uint32) string {
+	var sb strings.Builder
+
+	for _, p := range pieces {
+		start, end := p.cpStart, p.cpEnd
+		if start < cpStart {
+			start = cpStart
+		}
+		if end > cpEnd {
+			end = cpEnd
+		}
+		if start >= end {
+			continue
+		}
+
+		if p.compressed {
+			// Compressed text is stored at fc/2 with one byte per character
+			off := uint64(p.fc/2) + uint64(start-p.cpStart)
+			for i := uint64(0); i < uint64(end-start); i++ {
+				if off+i >= uint64(len(wordDoc)) {
+					break
+				}
+				b := wordDoc[off+i]
+				if b >= 0x80 && b <= 0x9F {
+					sb.WriteRune(cp1252High[b-0x80])
+				} else {
+					sb.WriteRune(rune(b))
+				}
+			}
+			continue
+		}
+
+		off := uint64(p.fc) + uint64(start-p.cpStart)*2
+		count := uint64(end - start)
+		if off+count*2 > uint64(len(wordDoc)) {
+			if off >= uint64(len(wordDoc)) {
+				continue
+			}
+			count = (uint64(len(wordDoc)) - off) / 2
+		}
+		units := make([]uint16, count)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(wordDoc[off+uint64(i)*2:])
+		}
+		sb.WriteString(string(utf16.Decode(units)))
+	}
+
+	return sb.String()
+}
+
+// cleanWordText converts Word control characters to plain text and drops field codes
+func cleanWordText(raw string) string {
+	var sb strings.Builder
+	fieldDepth := 0
+	// inCode tracks, per nesting level, whether we are before the field separator
+	var inCode []bool
+
+	for _, r := range raw {
+		switch r {
+		case 0x13: // field begin
+			fieldDepth++
+			inCode = append(inCode, true)
+			continue
+		case 0x14: // field separator: result text follows
+			if fieldDepth > 0 {
+				inCode[fieldDepth-1] = false
+			}
+			continue
+		case 0x15: // field end
+			if fieldDepth > 0 {
+				fieldDepth--
+				inCode = inCode[:fieldDepth]
+			}
+			continue
+		}
+
+		if fieldDepth > 0 && inCode[fieldDepth-1] {
+			continue
+		}
+
+		switch r {
+		case '\r', 0x0B, 0x0C, 0x0E: // paragraph, line, page and column breaks
+			sb.WriteByte('\n')
+		case 0x07: // table cell / row mark
+			sb.WriteByte('\t')
+		case 0x1E: // non-breaking hyphen
+			sb.WriteByte('-')
+		case 0xA0: // non-breaking space
+			sb.WriteByte(' ')
+		case 0x1F, 0x01, 0x02, 0x03, 0x04, 0x05, 0x08: // optional hyphen, objects, notes
+			// dropped
+		default:
+			if r < 0x20 && r != '\t' {
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+
+	return normalizeExtractedText(sb.String())
+}
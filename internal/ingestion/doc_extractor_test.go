@@ -0,0 +1,215 @@
+package ingestion
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// buildWordStream creates a minimal Word 97 WordDocument stream and 1Table stream holding text
+func buildWordStream(text string, compressed bool) (wordDoc, table []byte) {
+	const textOffset = 1024
+
+	wordDoc = make([]byte, textOffset)
+	binary.LittleEndian.PutUint16(wordDoc[0:], wordFibIdent)
+	binary.LittleEndian.PutUint16(wordDoc[2:], 0x00C1)
+	binary.LittleEndian.PutUint16(wordDoc[10:], fibFlagWhichTable)
+
+	// csw = 14, cslw = 22, cbRgFcLcb = 93 (Word 97 sizes)
+	binary.LittleEndian.PutUint16(wordDoc[32:], 14)
+	binary.LittleEndian.PutUint16(wordDoc[62:], 22)
+	binary.LittleEndian.PutUint16(wordDoc[152:], 93)
+
+	runes := []rune(text)
+	binary.LittleEndian.PutUint32(wordDoc[64+12:], uint32(len(runes))) // ccpText
+
+	fc := uint32(textOffset)
+	if compressed {
+		for _, r := range runes {
+			wordDoc = append(wordDoc, byte(r))
+		}
+		fc = uint32(textOffset*2) | pcdCompressedFlag
+	} else {
+		for _, u := range utf16.Encode(runes) {
+			wordDoc = binary.LittleEndian.AppendUint16(wordDoc, u)
+		}
+	}
+
+	// Clx: one Pcdt with a single piece covering the whole text
+	plc := make([]byte, 0, 16)
+	plc = binary.LittleEndian.AppendUint32(plc, 0)
+	plc = binary.LittleEndian.AppendUint32(plc, uint32(len(runes)))
+	plc = append(plc, 0, 0)
+	plc = binary.LittleEndian.AppendUint32(plc, fc)
+	plc = append(plc, 0, 0)
+
+	table = append(table, 0x02)
+	table = binary.LittleEndian.AppendUint32(table, uint32(len(plc)))
+	table = append(table, plc...)
+
+	clx := 154 + fibRgFcLcbClxIndex*8
+	binary.LittleEndian.PutUint32(wordDoc[clx:], 0)
+	binary.LittleEndian.PutUint32(wordDoc[clx+4:], uint32(len(table)))
+
+	return wordDoc, table
+}
+
+// buildCompoundFile wraps the given streams in a version 3 OLE compound file.
+// Streams are padded to 4096 bytes so they live in regular sectors.
+func buildCompoundFile(streams map[string][]byte, order []string) []byte {
+	const sectorSize = 512
+	const endOfChain, freeSect, fatSect, noStream = 0xFFFFFFFE, 0xFFFFFFFF, 0xFFFFFFFD, 0xFFFFFFFF
+
+	fat := []uint32{fatSect, endOfChain} // sector 0: FAT, sector 1: directory
+	var body []byte
+	starts := make([]uint32, len(order))
+	sizes := make([]int, len(order))
+	for i, name := range order {
+		data := streams[name]
+		padded := make([]byte, ((max(len(data), 4096)+sectorSize-1)/sectorSize)*sectorSize)
+		copy(padded, data)
+		starts[i] = uint32(len(fat))
+		sizes[i] = len(padded)
+		n := len(padded) / sectorSize
+		for j := 0; j < n; j++ {
+			if j == n-1 {
+				fat = append(fat, endOfChain)
+			} else {
+				fat = append(fat, uint32(len(fat)+1))
+			}
+		}
+		body = append(body, padded...)
+	}
+
+	header := make([]byte, sectorSize)
+	copy(header, oleSignature)
+	binary.LittleEndian.PutUint16(header[24:], 0x003E)
+	binary.LittleEndian.PutUint16(header[26:], 3)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], 1) // one FAT sector
+	binary.LittleEndian.PutUint32(header[48:], 1) // directory starts at sector 1
+	binary.LittleEndian.PutUint32(header[56:], 4096)
+	binary.LittleEndian.PutUint32(header[60:], endOfChain)
+	binary.LittleEndian.PutUint32(header[68:], endOfChain)
+	for i := 76; i < sectorSize; i += 4 {
+		binary.LittleEndian.PutUint32(header[i:], freeSect)
+	}
+	binary.LittleEndian.PutUint32(header[76:], 0)
+
+	fatSector := make([]byte, sectorSize)
+	for i := 0; i < sectorSize/4; i++ {
+		v := uint32(freeSect)
+		if i < len(fat) {
+			v = fat[i]
+		}
+		binary.LittleEndian.PutUint32(fatSector[i*4:], v)
+	}
+
+	dirEntry := func(name string, typ byte, left, right, child, start uint32, size int) []byte {
+		e := make([]byte, 128)
+		units := utf16.Encode([]rune(name))
+		for i, u := range units {
+			binary.LittleEndian.PutUint16(e[i*2:], u)
+		}
+		if name != "" {
+			binary.LittleEndian.PutUint16(e[64:], uint16((len(units)+1)*2))
+		}
+		e[66] = typ
+		e[67] = 1
+		binary.LittleEndian.PutUint32(e[68:], left)
+		binary.LittleEndian.PutUint32(e[72:], right)
+		binary.LittleEndian.PutUint32(e[76:], child)
+		binary.LittleEndian.PutUint32(e[116:], start)
+		binary.LittleEndian.PutUint32(e[120:], uint32(size))
+		return e
+	}
+
+	// Root entry links the streams as a chain of right siblings
+	dir := dirEntry("Root Entry", 5, noStream, noStream, 1, endOfChain, 0)
+	for i, name := range order {
+		right := uint32(noStream)
+		if i < len(order)-1 {
+			right = uint32(i + 2)
+		}
+		dir = append(dir, dirEntry(name, 2, noStream, right, noStream, starts[i], sizes[i])...)
+	}
+	for len(dir) < sectorSize {
+		dir = append(dir, dirEntry("", 0, noStream, noStream, noStream, 0, 0)...)
+	}
+
+	out := append(header, fatSector...)
+	out = append(out, dir...)
+	return append(out, body...)
+}
+
+func writeTestDoc(t *testing.T, text string, compressed bool) string {
+	t.Helper()
+	wordDoc, table := buildWordStream(text, compressed)
+	data := buildCompoundFile(map[string][]byte{
+		"WordDocument": wordDoc,
+		"1Table":       table,
+	}, []string{"WordDocument", "1Table"})
+
+	path := filepath.Join(t.TempDir(), "JaneSmith_CV.doc")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write test document: %v", err)
+	}
+	return path
+}
+
+// TestExtractDOC_Unicode tests extraction from a document stored as UTF-16 text
+func TestExtractDOC_Unicode(t *testing.T) {
+	text := "Jane Smith\rSenior Engineer – Zürich\r\x13 HYPERLINK \"mailto:jane@example.com\" \x14jane@example.com\x15\rExperience\x07Go\x07\r"
+	path := writeTestDoc(t, text, false)
+
+	result, err := ExtractText(path)
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+
+	for _, want := range []string{"Jane Smith\n", "Senior Engineer – Zürich", "jane@example.com", "Experience\tGo"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Extracted text missing %q, got:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "HYPERLINK") {
+		t.Errorf("Field codes should be removed, got:\n%s", result)
+	}
+}
+
+// TestExtractDOC_Compressed tests extraction from a document stored as 8-bit text
+func TestExtractDOC_Compressed(t *testing.T) {
+	text := "John Doe\rProject Manager \u0093Agile\u0094 with 10 years of experience\r"
+	path := writeTestDoc(t, text, true)
+
+	result, err := ExtractText(path)
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+
+	if !strings.Contains(result, "Project Manager “Agile” with 10 years") {
+		t.Errorf("Unexpected extracted text:\n%s", result)
+	}
+}
+
+// TestExtractDOC_NotOLE tests that non-OLE files are rejected with an error
+func TestExtractDOC_NotOLE(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fake.doc")
+	os.WriteFile(path, []byte(strings.Repeat("not a word document ", 10)), 0644)
+
+	if _, err := ExtractText(path); err == nil {
+		t.Error("ExtractText() should return error for non-OLE .doc file")
+	}
+}
+
+// TestIsBinaryData_OLE tests that OLE compound documents are detected as binary
+func TestIsBinaryData_OLE(t *testing.T) {
+	if !IsBinaryData(oleSignature + "rest of file") {
+		t.Error("IsBinaryData() returned false for OLE content")
+	}
+}
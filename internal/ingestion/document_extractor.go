@@ -5,8 +5,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-
-	"github.com/nguyenthenguyen/docx"
 )
 
 const (
@@ -18,21 +16,20 @@ const (
 	BinaryThreshold = 0.3
 )
 
-// ExtractText extracts text from PDF, DOCX, DOC, or TXT files
+// ExtractText extracts text from any format in the extractor registry
+// (PDF, DOC, DOCX, RTF, ODT, HTML, Markdown, and images via OCR). Plain
+// text files need no extraction and return an empty string.
 func ExtractText(filePath string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
+	format := lookupFormat(filePath)
+	if format == nil {
+		return "", fmt.Errorf("unsupported file type: %s", strings.ToLower(filepath.Ext(filePath)))
+	}
 
-	switch ext {
-	case ".txt":
-		// Plain text - no extraction needed
-		return "", nil
-	case ".pdf":
-		return extractPDF(filePath)
-	case ".docx", ".doc":
-		return extractDOCX(filePath)
-	default:
-		return "", fmt.Errorf("unsupported file type: %s", ext)
+	extraction, err := format.extract(filePath)
+	if err != nil {
+		return "", err
 	}
+	return extraction.Text, nil
 }
 
 // extractPDF extracts text from PDF using pdftotext (if available) or returns error
@@ -52,42 +49,7 @@ func extractPDF(filePath string) (string, error) {
 	return text, nil
 }
 
-// extractDOCX extracts text from DOCX using antiword (for .doc) or docx library (for .docx)
-func extractDOCX(filePath string) (string, error) {
-	ext := strings.ToLower(filepath.Ext(filePath))
-
-	if ext == ".doc" {
-		// Try antiword for .doc files
-		cmd := exec.Command("antiword", filePath)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			return "", fmt.Errorf("DOC extraction requires 'antiword': %w\nFile appears to be binary DOC: %s", err, filePath)
-		}
-		return string(output), nil
-	}
-
-	// For .docx files, use the docx library
-	if ext == ".docx" {
-		r, err := docx.ReadDocxFile(filePath)
-		if err != nil {
-			return "", fmt.Errorf("failed to open DOCX file: %w", err)
-		}
-		defer r.Close()
-
-		doc := r.Editable()
-		text := doc.GetContent()
-
-		if len(text) < MinExtractedTextLength {
-			return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
-		}
-
-		return text, nil
-	}
-
-	return "", fmt.Errorf("unsupported document format: %s", ext)
-}
-
-// IsBinaryData checks if content appears to be binary (PDF/ZIP markers)
+// IsBinaryData checks if content appears to be binary (PDF/ZIP/OLE/image markers)
 func IsBinaryData(content string) bool {
 	if len(content) == 0 {
 		return false
@@ -103,6 +65,18 @@ func IsBinaryData(content string) bool {
 		return true
 	}
 
+	// Check for OLE compound document magic number (DOC files)
+	if strings.HasPrefix(content, oleSignature) {
+		return true
+	}
+
+	// Check for image magic numbers (PNG, JPEG, TIFF scans)
+	for _, magic := range []string{"\x89PNG", "\xFF\xD8\xFF", "II*\x00", "MM\x00*"} {
+		if strings.HasPrefix(content, magic) {
+			return true
+		}
+	}
+
 	// Check for high proportion of non-printable characters
 	sampleSize := min(BinarySampleSize, len(content))
 	nonPrintable := 0
@@ -143,8 +143,6 @@ func TestExtractText_TXT(t *testing.T) {
 // TestExtractText_UnsupportedType tests that unsupported file types return error
 func TestExtractText_UnsupportedType(t *testing.T) {
 	tests := []string{
-		"test.jpg",
-		"test.png",
 		"test.xlsx",
 		"test.unknown",
 	}
@@ -0,0 +1,227 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// wordprocessingNS is the WordprocessingML main namespace
+	wordprocessingNS = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	// markupCompatibilityNS holds mc:AlternateContent, used for text boxes
+	markupCompatibilityNS = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+	// maxDOCXPartSize limits how much of each XML part is read to guard against zip bombs
+	maxDOCXPartSize = 50 << 20
+)
+
+// docxTableState tracks the current row of an open (possibly nested) table
+type docxTableState struct {
+	cellIndex int
+	cellParas int
+}
+
+// extractDOCX extracts text from a DOCX file, including tables, text boxes, headers and footers
+func extractDOCX(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX file: %w", err)
+	}
+	defer r.Close()
+
+	var document *zip.File
+	var headers, footers []*zip.File
+	for _, f := range r.File {
+		dir, name := path.Split(f.Name)
+		if dir != "word/" || path.Ext(name) != ".xml" {
+			continue
+		}
+		switch {
+		case name == "document.xml":
+			document = f
+		case strings.HasPrefix(name, "header"):
+			headers = append(headers, f)
+		case strings.HasPrefix(name, "footer"):
+			footers = append(footers, f)
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("word/document.xml not found in DOCX file: %s", filePath)
+	}
+
+	body, err := readDOCXPart(document)
+	if err != nil {
+		return "", err
+	}
+
+	// Headers usually carry contact details, footers page numbers and references
+	var sections []string
+	sections = append(sections, readDOCXParts(headers)...)
+	sections = append(sections, body)
+	sections = append(sections, readDOCXParts(footers)...)
+
+	text := normalizeExtractedText(strings.Join(sections, "\n\n"))
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// readDOCXParts returns the distinct non-empty text of header or footer parts in name order.
+// Documents with first-page and even-page variants often repeat the same content.
+func readDOCXParts(parts []*zip.File) []string {
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Name < parts[j].Name })
+
+	var texts []string
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		text, err := readDOCXPart(part)
+		if err != nil || text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		texts = append(texts, text)
+	}
+	return texts
+}
+
+// readDOCXPart parses a single WordprocessingML part
+func readDOCXPart(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX part %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	text, err := wordMLToText(io.LimitReader(rc, maxDOCXPartSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse DOCX part %s: %w", f.Name, err)
+	}
+	return text, nil
+}
+
+// wordMLToText walks WordprocessingML and emits paragraphs, table rows and cells as plain text
+func wordMLToText(r io.Reader) (string, error) {
+	var sb strings.Builder
+	decoder := xml.NewDecoder(r)
+	var tables []docxTableState
+	// paraDepth is greater than one inside text boxes anchored in a paragraph
+	paraDepth := 0
+	inText := false
+
+	inCell := func() bool {
+		return len(tables) > 0 && tables[len(tables)-1].cellIndex > 0
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Space == markupCompatibilityNS && t.Name.Local == "Fallback" {
+				// VML fallback duplicates the text box content of mc:Choice
+				if err := decoder.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			}
+			if t.Name.Space != wordprocessingNS {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "p":
+				paraDepth++
+				if paraDepth > 1 {
+					sb.WriteByte('\n')
+				} else if inCell() {
+					// Paragraphs inside a table cell are joined with spaces
+					top := &tables[len(tables)-1]
+					if top.cellParas > 0 {
+						sb.WriteByte(' ')
+					}
+					top.cellParas++
+				}
+			case "pPr":
+				var props struct {
+					NumPr *struct{} `xml:"numPr"`
+				}
+				if err := decoder.DecodeElement(&props, &t); err != nil {
+					return "", err
+				}
+				if props.NumPr != nil {
+					sb.WriteString("- ")
+				}
+			case "rPr", "del", "moveFrom", "instrText", "delInstrText":
+				// Run formatting, deleted revisions and field codes are not visible text
+				if err := decoder.Skip(); err != nil {
+					return "", err
+				}
+			case "t":
+				inText = true
+			case "tab", "ptab":
+				sb.WriteByte('\t')
+			case "br", "cr":
+				sb.WriteByte('\n')
+			case "noBreakHyphen":
+				sb.WriteByte('-')
+			case "tbl":
+				tables = append(tables, docxTableState{})
+			case "tr":
+				if len(tables) > 0 {
+					tables[len(tables)-1].cellIndex = 0
+				}
+			case "tc":
+				if len(tables) > 0 {
+					top := &tables[len(tables)-1]
+					if top.cellIndex > 0 {
+						sb.WriteByte('\t')
+					}
+					top.cellIndex++
+					top.cellParas = 0
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != wordprocessingNS {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "p":
+				// Text box paragraphs already started on their own line
+				paraDepth--
+				if paraDepth == 0 && !inCell() {
+					sb.WriteByte('\n')
+				}
+			case "t":
+				inText = false
+			case "tr":
+				sb.WriteByte('\n')
+				if len(tables) > 0 {
+					tables[len(tables)-1].cellIndex = 0
+				}
+			case "tbl":
+				if len(tables) > 0 {
+					tables = tables[:len(tables)-1]
+				}
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return normalizeExtractedText(sb.String()), nil
+}
@@ -0,0 +1,143 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testWordNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+  xmlns:mc="http://schemas.openxmlformats.org/markup-compatibility/2006"
+  xmlns:wps="http://schemas.microsoft.com/office/word/2010/wordprocessingShape"
+  xmlns:v="urn:schemas-microsoft-com:vml"`
+
+// writeTestDOCX creates a DOCX file with the given document body and extra parts (name -> root XML)
+func writeTestDOCX(t *testing.T, body string, parts map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "JaneSmith_CV.docx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create DOCX: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("[Content_Types].xml")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Types/>`))
+	w, _ = zw.Create("word/document.xml")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document ` + testWordNamespaces + `><w:body>` + body + `</w:body></w:document>`))
+	for name, xmlBody := range parts {
+		w, _ = zw.Create(name)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` + xmlBody))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write DOCX: %v", err)
+	}
+	return path
+}
+
+// TestExtractDOCX_TemplateCV tests a two-column template with header, footer, list and text box
+func TestExtractDOCX_TemplateCV(t *testing.T) {
+	body := `
+    <w:p><w:pPr><w:pStyle w:val="Title"/><w:tabs><w:tab w:val="right" w:pos="9000"/></w:tabs></w:pPr>
+      <w:r><w:rPr><w:b/></w:rPr><w:t>Jane Smith</w:t></w:r></w:p>
+    <w:p><w:r><w:t xml:space="preserve">Senior </w:t></w:r><w:r><w:t>Engineer</w:t></w:r>
+      <w:del><w:r><w:delText>Junior</w:delText></w:r></w:del></w:p>
+    <w:tbl>
+      <w:tr>
+        <w:tc><w:p><w:r><w:t>2018 - 2024</w:t></w:r></w:p></w:tc>
+        <w:tc><w:p><w:r><w:t>Acme Corp</w:t></w:r></w:p><w:p><w:r><w:t>Lead Developer</w:t></w:r></w:p></w:tc>
+      </w:tr>
+      <w:tr>
+        <w:tc><w:p><w:r><w:t>2014 - 2018</w:t></w:r></w:p></w:tc>
+        <w:tc><w:p><w:r><w:t>Globex</w:t></w:r></w:p></w:tc>
+      </w:tr>
+    </w:tbl>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Go</w:t></w:r></w:p>
+    <w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>Kubernetes</w:t></w:r></w:p>
+    <w:p><w:r><w:t>Skills</w:t></w:r><w:r>
+      <mc:AlternateContent>
+        <mc:Choice Requires="wps"><w:drawing><wps:txbx><w:txbxContent>
+          <w:p><w:r><w:t>Languages: English, French</w:t></w:r></w:p>
+        </w:txbxContent></wps:txbx></w:drawing></mc:Choice>
+        <mc:Fallback><w:pict><v:textbox><w:txbxContent>
+          <w:p><w:r><w:t>Languages: English, French</w:t></w:r></w:p>
+        </w:txbxContent></v:textbox></w:pict></mc:Fallback>
+      </mc:AlternateContent></w:r></w:p>
+    <w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>HYPERLINK "https://example.com"</w:instrText></w:r>
+      <w:r><w:t>Portfolio</w:t></w:r><w:r><w:tab/><w:t>Zürich</w:t></w:r></w:p>`
+
+	parts := map[string]string{
+		"word/header1.xml": `<w:hdr ` + testWordNamespaces + `><w:p><w:r><w:t>jane@example.com | +41 79 555 0101</w:t></w:r></w:p></w:hdr>`,
+		"word/header2.xml": `<w:hdr ` + testWordNamespaces + `><w:p><w:r><w:t>jane@example.com | +41 79 555 0101</w:t></w:r></w:p></w:hdr>`,
+		"word/footer1.xml": `<w:ftr ` + testWordNamespaces + `><w:p><w:r><w:t>References available on request</w:t></w:r></w:p></w:ftr>`,
+	}
+
+	text, err := ExtractText(writeTestDOCX(t, body, parts))
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"jane@example.com | +41 79 555 0101",
+		"",
+		"Jane Smith",
+		"Senior Engineer",
+		"2018 - 2024\tAcme Corp Lead Developer",
+		"2014 - 2018\tGlobex",
+		"- Go",
+		"- Kubernetes",
+		"Skills",
+		"Languages: English, French",
+		"Portfolio\tZürich",
+		"",
+		"References available on request",
+	}, "\n")
+	if text != expected {
+		t.Errorf("Unexpected DOCX text.\nGot:\n%s\n\nWant:\n%s", text, expected)
+	}
+}
+
+// TestExtractDOCX_NestedTable tests that a table inside a cell keeps the outer row intact
+func TestExtractDOCX_NestedTable(t *testing.T) {
+	body := `
+    <w:tbl><w:tr>
+      <w:tc><w:p><w:r><w:t>Contact</w:t></w:r></w:p></w:tc>
+      <w:tc>
+        <w:tbl>
+          <w:tr><w:tc><w:p><w:r><w:t>Email</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>jane@example.com</w:t></w:r></w:p></w:tc></w:tr>
+        </w:tbl>
+        <w:p><w:r><w:t>Available immediately for senior engineering roles</w:t></w:r></w:p>
+      </w:tc>
+    </w:tr></w:tbl>`
+
+	text, err := ExtractText(writeTestDOCX(t, body, nil))
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+
+	for _, want := range []string{"Email\tjane@example.com", "Available immediately"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+// TestExtractDOCX_MissingDocumentPart tests that a ZIP without word/document.xml is rejected
+func TestExtractDOCX_MissingDocumentPart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JaneSmith_CV.docx")
+	f, _ := os.Create(path)
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("readme.txt")
+	w.Write([]byte("not a word document"))
+	zw.Close()
+	f.Close()
+
+	if _, err := ExtractText(path); err == nil || !strings.Contains(err.Error(), "word/document.xml") {
+		t.Errorf("Expected missing document.xml error, got %v", err)
+	}
+}
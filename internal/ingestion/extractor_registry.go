@@ -0,0 +1,200 @@
+package ingestion
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExtractorFunc converts the document at filePath into plain text
+type ExtractorFunc func(filePath string) (string, error)
+
+// OCRFunc recognizes the text of a scanned document or image at filePath
+type OCRFunc func(filePath string) (*OCRResult, error)
+
+// Format describes a document format known to the extractor registry
+type Format struct {
+	// Extensions are the lower-case file extensions including the dot (e.g. ".pdf")
+	Extensions []string
+	// MIMETypes are the media types that identify this format when sniffing content
+	MIMETypes []string
+	// Text marks formats whose raw bytes are readable text (TXT, HTML, Markdown)
+	Text bool
+	// Extract converts a file to plain text; nil means the content is used as-is
+	Extract ExtractorFunc
+	// OCR recognizes scanned content; it runs when Extract is nil or fails
+	OCR OCRFunc
+}
+
+// Extraction is the readable text of a document and, when OCR was used, its per-page confidence
+type Extraction struct {
+	Text string
+	OCR  *OCRResult
+}
+
+// extractorRegistry maps extensions and MIME types to document formats
+type extractorRegistry struct {
+	mu     sync.RWMutex
+	byExt  map[string]*Format
+	byMIME map[string]*Format
+}
+
+var registry = &extractorRegistry{
+	byExt:  make(map[string]*Format),
+	byMIME: make(map[string]*Format),
+}
+
+func init() {
+	RegisterFormat(Format{Extensions: []string{".txt"}, MIMETypes: []string{"text/plain"}, Text: true})
+	RegisterFormat(Format{Extensions: []string{".pdf"}, MIMETypes: []string{"application/pdf"}, Extract: extractPDF, OCR: recognizePDF})
+	RegisterFormat(Format{Extensions: []string{".doc"}, MIMETypes: []string{"application/msword"}, Extract: extractDOC})
+	RegisterFormat(Format{Extensions: []string{".docx"}, MIMETypes: []string{"application/vnd.openxmlformats-officedocument.wordprocessingml.document"}, Extract: extractDOCX})
+	RegisterFormat(Format{Extensions: []string{".rtf"}, MIMETypes: []string{"application/rtf", "text/rtf"}, Text: true, Extract: extractRTF})
+	RegisterFormat(Format{Extensions: []string{".odt"}, MIMETypes: []string{"application/vnd.oasis.opendocument.text"}, Extract: extractODT})
+	RegisterFormat(Format{Extensions: []string{".html", ".htm"}, MIMETypes: []string{"text/html"}, Text: true, Extract: extractHTML})
+	RegisterFormat(Format{Extensions: []string{".md", ".markdown"}, MIMETypes: []string{"text/markdown"}, Text: true, Extract: extractMarkdown})
+	RegisterFormat(Format{Extensions: []string{".png"}, MIMETypes: []string{"image/png"}, OCR: recognizeImage})
+	RegisterFormat(Format{Extensions: []string{".jpg", ".jpeg"}, MIMETypes: []string{"image/jpeg"}, OCR: recognizeImage})
+	RegisterFormat(Format{Extensions: []string{".tif", ".tiff"}, MIMETypes: []string{"image/tiff"}, OCR: recognizeImage})
+}
+
+// RegisterFormat adds a document format to the registry, replacing any
+// existing registration for the same extensions or MIME types
+func RegisterFormat(f Format) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	format := f
+	for _, ext := range f.Extensions {
+		registry.byExt[strings.ToLower(ext)] = &format
+	}
+	for _, mimeType := range f.MIMETypes {
+		registry.byMIME[strings.ToLower(mimeType)] = &format
+	}
+}
+
+// lookupFormat returns the format registered for a file's extension
+func lookupFormat(filename string) *Format {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.byExt[strings.ToLower(filepath.Ext(filename))]
+}
+
+// lookupFormatByMIME returns the format registered for a MIME type
+func lookupFormatByMIME(mimeType string) *Format {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.byMIME[strings.ToLower(mimeType)]
+}
+
+// IsSupportedFile reports whether a file's extension has a registered extractor
+func IsSupportedFile(filename string) bool {
+	return lookupFormat(filename) != nil
+}
+
+// SupportedExtensions returns all registered file extensions in sorted order
+func SupportedExtensions() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	exts := make([]string, 0, len(registry.byExt))
+	for ext := range registry.byExt {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// SniffMIMEType detects the media type of document content from its leading bytes
+func SniffMIMEType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte(oleSignature)):
+		return "application/msword"
+	case bytes.HasPrefix(data, []byte(`{\rtf`)):
+		return "application/rtf"
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return sniffZipMIMEType(data)
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return "image/tiff"
+	}
+
+	mimeType := http.DetectContentType(data)
+	if idx := strings.Index(mimeType, ";"); idx >= 0 {
+		mimeType = mimeType[:idx]
+	}
+	return mimeType
+}
+
+// sniffZipMIMEType distinguishes OpenDocument and Office Open XML packages from plain ZIP files
+func sniffZipMIMEType(data []byte) string {
+	// ODF packages store an uncompressed "mimetype" entry first
+	if len(data) > 38 && string(data[30:38]) == "mimetype" {
+		rest := data[38:]
+		if end := bytes.Index(rest, []byte("PK")); end > 0 && bytes.HasPrefix(rest, []byte("application/")) {
+			return string(rest[:end])
+		}
+	}
+
+	sample := data[:min(len(data), 8192)]
+	if bytes.Contains(sample, []byte("word/")) {
+		return "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	}
+	return "application/zip"
+}
+
+// ExtractDocument returns the readable text of a document whose raw bytes are content.
+// The format is chosen from the file extension; if the bytes are binary but the
+// extension names a text format, the format is chosen by sniffing the content instead.
+func ExtractDocument(filePath string, content []byte) (*Extraction, error) {
+	format := lookupFormat(filePath)
+	binary := IsBinaryData(string(content))
+
+	if binary && (format == nil || format.Text) {
+		format = lookupFormatByMIME(SniffMIMEType(content))
+	}
+
+	if format == nil {
+		return nil, fmt.Errorf("unsupported file type: %s", strings.ToLower(filepath.Ext(filePath)))
+	}
+
+	// Plain text, and binary formats that actually contain plain text, are used as-is
+	if (format.Text && format.Extract == nil) || (!binary && !format.Text) {
+		return &Extraction{Text: string(content)}, nil
+	}
+
+	return format.extract(filePath)
+}
+
+// extract runs the format's extractor, falling back to OCR for scanned documents
+func (f *Format) extract(filePath string) (*Extraction, error) {
+	var extractErr error
+	if f.Extract != nil {
+		text, err := f.Extract(filePath)
+		if err == nil {
+			return &Extraction{Text: text}, nil
+		}
+		if f.OCR == nil {
+			return nil, err
+		}
+		log.Printf("Text extraction failed for %s, trying OCR: %v", filepath.Base(filePath), err)
+		extractErr = err
+	}
+
+	if f.OCR == nil {
+		return &Extraction{}, nil
+	}
+
+	result, err := f.OCR(filePath)
+	if err != nil {
+		if extractErr != nil {
+			return nil, fmt.Errorf("%v; OCR fallback failed: %w", extractErr, err)
+		}
+		return nil, err
+	}
+	return &Extraction{Text: result.Text(), OCR: result}, nil
+}
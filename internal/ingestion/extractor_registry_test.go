@@ -0,0 +1,130 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestIsSupportedFile tests extension lookups in the extractor registry
+func TestIsSupportedFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     bool
+	}{
+		{"cv.pdf", true},
+		{"cv.DOCX", true},
+		{"cv.doc", true},
+		{"cv.txt", true},
+		{"cv.rtf", true},
+		{"cv.odt", true},
+		{"cv.html", true},
+		{"cv.htm", true},
+		{"cv.md", true},
+		{"cv.xlsx", false},
+		{"cv", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := IsSupportedFile(tt.filename); got != tt.want {
+				t.Errorf("IsSupportedFile(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRegisterFormat tests that custom formats become available to ExtractText
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat(Format{
+		Extensions: []string{".cvx"},
+		MIMETypes:  []string{"application/x-test-cv"},
+		Extract: func(filePath string) (string, error) {
+			return "extracted " + filepath.Base(filePath), nil
+		},
+	})
+
+	if !IsSupportedFile("candidate.cvx") {
+		t.Fatal("Registered extension should be supported")
+	}
+
+	text, err := ExtractText("candidate.cvx")
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+	if text != "extracted candidate.cvx" {
+		t.Errorf("Unexpected text %q", text)
+	}
+}
+
+// TestSniffMIMEType tests content-based format detection
+func TestSniffMIMEType(t *testing.T) {
+	var odt bytes.Buffer
+	zw := zip.NewWriter(&odt)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	w.Write([]byte("application/vnd.oasis.opendocument.text"))
+	zw.Close()
+
+	var docx bytes.Buffer
+	zw = zip.NewWriter(&docx)
+	w, _ = zw.Create("word/document.xml")
+	w.Write([]byte("<w:document/>"))
+	zw.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"PDF", []byte("%PDF-1.7\n%%EOF"), "application/pdf"},
+		{"OLE", []byte(oleSignature + "\x00\x00"), "application/msword"},
+		{"RTF", []byte(`{\rtf1\ansi Hello}`), "application/rtf"},
+		{"HTML", []byte("<!DOCTYPE html><html><body>Hi</body></html>"), "text/html"},
+		{"Plain text", []byte("Jane Smith\nEngineer"), "text/plain"},
+		{"ODT", odt.Bytes(), "application/vnd.oasis.opendocument.text"},
+		{"DOCX", docx.Bytes(), "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SniffMIMEType(tt.data); got != tt.want {
+				t.Errorf("SniffMIMEType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExtractDocument_SniffsMislabeledFile tests that binary content behind a text extension is routed by MIME type
+func TestExtractDocument_SniffsMislabeledFile(t *testing.T) {
+	text := "Jane Smith\rSenior Engineer with twelve years of experience building systems\r"
+	docPath := writeTestDoc(t, text, false)
+	data, err := os.ReadFile(docPath)
+	if err != nil {
+		t.Fatalf("Failed to read test document: %v", err)
+	}
+
+	mislabeled := filepath.Join(t.TempDir(), "JaneSmith_CV.txt")
+	os.WriteFile(mislabeled, data, 0644)
+
+	result, err := ExtractDocument(mislabeled, data)
+	if err != nil {
+		t.Fatalf("ExtractDocument() returned error: %v", err)
+	}
+	if !strings.Contains(result.Text, "Senior Engineer") {
+		t.Errorf("Expected Word text, got %q", result.Text)
+	}
+}
+
+// TestExtractDocument_PlainText tests that plain text content is returned unchanged
+func TestExtractDocument_PlainText(t *testing.T) {
+	result, err := ExtractDocument("JohnDoe_CV.txt", []byte("John Doe CV content"))
+	if err != nil {
+		t.Fatalf("ExtractDocument() returned error: %v", err)
+	}
+	if result.Text != "John Doe CV content" || result.OCR != nil {
+		t.Errorf("Expected unchanged content without OCR, got %+v", result)
+	}
+}
@@ -3,17 +3,20 @@ package ingestion
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fmuoria/CV-Review-agent/internal/models"
 )
 
 // FileHandler manages file operations for CV and cover letter ingestion
 type FileHandler struct {
-	uploadsDir string
+	uploadsDir  string
+	unprocessed []models.UnprocessedFile
 }
 
 // NewFileHandler creates a new file handler
@@ -23,14 +26,22 @@ func NewFileHandler(uploadsDir string) *FileHandler {
 	}
 }
 
-// SaveUploadedFile saves an uploaded file to the uploads directory
+// SaveUploadedFile saves an uploaded file to the uploads directory. The
+// client-supplied name is sanitized (see SanitizeUploadName), so it cannot
+// point outside the directory, and a counter is added rather than replacing
+// an earlier file of the same name.
 func (fh *FileHandler) SaveUploadedFile(filename string, content io.Reader) (string, error) {
+	filename, err := SanitizeUploadName(filename)
+	if err != nil {
+		return "", err
+	}
+
 	// Ensure uploads directory exists
 	if err := os.MkdirAll(fh.uploadsDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create uploads directory: %w", err)
 	}
 
-	filePath := filepath.Join(fh.uploadsDir, filename)
+	filePath := uniqueFilePath(fh.uploadsDir, filename)
 	file, err := os.Create(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to create file: %w", err)
@@ -44,89 +55,291 @@ func (fh *FileHandler) SaveUploadedFile(filename string, content io.Reader) (str
 	return filePath, nil
 }
 
-// LoadDocuments loads all documents from the uploads directory
-func (fh *FileHandler) LoadDocuments() ([]models.ApplicantDocument, error) {
-	files, err := os.ReadDir(fh.uploadsDir)
+// uniqueFilePath returns a path in dir for filename that does not exist yet,
+// adding a counter ("Name_CV_2.pdf") when needed
+func uniqueFilePath(dir, filename string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	path := filepath.Join(dir, filename)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s_%d%s", base, n, ext))
+	}
+}
+
+// uploadedFile is a document found in the uploads directory
+type uploadedFile struct {
+	name    string // path relative to the uploads directory ("Jane Smith/cv.pdf")
+	path    string
+	folder  string // top-level applicant folder, empty for files directly in the uploads directory
+	modTime time.Time
+}
+
+// listUploads returns the files in the uploads directory, including those in
+// per-applicant folders ("Jane Smith/cv.pdf"). Hidden files and folders are ignored.
+func (fh *FileHandler) listUploads() ([]uploadedFile, error) {
+	var files []uploadedFile
+	err := filepath.WalkDir(fh.uploadsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == fh.uploadsDir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(fh.uploadsDir, path)
+		if err != nil {
+			return err
+		}
+		file := uploadedFile{name: filepath.ToSlash(rel), path: path}
+		if i := strings.Index(file.name, "/"); i >= 0 {
+			file.folder = file.name[:i]
+		}
+		if info, err := d.Info(); err == nil {
+			file.modTime = info.ModTime()
+		}
+		files = append(files, file)
+		return nil
+	})
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []models.ApplicantDocument{}, nil
+			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to read uploads directory: %w", err)
 	}
+	return files, nil
+}
+
+// LoadDocuments loads all documents from the uploads directory. Files may sit
+// directly in the directory ("JaneSmith_CV.pdf") or in a folder per applicant
+// ("Jane Smith/cv.pdf"), in which case the folder names the applicant. Each file
+// is classified from its content (with the file name as a hint). An applicant
+// gets their newest CV and cover letter plus all portfolios, certificates,
+// references and transcripts; files that cannot be used are listed by UnprocessedFiles.
+func (fh *FileHandler) LoadDocuments() ([]models.ApplicantDocument, error) {
+	fh.unprocessed = nil
+
+	files, err := fh.listUploads()
+	if err != nil {
+		return nil, err
+	}
 
-	// Group files by applicant name
-	applicantFiles := make(map[string]*models.ApplicantDocument)
+	var applicantFiles []*applicantFile
 
 	for _, file := range files {
-		if file.IsDir() {
+		filename := file.name
+		ext := strings.ToLower(filepath.Ext(filename))
+
+		// Only process formats with a registered extractor
+		if !IsSupportedFile(filename) {
+			fh.skip(filename, "", "", fmt.Sprintf("unsupported file type: %s", ext))
 			continue
 		}
 
-		filename := file.Name()
-		ext := strings.ToLower(filepath.Ext(filename))
+		applicantName := file.folder
+		if applicantName == "" {
+			applicantName = applicantNameFromFile(filename)
+		}
+
+		filePath := file.path
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+		}
+
+		// Convert binary and markup formats (PDF, DOCX, RTF, HTML, ...) to plain text
+		extraction, err := ExtractDocument(filePath, content)
+		if err != nil {
+			log.Printf("WARNING: Failed to extract text from %s: %v", filename, err)
+			log.Printf("Skipping file: %s", filename)
+			fh.skip(filename, applicantName, "", fmt.Sprintf("text extraction failed: %v", err))
+			continue
+		}
+
+		classification := ClassifyDocument(filename, extraction.Text)
+		log.Printf("Classified %s as %s (%s)", filename, classification.Type, classification.Reason)
 
-		// Only process PDF and TXT files
-		if ext != ".pdf" && ext != ".txt" && ext != ".doc" && ext != ".docx" {
+		if classification.Type == DocumentTypeUnknown {
+			fh.skip(filename, applicantName, string(classification.Type), "could not classify as CV or cover letter: "+classification.Reason)
 			continue
 		}
+		applicantFiles = append(applicantFiles, newApplicantFile(filename, filePath, file.folder, classification.Type, extraction, file.modTime))
+	}
+
+	// Group files by person (email, phone, name, CV content) rather than by file name alone
+	documents := make([]models.ApplicantDocument, 0)
+	var cvFiles []*applicantFile
+	usedNames := make(map[string]int)
+
+	for _, group := range resolveApplicants(applicantFiles) {
+		name := applicantDisplayName(group)
+		// Different people can share a name; keep applicant names unique in the report
+		usedNames[name]++
+		if n := usedNames[name]; n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
 
-		// Extract applicant name from filename
-		// Convention: "Name_CV.pdf" or "Name_CoverLetter.pdf"
-		baseName := strings.TrimSuffix(filename, ext)
-		parts := strings.Split(baseName, "_")
+		var cvs, letters, supporting []*applicantFile
+		for _, f := range group {
+			switch f.docType {
+			case models.DocumentCV:
+				cvs = append(cvs, f)
+			case models.DocumentCoverLetter:
+				letters = append(letters, f)
+			default:
+				supporting = append(supporting, f)
+			}
+		}
 
-		if len(parts) < 2 {
+		if len(cvs) == 0 { // Only include applicants with at least a CV
+			for _, f := range group {
+				fh.skip(f.filename, name, string(f.docType), "no CV found for applicant")
+			}
 			continue
 		}
 
-		applicantName := parts[0]
-		docType := strings.ToLower(strings.Join(parts[1:], "_"))
+		doc := models.ApplicantDocument{Name: name}
+		newestFirst(cvs)
+		newestFirst(letters)
+		for _, f := range cvs[1:] {
+			fh.skip(f.filename, name, string(f.docType), supersededReason(cvs[0], f))
+		}
+		kept := []*applicantFile{cvs[0]}
+		if len(letters) > 0 {
+			for _, f := range letters[1:] {
+				fh.skip(f.filename, name, string(f.docType), supersededReason(letters[0], f))
+			}
+			kept = append(kept, letters[0])
+		}
 
-		if applicantFiles[applicantName] == nil {
-			applicantFiles[applicantName] = &models.ApplicantDocument{
-				Name: applicantName,
+		// Any number of portfolios, certificates, references and transcripts; only exact copies are dropped
+		seen := make(map[string]string)
+		for _, f := range supporting {
+			if original, ok := seen[f.text]; ok {
+				fh.skip(f.filename, name, string(f.docType), "duplicate of "+original)
+				continue
 			}
+			seen[f.text] = f.filename
+			kept = append(kept, f)
 		}
 
-		filePath := filepath.Join(fh.uploadsDir, filename)
-		content, err := os.ReadFile(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+		for _, f := range kept {
+			doc.Documents = append(doc.Documents, models.Document{
+				Type:    string(f.docType),
+				Path:    f.path,
+				Content: f.text,
+			})
 		}
 
-		// Convert to string and check if it's binary data
-		contentStr := string(content)
+		for _, f := range group {
+			doc.Emails = appendUnique(doc.Emails, f.emails...)
+			doc.Phones = appendUnique(doc.Phones, f.phones...)
+		}
 
-		// Try to extract text from PDFs/DOCX if binary
-		if IsBinaryData(contentStr) {
-			extractedText, err := ExtractText(filePath)
-			if err != nil {
-				log.Printf("WARNING: Failed to extract text from %s: %v", filename, err)
-				log.Printf("Skipping binary file: %s", filename)
-				continue // Skip this file entirely
+		// Scanned documents carry per-page OCR confidence; weak pages need a human check
+		for _, f := range kept {
+			if f.ocr != nil {
+				recordOCR(&doc, f.filename, f.ocr)
 			}
-			contentStr = extractedText
 		}
 
-		// Determine if it's a CV or cover letter
-		if strings.Contains(docType, "cv") || strings.Contains(docType, "resume") {
-			applicantFiles[applicantName].CVContent = contentStr
-			applicantFiles[applicantName].CVPath = filePath
-		} else if strings.Contains(docType, "cover") || strings.Contains(docType, "letter") || strings.Contains(docType, "cl") {
-			applicantFiles[applicantName].CLContent = contentStr
-			applicantFiles[applicantName].CLPath = filePath
+		if len(group) > 1 {
+			log.Printf("Resolved %d files to applicant %s", len(group), name)
 		}
+		documents = append(documents, doc)
+		cvFiles = append(cvFiles, cvs[0])
 	}
 
-	// Convert map to slice
-	documents := make([]models.ApplicantDocument, 0, len(applicantFiles))
-	for _, doc := range applicantFiles {
-		if doc.CVContent != "" { // Only include applicants with at least a CV
-			documents = append(documents, *doc)
+	flagSimilarCVs(documents, cvFiles)
+
+	return documents, nil
+}
+
+// flagSimilarCVs marks applicants whose CVs are nearly identical to another applicant's
+// (a shared template or copied content) for manual review
+func flagSimilarCVs(documents []models.ApplicantDocument, cvFiles []*applicantFile) {
+	for i := range documents {
+		for j := i + 1; j < len(documents); j++ {
+			similarity := shingleSimilarity(cvFiles[i].shingles, cvFiles[j].shingles)
+			if similarity < nearDuplicateCVSimilarity {
+				continue
+			}
+			log.Printf("WARNING: CVs of %s and %s are %.0f%% similar", documents[i].Name, documents[j].Name, similarity*100)
+			documents[i].ReviewReasons = append(documents[i].ReviewReasons,
+				fmt.Sprintf("CV is %.0f%% similar to %s's CV (possible shared template or copied content)", similarity*100, documents[j].Name))
+			documents[j].ReviewReasons = append(documents[j].ReviewReasons,
+				fmt.Sprintf("CV is %.0f%% similar to %s's CV (possible shared template or copied content)", similarity*100, documents[i].Name))
 		}
 	}
+}
 
-	return documents, nil
+// appendUnique appends values not already present in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// UnprocessedFiles returns the files the last LoadDocuments call did not score, with the reason
+func (fh *FileHandler) UnprocessedFiles() []models.UnprocessedFile {
+	return append([]models.UnprocessedFile(nil), fh.unprocessed...)
+}
+
+// skip records a file that will not be scored
+func (fh *FileHandler) skip(filename, applicant, docType, reason string) {
+	log.Printf("Not scoring %s: %s", filename, reason)
+	fh.unprocessed = append(fh.unprocessed, models.UnprocessedFile{
+		File:         filename,
+		Applicant:    applicant,
+		DocumentType: docType,
+		Reason:       reason,
+	})
+}
+
+// recordOCR stores per-page OCR confidence on the applicant and flags low-confidence documents for review
+func recordOCR(doc *models.ApplicantDocument, filename string, result *OCRResult) {
+	for _, page := range result.Pages {
+		doc.OCRPages = append(doc.OCRPages, models.OCRPage{
+			Document:   filename,
+			Page:       page.Number,
+			Confidence: page.Confidence,
+		})
+	}
+
+	low := result.LowConfidencePages(OCRLowConfidenceThreshold)
+	if len(low) == 0 {
+		return
+	}
+
+	pages := make([]string, 0, len(low))
+	for _, page := range low {
+		pages = append(pages, fmt.Sprintf("%d (%.0f%%)", page.Number, page.Confidence))
+	}
+	reason := fmt.Sprintf("Low OCR confidence in %s: page %s", filename, strings.Join(pages, ", "))
+	log.Printf("WARNING: %s", reason)
+	doc.ReviewReasons = append(doc.ReviewReasons, reason)
 }
 
 // ClearUploads removes all files from the uploads directory
@@ -54,6 +54,50 @@ func TestSaveUploadedFile(t *testing.T) {
 	}
 }
 
+// TestSaveUploadedFile_KeepsEarlierFiles tests that an upload with the name of
+// an earlier one is saved under a new name
+func TestSaveUploadedFile_KeepsEarlierFiles(t *testing.T) {
+	fh := NewFileHandler(t.TempDir())
+
+	first, err := fh.SaveUploadedFile("cv.txt", strings.NewReader("Jane Smith CV"))
+	if err != nil {
+		t.Fatalf("SaveUploadedFile() returned error: %v", err)
+	}
+	second, err := fh.SaveUploadedFile("cv.txt", strings.NewReader("John Doe CV"))
+	if err != nil {
+		t.Fatalf("SaveUploadedFile() returned error: %v", err)
+	}
+
+	if filepath.Base(second) != "cv_2.txt" {
+		t.Errorf("Expected cv_2.txt, got %s", filepath.Base(second))
+	}
+	if data, _ := os.ReadFile(first); string(data) != "Jane Smith CV" {
+		t.Errorf("Earlier upload was overwritten: %q", data)
+	}
+}
+
+// TestSaveUploadedFile_PathTraversal tests that upload names cannot escape the uploads directory
+func TestSaveUploadedFile_PathTraversal(t *testing.T) {
+	root := t.TempDir()
+	uploadsDir := filepath.Join(root, "uploads")
+	fh := NewFileHandler(uploadsDir)
+
+	path, err := fh.SaveUploadedFile("../../escaped_cv.txt", strings.NewReader("CV"))
+	if err != nil {
+		t.Fatalf("SaveUploadedFile() returned error: %v", err)
+	}
+	if path != filepath.Join(uploadsDir, "escaped_cv.txt") {
+		t.Errorf("Expected the file in the uploads directory, got %s", path)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped_cv.txt")); !os.IsNotExist(err) {
+		t.Error("File was written outside the uploads directory")
+	}
+
+	if _, err := fh.SaveUploadedFile("..", strings.NewReader("CV")); err == nil {
+		t.Error("Expected an error for an empty file name")
+	}
+}
+
 func TestLoadDocuments(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir := filepath.Join(os.TempDir(), "cv_review_test_load")
@@ -83,15 +127,82 @@ func TestLoadDocuments(t *testing.T) {
 		t.Errorf("Expected name 'JohnDoe', got '%s'", doc.Name)
 	}
 
-	if doc.CVContent != string(cvContent) {
+	if doc.CV().Content != string(cvContent) {
 		t.Errorf("CV content mismatch")
 	}
 
-	if doc.CLContent != string(clContent) {
+	if doc.CoverLetter().Content != string(clContent) {
 		t.Errorf("Cover letter content mismatch")
 	}
 }
 
+// TestLoadDocuments_ApplicantFolders tests the per-applicant folder layout with several supporting documents
+func TestLoadDocuments_ApplicantFolders(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"Jane Smith/certificates", "Mary Jones", ".cache"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+	}
+	writeTestFiles(t, tmpDir, [][2]string{
+		{"Jane Smith/cv.txt", testCV("Jane Smith", "jane@example.com", testExperience)},
+		{"Jane Smith/letter.txt", testCoverLetterText},
+		{"Jane Smith/portfolio.txt", "Portfolio\nSelected projects and case studies: payments dashboard, design system."},
+		{"Jane Smith/certificates/kubernetes.txt", testCertificateText},
+		{"Jane Smith/certificates/terraform.txt", strings.Replace(testCertificateText, "Kubernetes", "Terraform", 1)},
+		{"Jane Smith/certificates/kubernetes_copy.txt", testCertificateText},
+		{"Jane Smith/reference.txt", testReferenceText},
+		{"Jane Smith/notes.txt", "Meeting at 10am tomorrow"},
+		{"Mary Jones/document.txt", testCV("Mary Jones", "mary@example.com", "Initech, Tester, 2012 - 2019\nAutomated regression suites.")},
+		{"Mary Jones/transcript.txt", testTranscriptText},
+		{".cache/state.txt", "ignored"},
+		// Top-level files keep working alongside folders
+		{"JohnDoe_CV.txt", testCV("John Doe", "john@example.com", "Northwind, Analyst, 2015 - 2020\nModelled demand for retail stores.")},
+	})
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	byName := make(map[string]int)
+	for i, doc := range docs {
+		byName[doc.Name] = i
+	}
+	if len(docs) != 3 {
+		t.Fatalf("Expected 3 applicants, got %v", byName)
+	}
+
+	jane, ok := byName["Jane Smith"]
+	if !ok {
+		t.Fatalf("Folder name should be used as applicant name, got %v", byName)
+	}
+	if filepath.Base(docs[jane].CV().Path) != "cv.txt" || filepath.Base(docs[jane].CoverLetter().Path) != "letter.txt" {
+		t.Errorf("Jane Smith's CV and letter not assigned, got %s and %s", docs[jane].CV().Path, docs[jane].CoverLetter().Path)
+	}
+	var types []string
+	for _, sd := range docs[jane].AdditionalDocuments() {
+		types = append(types, sd.Type+":"+filepath.Base(sd.Path))
+	}
+	want := "certificate:kubernetes.txt,certificate:terraform.txt,portfolio:portfolio.txt,reference:reference.txt"
+	if strings.Join(types, ",") != want {
+		t.Errorf("Expected supporting documents %s, got %v", want, types)
+	}
+
+	mary, ok := byName["Mary Jones"]
+	if !ok || len(docs[mary].AdditionalDocuments()) != 1 || docs[mary].AdditionalDocuments()[0].Type != "transcript" {
+		t.Errorf("Mary Jones should have her CV and transcript, got %+v", docs)
+	}
+
+	reasons := make(map[string]string)
+	for _, u := range fh.UnprocessedFiles() {
+		reasons[u.File] = u.Applicant + ": " + u.Reason
+	}
+	if len(reasons) != 2 || !strings.HasPrefix(reasons["Jane Smith/notes.txt"], "Jane Smith: could not classify") ||
+		!strings.Contains(reasons["Jane Smith/certificates/kubernetes_copy.txt"], "duplicate of Jane Smith/certificates/kubernetes.txt") {
+		t.Errorf("Expected the unclassified note and the duplicate certificate to be reported, got %v", reasons)
+	}
+}
+
 func TestClearUploads(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir := filepath.Join(os.TempDir(), "cv_review_test_clear")
@@ -0,0 +1,464 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+const (
+	// gmailCredentialsFile is the OAuth client downloaded from the Google Cloud Console
+	gmailCredentialsFile = "credentials.json"
+	// legacyGmailTokenFile is where tokens were kept before they were stored per account
+	legacyGmailTokenFile = "token.json"
+	// gmailTokensDir is the directory under the config directory holding one token per account
+	gmailTokensDir = "gmail_tokens"
+
+	// GmailCallbackPath is the redirect path of the Gmail OAuth flow
+	GmailCallbackPath = "/oauth/gmail/callback"
+	// gmailAuthTimeout bounds how long an authorization request stays valid
+	gmailAuthTimeout = 10 * time.Minute
+)
+
+// ErrGmailNotAuthorized is returned when no Gmail account has granted the needed access
+var ErrGmailNotAuthorized = errors.New("gmail access is not authorized")
+
+// GmailScopes returns the OAuth scopes to request: read access, plus label and
+// compose access when followUp is set
+func GmailScopes(followUp bool) []string {
+	if followUp {
+		return gmailFollowUpScopes
+	}
+	return gmailFetchScopes
+}
+
+// storedToken is the token file format; Scopes records what the user granted
+type storedToken struct {
+	oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// GmailTokenStore keeps one OAuth token per Gmail account, readable only by the owner
+type GmailTokenStore struct {
+	dir string
+}
+
+// NewGmailTokenStore returns a token store in dir, or in the config directory if dir is empty
+func NewGmailTokenStore(dir string) *GmailTokenStore {
+	return &GmailTokenStore{dir: dir}
+}
+
+// directory returns the store's directory, creating it with owner-only permissions
+func (s *GmailTokenStore) directory() (string, error) {
+	dir := s.dir
+	if dir == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(configDir, gmailTokensDir)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	return dir, nil
+}
+
+// tokenPath returns the file holding the token of account
+func (s *GmailTokenStore) tokenPath(account string) (string, error) {
+	name := sanitizeFilename(strings.ToLower(strings.TrimSpace(account)))
+	if name == "" || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid Gmail account: %q", account)
+	}
+	dir, err := s.directory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Load returns the saved token of account, or ErrGmailNotAuthorized if there is none
+func (s *GmailTokenStore) Load(account string) (*storedToken, error) {
+	path, err := s.tokenPath(account)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := tokenFromFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: no token for %s", ErrGmailNotAuthorized, account)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token for %s: %w", account, err)
+	}
+	return tok, nil
+}
+
+// Save writes the token of account and the scopes it was granted for
+func (s *GmailTokenStore) Save(account string, token *oauth2.Token, scopes []string) error {
+	path, err := s.tokenPath(account)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(storedToken{Token: *token, Scopes: scopes})
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	// Write a private temporary file and rename it so a crash never leaves half a token
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// Accounts lists the Gmail accounts with a saved token
+func (s *GmailTokenStore) Accounts() ([]string, error) {
+	dir, err := s.directory()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	var accounts []string
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && !strings.HasPrefix(name, ".") && filepath.Ext(name) == ".json" {
+			accounts = append(accounts, strings.TrimSuffix(name, ".json"))
+		}
+	}
+	sort.Strings(accounts)
+	return accounts, nil
+}
+
+// Resolve returns account, or the only authorized account if account is empty
+func (s *GmailTokenStore) Resolve(account string) (string, error) {
+	if account = strings.ToLower(strings.TrimSpace(account)); account != "" {
+		return account, nil
+	}
+	accounts, err := s.Accounts()
+	if err != nil {
+		return "", err
+	}
+	switch len(accounts) {
+	case 0:
+		return "", ErrGmailNotAuthorized
+	case 1:
+		return accounts[0], nil
+	default:
+		return "", fmt.Errorf("several Gmail accounts are authorized (%s); choose one", strings.Join(accounts, ", "))
+	}
+}
+
+// tokenFromFile retrieves a token and its granted scopes from a local file
+func tokenFromFile(file string) (*storedToken, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var stored storedToken
+	if err := json.NewDecoder(f).Decode(&stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// savingTokenSource saves refreshed tokens so the next run does not start from an expired one
+type savingTokenSource struct {
+	base    oauth2.TokenSource
+	store   *GmailTokenStore
+	account string
+	scopes  []string
+
+	mu   sync.Mutex
+	last string
+}
+
+// Token returns a valid token, saving it whenever it was refreshed
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tok.AccessToken != s.last {
+		s.last = tok.AccessToken
+		if err := s.store.Save(s.account, tok, s.scopes); err != nil {
+			log.Printf("Failed to save refreshed Gmail token: %v", err)
+		}
+	}
+	return tok, nil
+}
+
+// gmailOAuthConfig reads the OAuth client from credentialsPath
+func gmailOAuthConfig(credentialsPath string, scopes []string, redirectURL string) (*oauth2.Config, error) {
+	b, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials file: %w", err)
+	}
+	cfg, err := google.ConfigFromJSON(b, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse credentials: %w", err)
+	}
+	if redirectURL != "" {
+		cfg.RedirectURL = redirectURL
+	}
+	return cfg, nil
+}
+
+// gmailTokenSource returns an authorized, self-saving token source for account
+// (or the only authorized account), checking it was granted scopes. A token.json
+// left by earlier versions is moved into the store the first time.
+func gmailTokenSource(ctx context.Context, store *GmailTokenStore, account string, scopes []string) (oauth2.TokenSource, string, error) {
+	cfg, err := gmailOAuthConfig(gmailCredentialsFile, scopes, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	account, err = store.Resolve(account)
+	if errors.Is(err, ErrGmailNotAuthorized) {
+		account, err = migrateLegacyToken(ctx, store, cfg)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	stored, err := store.Load(account)
+	if err != nil {
+		return nil, "", err
+	}
+	if !scopesCovered(stored.Scopes, scopes) {
+		return nil, "", fmt.Errorf("%w: %s has not granted the permissions for labels and replies", ErrGmailNotAuthorized, account)
+	}
+
+	ts := &savingTokenSource{
+		base:    oauth2.ReuseTokenSource(&stored.Token, cfg.TokenSource(ctx, &stored.Token)),
+		store:   store,
+		account: account,
+		scopes:  stored.Scopes,
+		last:    stored.AccessToken,
+	}
+	return ts, account, nil
+}
+
+// migrateLegacyToken moves token.json from the working directory into the store
+func migrateLegacyToken(ctx context.Context, store *GmailTokenStore, cfg *oauth2.Config) (string, error) {
+	legacy, err := tokenFromFile(legacyGmailTokenFile)
+	if err != nil {
+		return "", ErrGmailNotAuthorized
+	}
+
+	account, err := gmailAccount(ctx, cfg.TokenSource(ctx, &legacy.Token))
+	if err != nil {
+		return "", fmt.Errorf("failed to identify the account of %s: %w", legacyGmailTokenFile, err)
+	}
+	if err := store.Save(account, &legacy.Token, legacy.Scopes); err != nil {
+		return "", err
+	}
+	if err := os.Remove(legacyGmailTokenFile); err != nil {
+		log.Printf("Failed to remove %s: %v", legacyGmailTokenFile, err)
+	}
+	log.Printf("Moved %s into the token store for %s", legacyGmailTokenFile, account)
+	return account, nil
+}
+
+// gmailAccount returns the address of the Gmail account a token belongs to
+func gmailAccount(ctx context.Context, ts oauth2.TokenSource, opts ...option.ClientOption) (string, error) {
+	srv, err := gmail.NewService(ctx, append([]option.ClientOption{option.WithTokenSource(ts)}, opts...)...)
+	if err != nil {
+		return "", fmt.Errorf("unable to create Gmail client: %w", err)
+	}
+	profile, err := srv.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to read Gmail profile: %w", err)
+	}
+	return strings.ToLower(profile.EmailAddress), nil
+}
+
+// GmailAuthenticator runs the redirect-based OAuth flow that authorizes Gmail accounts
+type GmailAuthenticator struct {
+	credentialsPath string
+	store           *GmailTokenStore
+	// serviceOptions point the profile lookup elsewhere in tests
+	serviceOptions []option.ClientOption
+
+	mu      sync.Mutex
+	pending map[string]gmailAuthRequest
+}
+
+// gmailAuthRequest is an authorization waiting for its callback
+type gmailAuthRequest struct {
+	config  *oauth2.Config
+	expires time.Time
+}
+
+// NewGmailAuthenticator creates an authenticator for the OAuth client in credentialsPath
+func NewGmailAuthenticator(credentialsPath string, store *GmailTokenStore) *GmailAuthenticator {
+	return &GmailAuthenticator{
+		credentialsPath: credentialsPath,
+		store:           store,
+		pending:         make(map[string]gmailAuthRequest),
+	}
+}
+
+// Start returns the Google consent page URL for scopes; Google sends the user
+// back to redirectURL, whose handler calls Complete. loginHint may name the account.
+func (ga *GmailAuthenticator) Start(redirectURL string, scopes []string, loginHint string) (string, error) {
+	cfg, err := gmailOAuthConfig(ga.credentialsPath, scopes, redirectURL)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create OAuth state: %w", err)
+	}
+	state := base64.RawURLEncoding.EncodeToString(b)
+
+	ga.mu.Lock()
+	now := time.Now()
+	for key, req := range ga.pending {
+		if now.After(req.expires) {
+			delete(ga.pending, key)
+		}
+	}
+	ga.pending[state] = gmailAuthRequest{config: cfg, expires: now.Add(gmailAuthTimeout)}
+	ga.mu.Unlock()
+
+	opts := []oauth2.AuthCodeOption{
+		oauth2.AccessTypeOffline,
+		oauth2.ApprovalForce, // always return a refresh token
+		oauth2.SetAuthURLParam("include_granted_scopes", "true"),
+	}
+	if loginHint != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("login_hint", loginHint))
+	}
+	return cfg.AuthCodeURL(state, opts...), nil
+}
+
+// Complete exchanges the code Google returned for a token, saves it under the
+// account's address and returns the address
+func (ga *GmailAuthenticator) Complete(ctx context.Context, state, code string) (string, error) {
+	ga.mu.Lock()
+	req, ok := ga.pending[state]
+	delete(ga.pending, state)
+	ga.mu.Unlock()
+	if !ok || time.Now().After(req.expires) {
+		return "", fmt.Errorf("unknown or expired authorization request; please start again")
+	}
+	if code == "" {
+		return "", fmt.Errorf("authorization was not granted")
+	}
+
+	tok, err := req.config.Exchange(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("unable to exchange authorization code: %w", err)
+	}
+
+	account, err := gmailAccount(ctx, req.config.TokenSource(ctx, tok), ga.serviceOptions...)
+	if err != nil {
+		return "", err
+	}
+
+	// Google reports the granted scopes, including ones granted earlier
+	scopes := req.config.Scopes
+	if granted, ok := tok.Extra("scope").(string); ok && granted != "" {
+		scopes = strings.Fields(granted)
+	}
+	if err := ga.store.Save(account, tok, scopes); err != nil {
+		return "", err
+	}
+	log.Printf("Authorized Gmail account %s", account)
+	return account, nil
+}
+
+// AuthorizeGmailLocal authorizes a Gmail account from a desktop application:
+// the consent page redirects to a temporary server on the loopback interface.
+// openURL (e.g. a browser launcher) is called with the consent page URL.
+func AuthorizeGmailLocal(ctx context.Context, scopes []string, openURL func(string) error) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start OAuth callback listener: %w", err)
+	}
+
+	ga := NewGmailAuthenticator(gmailCredentialsFile, NewGmailTokenStore(""))
+	authURL, err := ga.Start("http://"+listener.Addr().String()+GmailCallbackPath, scopes, "")
+	if err != nil {
+		listener.Close()
+		return "", err
+	}
+
+	type result struct {
+		account string
+		err     error
+	}
+	done := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+GmailCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		account, err := ga.Complete(r.Context(), r.FormValue("state"), r.FormValue("code"))
+		if err != nil {
+			http.Error(w, "Gmail authorization failed: "+err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, "Gmail account %s authorized. You can close this window.", account)
+		}
+		select {
+		case done <- result{account, err}:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	log.Printf("Open this link to authorize Gmail access: %s", authURL)
+	if openURL != nil {
+		if err := openURL(authURL); err != nil {
+			log.Printf("Failed to open browser: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gmailAuthTimeout)
+	defer cancel()
+	select {
+	case res := <-done:
+		return res.account, res.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("gmail authorization was not completed: %w", ctx.Err())
+	}
+}
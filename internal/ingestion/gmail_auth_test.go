@@ -0,0 +1,123 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+)
+
+// TestGmailTokenStore tests per-account storage, permissions and account selection
+func TestGmailTokenStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tokens")
+	store := NewGmailTokenStore(dir)
+
+	if _, err := store.Resolve(""); !errors.Is(err, ErrGmailNotAuthorized) {
+		t.Errorf("Expected ErrGmailNotAuthorized for an empty store, got %v", err)
+	}
+	if _, err := store.Load("jobs@example.com"); !errors.Is(err, ErrGmailNotAuthorized) {
+		t.Errorf("Expected ErrGmailNotAuthorized for a missing token, got %v", err)
+	}
+
+	if err := store.Save("Jobs@Example.com", &oauth2.Token{AccessToken: "at", RefreshToken: "rt"}, gmailFetchScopes); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "jobs@example.com.json"))
+	if err != nil {
+		t.Fatalf("Token file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Token file permissions = %v, want 0600", info.Mode().Perm())
+	}
+	if dirInfo, _ := os.Stat(dir); dirInfo.Mode().Perm() != 0700 {
+		t.Errorf("Token directory permissions = %v, want 0700", dirInfo.Mode().Perm())
+	}
+
+	stored, err := store.Load("jobs@example.com")
+	if err != nil || stored.RefreshToken != "rt" || len(stored.Scopes) != 1 {
+		t.Fatalf("Load() = %+v, %v", stored, err)
+	}
+	if account, err := store.Resolve(""); err != nil || account != "jobs@example.com" {
+		t.Errorf("Resolve() = %q, %v; want the only account", account, err)
+	}
+
+	store.Save("hr@example.com", &oauth2.Token{AccessToken: "at2"}, nil)
+	if _, err := store.Resolve(""); err == nil {
+		t.Error("Expected an error when several accounts are authorized")
+	}
+	for _, account := range []string{"", "..", "../escape"} {
+		if _, err := store.tokenPath(account); err == nil {
+			t.Errorf("Expected an error for account %q", account)
+		}
+	}
+}
+
+// TestGmailAuthenticator tests the redirect flow against fake Google endpoints
+func TestGmailAuthenticator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			if r.FormValue("code") != "good-code" {
+				http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "at", "refresh_token": "rt", "token_type": "Bearer", "expires_in": 3600, "scope": %q}`,
+				gmailFollowUpScopes[0]+" "+gmailFollowUpScopes[1])
+		case "/gmail/v1/users/me/profile":
+			fmt.Fprint(w, `{"emailAddress": "Jobs@Example.com"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	credentials := filepath.Join(t.TempDir(), "credentials.json")
+	os.WriteFile(credentials, []byte(fmt.Sprintf(`{"web": {"client_id": "id", "client_secret": "secret",
+		"auth_uri": "https://accounts.example.com/auth", "token_uri": %q,
+		"redirect_uris": ["https://cv.example.com/oauth/gmail/callback"]}}`, server.URL+"/token")), 0600)
+
+	store := NewGmailTokenStore(t.TempDir())
+	ga := NewGmailAuthenticator(credentials, store)
+	ga.serviceOptions = []option.ClientOption{option.WithEndpoint(server.URL + "/")}
+
+	authURL, err := ga.Start("https://cv.example.com"+GmailCallbackPath, gmailFollowUpScopes, "jobs@example.com")
+	if err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if q.Get("redirect_uri") != "https://cv.example.com"+GmailCallbackPath || q.Get("access_type") != "offline" || q.Get("login_hint") != "jobs@example.com" {
+		t.Errorf("Unexpected consent URL: %s", authURL)
+	}
+	state := q.Get("state")
+
+	if _, err := ga.Complete(context.Background(), "forged", "good-code"); err == nil {
+		t.Error("Expected an unknown state to be rejected")
+	}
+
+	account, err := ga.Complete(context.Background(), state, "good-code")
+	if err != nil {
+		t.Fatalf("Complete() returned error: %v", err)
+	}
+	if account != "jobs@example.com" {
+		t.Errorf("Complete() account = %q", account)
+	}
+	stored, err := store.Load(account)
+	if err != nil || stored.RefreshToken != "rt" || !scopesCovered(stored.Scopes, gmailFollowUpScopes) {
+		t.Errorf("Unexpected stored token: %+v, %v", stored, err)
+	}
+
+	// A state can only be used once
+	if _, err := ga.Complete(context.Background(), state, "good-code"); err == nil {
+		t.Error("Expected a reused state to be rejected")
+	}
+}
@@ -0,0 +1,384 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+	"google.golang.org/api/gmail/v1"
+)
+
+// gmailBatchModifyLimit is the most message IDs one batchModify call accepts
+const gmailBatchModifyLimit = 1000
+
+// DefaultGmailReplyTemplate acknowledges an application without revealing its score
+const DefaultGmailReplyTemplate = `Dear {{.Name}},
+
+Thank you for applying for the {{.JobTitle}} position. We have received your application and will be in touch about the next steps.
+
+Kind regards`
+
+// DefaultGmailLabelRules labels every scored application and shortlists the top five
+func DefaultGmailLabelRules() []models.GmailLabelRule {
+	return []models.GmailLabelRule{
+		{Label: "CV-Review/Scored"},
+		{Label: "CV-Review/Shortlisted", TopN: 5},
+	}
+}
+
+// GmailFollowUpSummary counts the actions taken by FollowUp
+type GmailFollowUpSummary struct {
+	Labelled int `json:"labelled"` // messages that received at least one label
+	Replies  int `json:"replies"`  // drafts created or replies sent
+	// Skipped counts applicants whose documents did not come from Gmail, who
+	// were already followed up for the job, or whose forwarded application does
+	// not name an address to reply to
+	Skipped int `json:"skipped"`
+}
+
+// gmailFollowUpState records the follow-up done for one job, so running it again
+// does not answer the same applicant twice
+type gmailFollowUpState struct {
+	Labels  map[string][]string `json:"labels"`  // message ID -> labels applied
+	Replies map[string]string   `json:"replies"` // application message ID -> draft or sent message ID
+}
+
+// replyTemplateData is passed to the reply template
+type replyTemplateData struct {
+	Name     string
+	JobTitle string
+}
+
+// ValidateGmailFollowUp checks the reply mode, labels and template of a follow-up
+func ValidateGmailFollowUp(followUp models.GmailFollowUp) error {
+	switch followUp.Reply {
+	case "", models.GmailReplyDraft, models.GmailReplySend:
+	default:
+		return fmt.Errorf("gmail follow-up reply must be %q or %q", models.GmailReplyDraft, models.GmailReplySend)
+	}
+	for _, rule := range followUp.Labels {
+		if strings.TrimSpace(rule.Label) == "" {
+			return fmt.Errorf("gmail follow-up label rules need a label name")
+		}
+	}
+	if followUp.ReplyTemplate != "" {
+		if _, err := template.New("reply").Parse(followUp.ReplyTemplate); err != nil {
+			return fmt.Errorf("invalid reply template: %w", err)
+		}
+	}
+	return nil
+}
+
+// FollowUp labels and acknowledges the Gmail messages the ranked applicants'
+// documents were downloaded from. Labels and replies already recorded for the
+// job are not repeated, so applicants with nothing left to do are skipped.
+func (gh *GmailHandler) FollowUp(ctx context.Context, results []models.ApplicantResult, jobTitle string, followUp models.GmailFollowUp) (GmailFollowUpSummary, error) {
+	var summary GmailFollowUpSummary
+	if err := ValidateGmailFollowUp(followUp); err != nil {
+		return summary, err
+	}
+
+	user := "me"
+	messageFiles := loadGmailMessages(gh.uploadsDir)
+	states := loadGmailFollowUps(gh.uploadsDir)
+	state := states[jobTitle]
+	if state == nil {
+		state = &gmailFollowUpState{Labels: make(map[string][]string), Replies: make(map[string]string)}
+		states[jobTitle] = state
+	}
+	defer func() {
+		if err := saveGmailFollowUps(gh.uploadsDir, states); err != nil {
+			log.Printf("Warning: failed to record Gmail follow-up: %v", err)
+		}
+	}()
+
+	// Label name -> message IDs of the applicants the rule matches
+	labelled := make(map[string]bool)
+	labelMessages := make(map[string][]string)
+	var toReply []models.ApplicantResult
+	for _, result := range results {
+		messages := resultMessages(result, messageFiles)
+		if len(messages) == 0 {
+			summary.Skipped++
+			continue
+		}
+		ids := messageIDs(messages)
+
+		pending := false
+		for _, rule := range followUp.Labels {
+			if !rule.Matches(result) {
+				continue
+			}
+			for _, id := range ids {
+				if !slices.Contains(state.Labels[id], rule.Label) {
+					labelMessages[rule.Label] = append(labelMessages[rule.Label], id)
+					labelled[id] = true
+					pending = true
+				}
+			}
+		}
+		skipped := false
+		if followUp.Reply != "" && !state.replied(ids) {
+			// The forwarder is a colleague, not the applicant
+			if messages[0].Forwarded && messages[0].ReplyTo == "" {
+				log.Printf("Not replying to %s: the forwarded application has no sender address", result.Name)
+				skipped = true
+			} else {
+				toReply = append(toReply, result)
+				pending = true
+			}
+		}
+		if !pending || skipped {
+			summary.Skipped++
+		}
+	}
+
+	if len(labelMessages) > 0 {
+		labelIDs, err := gh.ensureLabels(user, followUp.Labels)
+		if err != nil {
+			return summary, err
+		}
+		for _, rule := range followUp.Labels {
+			if len(labelMessages[rule.Label]) == 0 {
+				continue
+			}
+			if err := gh.applyLabel(ctx, user, labelIDs[rule.Label], labelMessages[rule.Label]); err != nil {
+				return summary, fmt.Errorf("failed to apply label %s: %w", rule.Label, err)
+			}
+			for _, id := range labelMessages[rule.Label] {
+				state.Labels[id] = append(state.Labels[id], rule.Label)
+			}
+		}
+		summary.Labelled = len(labelled)
+	}
+
+	if len(toReply) > 0 {
+		text := followUp.ReplyTemplate
+		if text == "" {
+			text = DefaultGmailReplyTemplate
+		}
+		tmpl, err := template.New("reply").Parse(text)
+		if err != nil {
+			return summary, fmt.Errorf("invalid reply template: %w", err)
+		}
+
+		// One reply per applicant, even if they sent several emails
+		for _, result := range toReply {
+			if err := ctx.Err(); err != nil {
+				return summary, err
+			}
+			message := resultMessages(result, messageFiles)[0]
+			replyID, err := gh.reply(user, message, result.Name, jobTitle, followUp.Reply, tmpl)
+			if err != nil {
+				log.Printf("Failed to reply to %s: %v", result.Name, err)
+				continue
+			}
+			state.Replies[message.MessageID] = replyID
+			summary.Replies++
+		}
+	}
+
+	log.Printf("Gmail follow-up: %d messages labelled, %d replies, %d applicants skipped",
+		summary.Labelled, summary.Replies, summary.Skipped)
+	return summary, nil
+}
+
+// replied reports whether one of an applicant's messages has already been answered
+func (s *gmailFollowUpState) replied(messageIDs []string) bool {
+	for _, id := range messageIDs {
+		if s.Replies[id] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadGmailFollowUps reads the follow-up state of each job title from the uploads directory
+func loadGmailFollowUps(uploadsDir string) map[string]*gmailFollowUpState {
+	states := make(map[string]*gmailFollowUpState)
+	data, err := os.ReadFile(filepath.Join(uploadsDir, gmailFollowUpFile))
+	if err != nil {
+		return states
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		log.Printf("Ignoring unreadable %s: %v", gmailFollowUpFile, err)
+		return make(map[string]*gmailFollowUpState)
+	}
+	for _, state := range states {
+		if state.Labels == nil {
+			state.Labels = make(map[string][]string)
+		}
+		if state.Replies == nil {
+			state.Replies = make(map[string]string)
+		}
+	}
+	return states
+}
+
+// saveGmailFollowUps records the follow-up state of each job title
+func saveGmailFollowUps(uploadsDir string, states map[string]*gmailFollowUpState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(uploadsDir, gmailFollowUpFile), data, 0644)
+}
+
+// resultMessages returns the distinct Gmail messages an applicant's documents came
+// from, the CV's message first
+func resultMessages(result models.ApplicantResult, messageFiles map[string]gmailMessageFile) []gmailMessageFile {
+	paths := []string{result.CVPath, result.CLPath}
+	for _, doc := range result.Documents {
+		paths = append(paths, doc.Path)
+	}
+
+	seen := make(map[string]bool)
+	var messages []gmailMessageFile
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if message := messageFiles[filepath.Base(path)]; message.MessageID != "" && !seen[message.MessageID] {
+			seen[message.MessageID] = true
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// messageIDs returns the IDs of messages
+func messageIDs(messages []gmailMessageFile) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.MessageID
+	}
+	return ids
+}
+
+// ensureLabels returns the IDs of the rules' labels, creating the ones that do not exist
+func (gh *GmailHandler) ensureLabels(user string, rules []models.GmailLabelRule) (map[string]string, error) {
+	existing, err := gh.service.Users.Labels.List(user).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list labels: %w", err)
+	}
+
+	ids := make(map[string]string)
+	for _, label := range existing.Labels {
+		ids[label.Name] = label.Id
+	}
+	for _, rule := range rules {
+		if ids[rule.Label] != "" {
+			continue
+		}
+		created, err := gh.service.Users.Labels.Create(user, &gmail.Label{
+			Name:                  rule.Label,
+			LabelListVisibility:   "labelShow",
+			MessageListVisibility: "show",
+		}).Do()
+		if err != nil {
+			return nil, fmt.Errorf("unable to create label %s: %w", rule.Label, err)
+		}
+		log.Printf("Created Gmail label: %s", rule.Label)
+		ids[rule.Label] = created.Id
+	}
+	return ids, nil
+}
+
+// applyLabel adds a label to messages in batches
+func (gh *GmailHandler) applyLabel(ctx context.Context, user, labelID string, messageIDs []string) error {
+	for start := 0; start < len(messageIDs); start += gmailBatchModifyLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+gmailBatchModifyLimit, len(messageIDs))
+		err := gh.service.Users.Messages.BatchModify(user, &gmail.BatchModifyMessagesRequest{
+			Ids:         messageIDs[start:end],
+			AddLabelIds: []string{labelID},
+		}).Do()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reply answers an application email in its thread, as a draft or sent directly,
+// and returns the ID of the draft or sent message. A forwarded application is
+// answered in a new thread at the original sender's address.
+func (gh *GmailHandler) reply(user string, message gmailMessageFile, applicantName, jobTitle, mode string, tmpl *template.Template) (string, error) {
+	original, err := gh.service.Users.Messages.Get(user, message.MessageID).Format("metadata").
+		MetadataHeaders("From", "Reply-To", "Subject", "Message-ID").Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve message: %w", err)
+	}
+
+	to := partHeader(original.Payload, "Reply-To")
+	if to == "" {
+		to = partHeader(original.Payload, "From")
+	}
+	subject, inReplyTo, threadID := partHeader(original.Payload, "Subject"), partHeader(original.Payload, "Message-ID"), original.ThreadId
+	if message.Forwarded {
+		to = message.ReplyTo
+		subject = strings.TrimSpace(forwardSubjectPattern.ReplaceAllString(subject, ""))
+		inReplyTo, threadID = "", ""
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return "", fmt.Errorf("no address to reply to: %w", err)
+	}
+
+	name := recipient.Name
+	if name == "" {
+		name = applicantName
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, replyTemplateData{Name: name, JobTitle: jobTitle}); err != nil {
+		return "", fmt.Errorf("failed to render reply: %w", err)
+	}
+
+	raw := buildReply(recipient, subject, inReplyTo, body.String())
+	msg := &gmail.Message{Raw: base64.URLEncoding.EncodeToString(raw), ThreadId: threadID}
+
+	if mode == models.GmailReplySend {
+		sent, err := gh.service.Users.Messages.Send(user, msg).Do()
+		if err != nil {
+			return "", fmt.Errorf("unable to send reply: %w", err)
+		}
+		return sent.Id, nil
+	}
+	draft, err := gh.service.Users.Drafts.Create(user, &gmail.Draft{Message: msg}).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to draft reply: %w", err)
+	}
+	return draft.Id, nil
+}
+
+// buildReply writes a plain text RFC 5322 reply that threads under the original message
+func buildReply(to *mail.Address, subject, messageID, body string) []byte {
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	if messageID != "" {
+		fmt.Fprintf(&b, "In-Reply-To: %s\r\nReferences: %s\r\n", messageID, messageID)
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
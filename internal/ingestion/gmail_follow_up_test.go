@@ -0,0 +1,322 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+// fakeGmail serves the parts of the Gmail API used for fetching and follow-up
+type fakeGmail struct {
+	mu       sync.Mutex
+	messages map[string]*gmail.Message
+	labels   []*gmail.Label
+	modified []gmail.BatchModifyMessagesRequest
+	drafts   []string // decoded raw messages
+	sent     []string
+}
+
+func (f *fakeGmail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me/")
+	switch {
+	case r.Method == http.MethodGet && path == "messages":
+		var list gmail.ListMessagesResponse
+		for id := range f.messages {
+			list.Messages = append(list.Messages, &gmail.Message{Id: id})
+		}
+		json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "messages/"):
+		msg, ok := f.messages[strings.TrimPrefix(path, "messages/")]
+		if !ok {
+			http.Error(w, `{"error": {"code": 404, "message": "not found"}}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(msg)
+	case r.Method == http.MethodGet && path == "labels":
+		json.NewEncoder(w).Encode(gmail.ListLabelsResponse{Labels: f.labels})
+	case r.Method == http.MethodPost && path == "labels":
+		var label gmail.Label
+		json.NewDecoder(r.Body).Decode(&label)
+		label.Id = "Label_" + label.Name
+		f.labels = append(f.labels, &label)
+		json.NewEncoder(w).Encode(label)
+	case r.Method == http.MethodPost && path == "messages/batchModify":
+		var req gmail.BatchModifyMessagesRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		f.modified = append(f.modified, req)
+	case r.Method == http.MethodPost && (path == "drafts" || path == "messages/send"):
+		var msg gmail.Message
+		if path == "drafts" {
+			var draft gmail.Draft
+			json.NewDecoder(r.Body).Decode(&draft)
+			msg = *draft.Message
+		} else {
+			json.NewDecoder(r.Body).Decode(&msg)
+		}
+		raw, _ := base64.URLEncoding.DecodeString(msg.Raw)
+		if path == "drafts" {
+			f.drafts = append(f.drafts, string(raw)+"\nThread: "+msg.ThreadId)
+		} else {
+			f.sent = append(f.sent, string(raw))
+		}
+		json.NewEncoder(w).Encode(gmail.Draft{Id: "d1", Message: &msg})
+	default:
+		http.Error(w, `{"error": {"code": 404, "message": "unexpected request"}}`, http.StatusNotFound)
+	}
+}
+
+// newFakeGmailHandler returns a Gmail handler talking to fake
+func newFakeGmailHandler(t *testing.T, fake *fakeGmail, uploadsDir string) *GmailHandler {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	srv, err := gmail.NewService(context.Background(), option.WithHTTPClient(server.Client()), option.WithEndpoint(server.URL+"/"))
+	if err != nil {
+		t.Fatalf("Failed to create Gmail service: %v", err)
+	}
+	return newGmailHandlerWithService(srv, uploadsDir, nil)
+}
+
+// fakeApplication builds a Gmail message with one inline attachment
+func fakeApplication(id, from, filename string) *gmail.Message {
+	msg := gmailMessage(from, "Application", gmailPart("multipart/mixed", "", "",
+		gmailPart("text/plain", "", "Hello"),
+		gmailPart("text/plain", filename, "document")))
+	msg.Id, msg.ThreadId = id, "thread-"+id
+	msg.Payload.Headers = append(msg.Payload.Headers, &gmail.MessagePartHeader{Name: "Message-ID", Value: "<" + id + "@mail.example.com>"})
+	return msg
+}
+
+// TestGmailHandler_FollowUp tests that fetched messages are labelled by rank and answered
+func TestGmailHandler_FollowUp(t *testing.T) {
+	fake := &fakeGmail{
+		messages: map[string]*gmail.Message{
+			"m1": fakeApplication("m1", "Jane Smith <jane@example.com>", "cv.txt"),
+			"m2": fakeApplication("m2", "Jane Smith <jane@example.com>", "letter.txt"),
+			"m3": fakeApplication("m3", "bob@example.com", "resume.txt"),
+		},
+		labels: []*gmail.Label{{Id: "Label_existing", Name: "CV-Review/Scored"}},
+	}
+	uploadsDir := t.TempDir()
+	handler := newFakeGmailHandler(t, fake, uploadsDir)
+
+	if err := handler.FetchAttachmentsWithContext(context.Background(), models.GmailFilter{Subject: "Application"}); err != nil {
+		t.Fatalf("FetchAttachmentsWithContext() returned error: %v", err)
+	}
+	files := loadGmailMessages(uploadsDir)
+	if files["JaneSmith_CV.txt"].MessageID != "m1" || files["JaneSmith_CoverLetter.txt"].MessageID != "m2" || files["bob_CV.txt"].MessageID != "m3" {
+		t.Fatalf("Unexpected message map: %v", files)
+	}
+
+	results := []models.ApplicantResult{
+		{Name: "JaneSmith", Rank: 1, Scores: models.Scores{TotalScore: 82}, CVPath: "uploads/JaneSmith_CV.txt", CLPath: "uploads/JaneSmith_CoverLetter.txt"},
+		{Name: "bob", Rank: 2, Scores: models.Scores{TotalScore: 40}, CVPath: "uploads/bob_CV.txt"},
+		{Name: "Walkin", Rank: 3, CVPath: "uploads/Walkin_CV.pdf"},
+	}
+	followUp := models.GmailFollowUp{
+		Labels: []models.GmailLabelRule{{Label: "CV-Review/Scored"}, {Label: "CV-Review/Shortlisted", TopN: 2, MinScore: 70}},
+		Reply:  models.GmailReplyDraft,
+	}
+
+	summary, err := handler.FollowUp(context.Background(), results, "Platform Engineer", followUp)
+	if err != nil {
+		t.Fatalf("FollowUp() returned error: %v", err)
+	}
+	if summary != (GmailFollowUpSummary{Labelled: 3, Replies: 2, Skipped: 1}) {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	labelled := make(map[string]string)
+	for _, req := range fake.modified {
+		for _, id := range req.Ids {
+			labelled[id] += strings.Join(req.AddLabelIds, ",") + ";"
+		}
+	}
+	want := map[string]string{
+		"m1": "Label_existing;Label_CV-Review/Shortlisted;",
+		"m2": "Label_existing;Label_CV-Review/Shortlisted;",
+		"m3": "Label_existing;",
+	}
+	for id, labels := range want {
+		if labelled[id] != labels {
+			t.Errorf("Message %s labels = %q, want %q", id, labelled[id], labels)
+		}
+	}
+
+	if len(fake.drafts) != 2 {
+		t.Fatalf("Expected 2 drafts, got %d", len(fake.drafts))
+	}
+	for _, want := range []string{"To: \"Jane Smith\" <jane@example.com>", "Subject: Re: Application", "In-Reply-To: <m1@mail.example.com>", "Dear Jane Smith,", "Platform Engineer position", "Thread: thread-m1"} {
+		if !strings.Contains(fake.drafts[0], want) {
+			t.Errorf("Draft missing %q:\n%s", want, fake.drafts[0])
+		}
+	}
+	if !strings.Contains(fake.drafts[1], "Dear bob,") {
+		t.Errorf("Expected reply to fall back to the applicant name:\n%s", fake.drafts[1])
+	}
+	if len(fake.sent) != 0 {
+		t.Errorf("Draft mode must not send messages")
+	}
+
+	// Running the follow-up again for the same job does nothing
+	modified := len(fake.modified)
+	summary, err = handler.FollowUp(context.Background(), results, "Platform Engineer", followUp)
+	if err != nil {
+		t.Fatalf("Second FollowUp() returned error: %v", err)
+	}
+	if summary != (GmailFollowUpSummary{Skipped: 3}) {
+		t.Errorf("Unexpected summary of the second run: %+v", summary)
+	}
+	if len(fake.modified) != modified || len(fake.drafts) != 2 {
+		t.Errorf("Second run labelled or answered again: %d modifications, %d drafts", len(fake.modified)-modified, len(fake.drafts))
+	}
+
+	// A new rule only labels, and another job is answered again
+	followUp.Labels = append(followUp.Labels, models.GmailLabelRule{Label: "CV-Review/Interview", TopN: 1})
+	summary, err = handler.FollowUp(context.Background(), results, "Platform Engineer", followUp)
+	if err != nil {
+		t.Fatalf("Third FollowUp() returned error: %v", err)
+	}
+	if summary != (GmailFollowUpSummary{Labelled: 2, Skipped: 2}) || len(fake.drafts) != 2 {
+		t.Errorf("Unexpected summary for a new rule: %+v, %d drafts", summary, len(fake.drafts))
+	}
+	summary, err = handler.FollowUp(context.Background(), results, "Site Reliability Engineer", models.GmailFollowUp{Reply: models.GmailReplyDraft})
+	if err != nil {
+		t.Fatalf("FollowUp() for another job returned error: %v", err)
+	}
+	if summary.Replies != 2 || len(fake.drafts) != 4 {
+		t.Errorf("Expected another job to be answered, got %+v and %d drafts", summary, len(fake.drafts))
+	}
+}
+
+// TestGmailHandler_FollowUpForwarded tests that forwarded applications are answered
+// at the original sender's address and skipped when it is not known
+func TestGmailHandler_FollowUpForwarded(t *testing.T) {
+	inline := gmailMessage("Recruiter <recruiter@example.com>", "Fwd: Application", gmailPart("multipart/mixed", "", "",
+		gmailPart("text/plain", "", "FYI\n\n---------- Forwarded message ---------\nFrom: Jane Smith <jane@example.com>\nSubject: Application\n"),
+		gmailPart("text/plain", "cv.txt", "Jane Smith CV")))
+	attached := gmailPart("message/rfc822", "", "",
+		gmailPart("multipart/mixed", "", "",
+			gmailPart("text/plain", "", "Hello"),
+			gmailPart("text/plain", "cv.txt", "Ann Lee CV")))
+	attached.Headers = []*gmail.MessagePartHeader{{Name: "From", Value: "Ann Lee <ann@example.com>"}}
+	unknown := gmailMessage("Recruiter <recruiter@example.com>", "Fwd: Application", gmailPart("multipart/mixed", "", "",
+		gmailPart("text/plain", "", "See the attached CV"),
+		gmailPart("text/plain", "bob_cv.txt", "Bob CV")))
+
+	messages := map[string]*gmail.Message{
+		"m1": inline,
+		"m2": gmailMessage("Recruiter <recruiter@example.com>", "Application", gmailPart("multipart/mixed", "", "", attached)),
+		"m3": unknown,
+	}
+	for id, msg := range messages {
+		msg.Id, msg.ThreadId = id, "thread-"+id
+	}
+	fake := &fakeGmail{messages: messages}
+	uploadsDir := t.TempDir()
+	handler := newFakeGmailHandler(t, fake, uploadsDir)
+
+	if err := handler.FetchAttachmentsWithContext(context.Background(), models.GmailFilter{Subject: "Application"}); err != nil {
+		t.Fatalf("FetchAttachmentsWithContext() returned error: %v", err)
+	}
+	files := loadGmailMessages(uploadsDir)
+	want := map[string]gmailMessageFile{
+		"JaneSmith_CV.txt": {MessageID: "m1", Forwarded: true, ReplyTo: "jane@example.com"},
+		"AnnLee_CV.txt":    {MessageID: "m2", Forwarded: true, ReplyTo: "ann@example.com"},
+		"Recruiter_CV.txt": {MessageID: "m3", Forwarded: true},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("Unexpected message map: %v", files)
+	}
+	for name, file := range want {
+		if files[name] != file {
+			t.Errorf("files[%q] = %+v, want %+v", name, files[name], file)
+		}
+	}
+
+	results := []models.ApplicantResult{
+		{Name: "JaneSmith", Rank: 1, CVPath: "uploads/JaneSmith_CV.txt"},
+		{Name: "AnnLee", Rank: 2, CVPath: "uploads/AnnLee_CV.txt"},
+		{Name: "Recruiter", Rank: 3, CVPath: "uploads/Recruiter_CV.txt"},
+	}
+	summary, err := handler.FollowUp(context.Background(), results, "Platform Engineer", models.GmailFollowUp{Reply: models.GmailReplySend})
+	if err != nil {
+		t.Fatalf("FollowUp() returned error: %v", err)
+	}
+	if summary != (GmailFollowUpSummary{Replies: 2, Skipped: 1}) {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if len(fake.sent) != 2 {
+		t.Fatalf("Expected 2 replies, got %d", len(fake.sent))
+	}
+	for i, to := range []string{"To: <jane@example.com>", "To: <ann@example.com>"} {
+		if !strings.Contains(fake.sent[i], to) || !strings.Contains(fake.sent[i], "Subject: Re: Application\r\n") {
+			t.Errorf("Reply %d is not addressed to the applicant:\n%s", i, fake.sent[i])
+		}
+		if strings.Contains(fake.sent[i], "recruiter@example.com") {
+			t.Errorf("Reply %d goes to the forwarder:\n%s", i, fake.sent[i])
+		}
+	}
+}
+
+// TestLoadGmailMessages_OlderFormat tests reading message IDs recorded as plain strings
+func TestLoadGmailMessages_OlderFormat(t *testing.T) {
+	uploadsDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(uploadsDir, gmailMessagesFile), []byte(`{"JaneSmith_CV.txt": "m1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if files := loadGmailMessages(uploadsDir); files["JaneSmith_CV.txt"] != (gmailMessageFile{MessageID: "m1"}) {
+		t.Errorf("Unexpected message map: %v", files)
+	}
+}
+
+// TestValidateGmailFollowUp tests reply modes, label names and templates
+func TestValidateGmailFollowUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		followUp models.GmailFollowUp
+		wantErr  bool
+	}{
+		{"empty", models.GmailFollowUp{}, false},
+		{"send", models.GmailFollowUp{Reply: models.GmailReplySend, ReplyTemplate: "Hi {{.Name}}"}, false},
+		{"unknown reply mode", models.GmailFollowUp{Reply: "email"}, true},
+		{"missing label", models.GmailFollowUp{Labels: []models.GmailLabelRule{{TopN: 3}}}, true},
+		{"bad template", models.GmailFollowUp{Reply: models.GmailReplyDraft, ReplyTemplate: "Hi {{.Name"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateGmailFollowUp(tt.followUp); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGmailFollowUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestScopesCovered tests that saved tokens are re-authorized only when missing a scope
+func TestScopesCovered(t *testing.T) {
+	if !scopesCovered(nil, gmailFetchScopes) {
+		t.Error("Legacy tokens should cover read access")
+	}
+	if scopesCovered(nil, gmailFollowUpScopes) {
+		t.Error("Legacy tokens should not cover follow-up access")
+	}
+	if !scopesCovered(gmailFollowUpScopes, gmailFetchScopes) {
+		t.Error("Modify access should cover read access")
+	}
+}
@@ -2,29 +2,43 @@ package ingestion
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
+	"net/mail"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
 	"google.golang.org/api/gmail/v1"
 	"google.golang.org/api/option"
 )
 
+const (
+	// gmailMessagesFile maps downloaded files to the Gmail messages they came from,
+	// so scored applicants can be labelled and answered afterwards
+	gmailMessagesFile = ".gmail_messages.json"
+	// gmailFollowUpFile records the labels and replies already done for each job
+	gmailFollowUpFile = ".gmail_follow_up.json"
+)
+
+// Gmail OAuth scopes: fetching only reads mail, follow-up actions also label
+// messages and write replies
+var (
+	gmailFetchScopes    = []string{gmail.GmailReadonlyScope}
+	gmailFollowUpScopes = []string{gmail.GmailModifyScope, gmail.GmailComposeScope}
+)
+
 // GmailProgressCallback is called to report progress during Gmail fetching
 type GmailProgressCallback func(current, total int, message string)
 
 // GmailHandler manages Gmail operations for fetching attachments
 type GmailHandler struct {
 	service    *gmail.Service
+	account    string
 	uploadsDir string
 	progressCb GmailProgressCallback
 }
@@ -35,98 +49,98 @@ func NewGmailHandler(uploadsDir string) (*GmailHandler, error) {
 }
 
 // NewGmailHandlerWithCallback creates a new Gmail handler with progress callback
+// for the only authorized Gmail account
 func NewGmailHandlerWithCallback(uploadsDir string, progressCb GmailProgressCallback) (*GmailHandler, error) {
-	ctx := context.Background()
+	return NewGmailHandlerForAccount("", uploadsDir, progressCb)
+}
 
-	// Read credentials
-	b, err := os.ReadFile("credentials.json")
-	if err != nil {
-		return nil, fmt.Errorf("unable to read credentials file: %w", err)
-	}
+// NewGmailHandlerForAccount creates a Gmail handler for an authorized account;
+// an empty account selects the only authorized one
+func NewGmailHandlerForAccount(account, uploadsDir string, progressCb GmailProgressCallback) (*GmailHandler, error) {
+	return newGmailHandler(account, uploadsDir, progressCb, gmailFetchScopes)
+}
 
-	config, err := google.ConfigFromJSON(b, gmail.GmailReadonlyScope)
+// NewGmailFollowUpHandler creates a Gmail handler that may also label messages
+// and write replies; the account must have granted those permissions
+func NewGmailFollowUpHandler(account, uploadsDir string) (*GmailHandler, error) {
+	return newGmailHandler(account, uploadsDir, nil, gmailFollowUpScopes)
+}
+
+// newGmailHandler creates a Gmail handler authorized for scopes. It never
+// prompts: accounts are authorized with GmailAuthenticator or AuthorizeGmailLocal.
+func newGmailHandler(account, uploadsDir string, progressCb GmailProgressCallback, scopes []string) (*GmailHandler, error) {
+	ctx := context.Background()
+
+	ts, account, err := gmailTokenSource(ctx, NewGmailTokenStore(""), account, scopes)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse credentials: %w", err)
+		return nil, err
 	}
 
-	client := getClient(config)
-	srv, err := gmail.NewService(ctx, option.WithHTTPClient(client))
+	srv, err := gmail.NewService(ctx, option.WithTokenSource(ts))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Gmail client: %w", err)
 	}
 
+	gh := newGmailHandlerWithService(srv, uploadsDir, progressCb)
+	gh.account = account
+	return gh, nil
+}
+
+// newGmailHandlerWithService wraps an existing Gmail service (e.g. one pointed at a test server)
+func newGmailHandlerWithService(srv *gmail.Service, uploadsDir string, progressCb GmailProgressCallback) *GmailHandler {
 	return &GmailHandler{
 		service:    srv,
 		uploadsDir: uploadsDir,
 		progressCb: progressCb,
-	}, nil
-}
-
-// getClient retrieves a token, saves it, then returns the generated client
-func getClient(config *oauth2.Config) *http.Client {
-	tokFile := "token.json"
-	tok, err := tokenFromFile(tokFile)
-	if err != nil {
-		tok = getTokenFromWeb(config)
-		saveToken(tokFile, tok)
 	}
-	return config.Client(context.Background(), tok)
 }
 
-// getTokenFromWeb requests a token from the web
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the authorization code: \n%v\n", authURL)
+// Account returns the address of the Gmail account the handler reads
+func (gh *GmailHandler) Account() string {
+	return gh.account
+}
 
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		log.Fatalf("Unable to read authorization code: %v", err)
+// scopesCovered reports whether the granted scopes allow everything in needed.
+// Tokens saved before scopes were recorded only had read access.
+func scopesCovered(granted, needed []string) bool {
+	if len(granted) == 0 {
+		granted = gmailFetchScopes
 	}
-
-	tok, err := config.Exchange(context.TODO(), authCode)
-	if err != nil {
-		log.Fatalf("Unable to retrieve token from web: %v", err)
+	has := make(map[string]bool)
+	for _, scope := range granted {
+		has[scope] = true
 	}
-	return tok
-}
-
-// tokenFromFile retrieves a token from a local file
-func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return nil, err
+	// Modify access includes read access
+	if has[gmail.GmailModifyScope] {
+		has[gmail.GmailReadonlyScope] = true
 	}
-	defer f.Close()
-	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
-	return tok, err
-}
-
-// saveToken saves a token to a file path
-func saveToken(path string, token *oauth2.Token) {
-	fmt.Printf("Saving credential file to: %s\n", path)
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+	for _, scope := range needed {
+		if !has[scope] {
+			return false
+		}
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(token)
+	return true
 }
 
 // FetchAttachments fetches email attachments with a specific subject
 func (gh *GmailHandler) FetchAttachments(subject string) error {
-	return gh.FetchAttachmentsWithContext(context.Background(), subject)
+	return gh.FetchAttachmentsWithContext(context.Background(), models.GmailFilter{Subject: subject})
 }
 
-// FetchAttachmentsWithContext fetches email attachments with a specific subject and context
-func (gh *GmailHandler) FetchAttachmentsWithContext(ctx context.Context, subject string) error {
+// FetchAttachmentsWithContext fetches attachments of the emails matching filter
+func (gh *GmailHandler) FetchAttachmentsWithContext(ctx context.Context, filter models.GmailFilter) error {
+	query, err := GmailQuery(filter)
+	if err != nil {
+		return err
+	}
+
 	// Ensure uploads directory exists
 	if err := os.MkdirAll(gh.uploadsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create uploads directory: %w", err)
 	}
 
 	user := "me"
-	query := fmt.Sprintf("subject:%s has:attachment", subject)
+	log.Printf("Searching Gmail: %s", query)
 
 	gh.reportProgress(0, 100, "Listing emails...")
 
@@ -164,7 +178,7 @@ func (gh *GmailHandler) FetchAttachmentsWithContext(ctx context.Context, subject
 	}
 
 	if len(allMessages) == 0 {
-		return fmt.Errorf("no messages found with subject: %s", subject)
+		return fmt.Errorf("no messages found matching: %s", query)
 	}
 
 	log.Printf("Found %d emails to process", len(allMessages))
@@ -172,6 +186,7 @@ func (gh *GmailHandler) FetchAttachmentsWithContext(ctx context.Context, subject
 
 	// Process each message with retry logic
 	downloadedCount := 0
+	messageFiles := loadGmailMessages(gh.uploadsDir)
 	for i, msg := range allMessages {
 		// Check for cancellation
 		select {
@@ -183,28 +198,39 @@ func (gh *GmailHandler) FetchAttachmentsWithContext(ctx context.Context, subject
 		progress := 20 + (80 * i / len(allMessages))
 		gh.reportProgress(progress, 100, fmt.Sprintf("Processing email %d/%d", i+1, len(allMessages)))
 
-		if err := gh.processMessageWithRetry(ctx, user, msg.Id, 3); err != nil {
+		saved, forwarded, err := gh.processMessageWithRetry(ctx, user, msg.Id, filter.IncludeBody, 3)
+		if err != nil {
 			log.Printf("Failed to process message %s after retries: %v", msg.Id, err)
 			continue
 		}
+		for _, path := range saved {
+			replyTo, isForwarded := forwarded[path]
+			messageFiles[filepath.Base(path)] = gmailMessageFile{MessageID: msg.Id, Forwarded: isForwarded, ReplyTo: replyTo}
+		}
 		downloadedCount++
 	}
 
+	if err := saveGmailMessages(gh.uploadsDir, messageFiles); err != nil {
+		log.Printf("Failed to record Gmail message IDs: %v", err)
+	}
+
 	gh.reportProgress(100, 100, fmt.Sprintf("Downloaded %d attachments", downloadedCount))
 	log.Printf("Successfully downloaded attachments from %d emails", downloadedCount)
 
 	return nil
 }
 
-// processMessageWithRetry processes a single message with retry logic
-func (gh *GmailHandler) processMessageWithRetry(ctx context.Context, user, messageId string, retries int) error {
+// processMessageWithRetry processes a single message with retry logic. It returns
+// the paths saved and the original sender's address of forwarded files, see
+// gmailMessageSaver.forwarded.
+func (gh *GmailHandler) processMessageWithRetry(ctx context.Context, user, messageId string, includeBody bool, retries int) ([]string, map[string]string, error) {
 	var lastErr error
 
 	for attempt := 0; attempt <= retries; attempt++ {
 		// Check for cancellation
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, nil, ctx.Err()
 		default:
 		}
 
@@ -220,58 +246,65 @@ func (gh *GmailHandler) processMessageWithRetry(ctx context.Context, user, messa
 			continue
 		}
 
-		// Extract sender name for file naming
-		senderName := extractSenderName(message)
-
-		// Process attachments
-		hasAttachments := false
-		for _, part := range message.Payload.Parts {
-			if part.Filename != "" && part.Body.AttachmentId != "" {
-				hasAttachments = true
-
-				attachment, err := gh.service.Users.Messages.Attachments.Get(user, messageId, part.Body.AttachmentId).Do()
+		// Walk the whole MIME tree: attachments can sit inside multipart/alternative
+		// parts or in forwarded emails
+		saver := &gmailMessageSaver{
+			uploadsDir:  gh.uploadsDir,
+			includeBody: includeBody,
+			fetch: func(attachmentID string) ([]byte, error) {
+				attachment, err := gh.service.Users.Messages.Attachments.Get(user, messageId, attachmentID).Do()
 				if err != nil {
-					lastErr = fmt.Errorf("unable to retrieve attachment: %w", err)
-					continue
-				}
-
-				data, err := base64.URLEncoding.DecodeString(attachment.Data)
-				if err != nil {
-					lastErr = fmt.Errorf("unable to decode attachment: %w", err)
-					continue
+					return nil, fmt.Errorf("unable to retrieve attachment: %w", err)
 				}
+				return decodeGmailData(attachment.Data)
+			},
+		}
+		saved, err := saver.save(message)
+		return saved, saver.forwarded, err
+	}
 
-				// Determine if it's a CV or cover letter based on filename
-				filename := part.Filename
-				ext := filepath.Ext(filename)
-				baseName := strings.TrimSuffix(filename, ext)
-
-				// Rename to match convention: SenderName_CV.ext or SenderName_CoverLetter.ext
-				var newFilename string
-				if strings.Contains(strings.ToLower(baseName), "cv") || strings.Contains(strings.ToLower(baseName), "resume") {
-					newFilename = fmt.Sprintf("%s_CV%s", senderName, ext)
-				} else if strings.Contains(strings.ToLower(baseName), "cover") || strings.Contains(strings.ToLower(baseName), "letter") {
-					newFilename = fmt.Sprintf("%s_CoverLetter%s", senderName, ext)
-				} else {
-					newFilename = fmt.Sprintf("%s_%s", senderName, filename)
-				}
+	return nil, nil, lastErr
+}
 
-				filePath := filepath.Join(gh.uploadsDir, newFilename)
-				if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
-					lastErr = fmt.Errorf("unable to write file: %w", err)
-					continue
-				}
+// gmailMessageFile records the Gmail message a downloaded file came from
+type gmailMessageFile struct {
+	MessageID string `json:"message_id"`
+	// Forwarded is set for files of a forwarded application, which are answered
+	// at ReplyTo, the original sender's address, rather than at the forwarder's
+	Forwarded bool   `json:"forwarded,omitempty"`
+	ReplyTo   string `json:"reply_to,omitempty"`
+}
 
-				log.Printf("Downloaded: %s", newFilename)
-			}
-		}
+// UnmarshalJSON also reads the message ID strings recorded by older versions
+func (f *gmailMessageFile) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*f = gmailMessageFile{}
+		return json.Unmarshal(data, &f.MessageID)
+	}
+	type plain gmailMessageFile
+	return json.Unmarshal(data, (*plain)(f))
+}
 
-		if hasAttachments {
-			return nil // Success
-		}
+// loadGmailMessages reads the file name to Gmail message map of the uploads directory
+func loadGmailMessages(uploadsDir string) map[string]gmailMessageFile {
+	messages := make(map[string]gmailMessageFile)
+	data, err := os.ReadFile(filepath.Join(uploadsDir, gmailMessagesFile))
+	if err != nil {
+		return messages
+	}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		log.Printf("Ignoring unreadable %s: %v", gmailMessagesFile, err)
 	}
+	return messages
+}
 
-	return lastErr
+// saveGmailMessages records which Gmail message each downloaded file came from
+func saveGmailMessages(uploadsDir string, messages map[string]gmailMessageFile) error {
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(uploadsDir, gmailMessagesFile), data, 0644)
 }
 
 // reportProgress calls the progress callback if set
@@ -285,6 +318,9 @@ func (gh *GmailHandler) reportProgress(current, total int, message string) {
 func extractSenderName(message *gmail.Message) string {
 	for _, header := range message.Payload.Headers {
 		if header.Name == "From" {
+			if addr, err := mail.ParseAddress(header.Value); err == nil {
+				return senderNameFromAddress(addr.Name, addr.Address)
+			}
 			// Parse "Name <email@example.com>" format
 			from := header.Value
 			if idx := strings.Index(from, "<"); idx > 0 {
@@ -301,3 +337,36 @@ func extractSenderName(message *gmail.Message) string {
 	}
 	return "Unknown"
 }
+
+// senderNameFromAddress turns an email sender into a file name prefix: "Jane Smith"
+// becomes "JaneSmith" and senders without a display name use their address's local part
+func senderNameFromAddress(name, address string) string {
+	if name = strings.ReplaceAll(strings.TrimSpace(name), " ", ""); name != "" {
+		return sanitizeFilename(name)
+	}
+	if idx := strings.Index(address, "@"); idx > 0 {
+		return sanitizeFilename(address[:idx])
+	}
+	return "Unknown"
+}
+
+// attachmentFilename names a downloaded attachment after its sender:
+// SenderName_CV.ext, SenderName_CoverLetter.ext or SenderName_original.ext
+func attachmentFilename(senderName, filename string) string {
+	// Attachment names come from the sender; never let them leave the uploads directory
+	filename = sanitizeFilename(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if filename == "" || filename == "." || filename == ".." {
+		filename = "attachment"
+	}
+
+	ext := filepath.Ext(filename)
+	baseName := strings.ToLower(strings.TrimSuffix(filename, ext))
+	switch {
+	case strings.Contains(baseName, "cv") || strings.Contains(baseName, "resume"):
+		return fmt.Sprintf("%s_CV%s", senderName, ext)
+	case strings.Contains(baseName, "cover") || strings.Contains(baseName, "letter"):
+		return fmt.Sprintf("%s_CoverLetter%s", senderName, ext)
+	default:
+		return fmt.Sprintf("%s_%s", senderName, filename)
+	}
+}
@@ -0,0 +1,248 @@
+package ingestion
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// minBodyLetterSize is the shortest email body saved as a cover letter; shorter
+// bodies are usually just "please find my CV attached"
+const minBodyLetterSize = 200
+
+// gmailMessageSaver writes the attachments (and optionally the body) of one Gmail message
+type gmailMessageSaver struct {
+	uploadsDir string
+	// fetch downloads an attachment that is not inlined in the message
+	fetch func(attachmentID string) ([]byte, error)
+	// includeBody also saves the email body, see models.GmailFilter.IncludeBody
+	includeBody bool
+
+	saved     []string // paths written
+	hasLetter bool
+	// forwarded maps the paths of files from a forwarded application to the
+	// original sender's address, "" when it is not known
+	forwarded map[string]string
+}
+
+// save walks the full MIME tree of message and returns the paths of the files written
+func (s *gmailMessageSaver) save(message *gmail.Message) ([]string, error) {
+	if message.Payload == nil {
+		return nil, nil
+	}
+
+	if s.forwarded == nil {
+		s.forwarded = make(map[string]string)
+	}
+	senderName := extractSenderName(message)
+	textBody := string(inlineData(findBodyPart(message.Payload, "text/plain")))
+
+	// "Fwd: Application" with the original email quoted in the body
+	forwarded := forwardSubjectPattern.MatchString(partHeader(message.Payload, "Subject"))
+	forwardedFrom := ""
+	if forwarded {
+		if addr := forwardedSender(textBody); addr != nil {
+			senderName, forwardedFrom = senderNameFromAddress(addr.Name, addr.Address), addr.Address
+		}
+	}
+	defer func() {
+		// Attached emails have already recorded their own senders
+		for _, path := range s.saved {
+			if _, ok := s.forwarded[path]; forwarded && !ok {
+				s.forwarded[path] = forwardedFrom
+			}
+		}
+	}()
+
+	s.walk(message.Payload, senderName, 0)
+
+	if s.includeBody {
+		body, ext := textBody, ".txt"
+		if strings.TrimSpace(body) == "" {
+			body, ext = string(inlineData(findBodyPart(message.Payload, "text/html"))), ".html"
+		}
+		if err := s.saveBody(senderName, body, ext); err != nil {
+			return s.saved, err
+		}
+	}
+	return s.saved, nil
+}
+
+// walk visits part and its children, saving attachments and remembering the first text bodies
+func (s *gmailMessageSaver) walk(part *gmail.MessagePart, senderName string, depth int) {
+	switch {
+	case part.MimeType == "message/rfc822":
+		s.saveForwarded(part, senderName, depth)
+		return
+	case part.Filename != "":
+		// Logos and pictures embedded in a signature are not application documents
+		if strings.HasPrefix(part.MimeType, "image/") && partHeader(part, "Content-ID") != "" &&
+			!strings.HasPrefix(strings.ToLower(partHeader(part, "Content-Disposition")), "attachment") {
+			return
+		}
+		s.saveAttachment(part, senderName)
+		return
+	}
+
+	for _, child := range part.Parts {
+		s.walk(child, senderName, depth)
+	}
+}
+
+// saveForwarded saves the attachments of an email attached to the message,
+// named after the sender of that email
+func (s *gmailMessageSaver) saveForwarded(part *gmail.MessagePart, senderName string, depth int) {
+	if depth >= maxForwardDepth {
+		return
+	}
+
+	// The raw message is available: parse it like an imported .eml file
+	if part.Body != nil && (part.Body.AttachmentId != "" || part.Body.Data != "") {
+		data, err := s.partData(part)
+		if err != nil {
+			log.Printf("Failed to download attached email %s: %v", part.Filename, err)
+			return
+		}
+		paths, err := saveAttachments(bytes.NewReader(data), s.uploadsDir, depth+1, s.forwarded)
+		if err != nil {
+			log.Printf("Failed to process attached email %s: %v", part.Filename, err)
+		}
+		s.saved = append(s.saved, paths...)
+		return
+	}
+
+	// Gmail has already split the attached email into parts
+	senderAddress := ""
+	if from := partHeader(part, "From"); from != "" {
+		if addr, err := mail.ParseAddress(from); err == nil {
+			senderName, senderAddress = senderNameFromAddress(addr.Name, addr.Address), addr.Address
+		}
+	}
+	nested := &gmailMessageSaver{uploadsDir: s.uploadsDir, fetch: s.fetch, forwarded: s.forwarded}
+	for _, child := range part.Parts {
+		nested.walk(child, senderName, depth+1)
+	}
+	for _, path := range nested.saved {
+		if _, ok := s.forwarded[path]; !ok {
+			s.forwarded[path] = senderAddress
+		}
+	}
+	s.saved = append(s.saved, nested.saved...)
+	s.hasLetter = s.hasLetter || nested.hasLetter
+}
+
+// saveAttachment writes one attachment named after the sender
+func (s *gmailMessageSaver) saveAttachment(part *gmail.MessagePart, senderName string) {
+	data, err := s.partData(part)
+	if err != nil {
+		log.Printf("Failed to download attachment %s: %v", part.Filename, err)
+		return
+	}
+
+	// Several senders can share a name (or have none); never overwrite an earlier attachment
+	filename := attachmentFilename(senderName, part.Filename)
+	filePath := uniqueFilePath(s.uploadsDir, filename)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		log.Printf("Failed to write attachment %s: %v", part.Filename, err)
+		return
+	}
+	log.Printf("Downloaded: %s", filepath.Base(filePath))
+
+	s.saved = append(s.saved, filePath)
+	if strings.HasPrefix(filename, senderName+"_CoverLetter") {
+		s.hasLetter = true
+	}
+}
+
+// saveBody writes the email body as the applicant's cover letter. An email
+// without attachments may hold a pasted CV instead, so its body is saved under
+// a neutral name and left to the classifier.
+func (s *gmailMessageSaver) saveBody(senderName, body, ext string) error {
+	if len(strings.TrimSpace(body)) < minBodyLetterSize || s.hasLetter {
+		return nil
+	}
+
+	filename := senderName + "_CoverLetter" + ext
+	if len(s.saved) == 0 {
+		filename = senderName + "_Email" + ext
+	}
+	filePath := uniqueFilePath(s.uploadsDir, filename)
+	if err := os.WriteFile(filePath, []byte(body), 0644); err != nil {
+		return fmt.Errorf("unable to write email body: %w", err)
+	}
+	log.Printf("Saved email body: %s", filepath.Base(filePath))
+	s.saved = append(s.saved, filePath)
+	return nil
+}
+
+// inlineData returns the decoded body of a part included in the message, or nil
+func inlineData(part *gmail.MessagePart) []byte {
+	if part == nil || part.Body == nil || part.Body.Data == "" {
+		return nil
+	}
+	data, err := decodeGmailData(part.Body.Data)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// partData returns the decoded content of a part, downloading it if Gmail left it out of the message
+func (s *gmailMessageSaver) partData(part *gmail.MessagePart) ([]byte, error) {
+	if part.Body == nil {
+		return nil, fmt.Errorf("part has no body")
+	}
+	if part.Body.Data != "" {
+		return decodeGmailData(part.Body.Data)
+	}
+	if part.Body.AttachmentId == "" {
+		return nil, fmt.Errorf("part has no body")
+	}
+	return s.fetch(part.Body.AttachmentId)
+}
+
+// decodeGmailData decodes the URL-safe base64 used by the Gmail API, with or without padding
+func decodeGmailData(data string) ([]byte, error) {
+	decoded, err := base64.URLEncoding.DecodeString(data)
+	if err != nil {
+		decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(data, "="))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode attachment: %w", err)
+	}
+	return decoded, nil
+}
+
+// findBodyPart returns the first part of the given type that is not an
+// attachment or part of an attached email, or nil
+func findBodyPart(part *gmail.MessagePart, mimeType string) *gmail.MessagePart {
+	if part.Filename != "" || part.MimeType == "message/rfc822" {
+		return nil
+	}
+	if part.MimeType == mimeType {
+		return part
+	}
+	for _, child := range part.Parts {
+		if found := findBodyPart(child, mimeType); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// partHeader returns the value of the named header of a message part
+func partHeader(part *gmail.MessagePart, name string) string {
+	for _, header := range part.Headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
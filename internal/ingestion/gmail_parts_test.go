@@ -0,0 +1,142 @@
+package ingestion
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+// gmailPart builds a Gmail message part with inline (or, for attachmentID, remote) data
+func gmailPart(mimeType, filename, data string, children ...*gmail.MessagePart) *gmail.MessagePart {
+	part := &gmail.MessagePart{MimeType: mimeType, Filename: filename, Parts: children, Body: &gmail.MessagePartBody{}}
+	if data != "" {
+		part.Body.Data = base64.URLEncoding.EncodeToString([]byte(data))
+	}
+	return part
+}
+
+// gmailMessage wraps a payload with From and Subject headers
+func gmailMessage(from, subject string, payload *gmail.MessagePart) *gmail.Message {
+	payload.Headers = append(payload.Headers,
+		&gmail.MessagePartHeader{Name: "From", Value: from},
+		&gmail.MessagePartHeader{Name: "Subject", Value: subject})
+	return &gmail.Message{Payload: payload}
+}
+
+// TestGmailMessageSaver tests walking nested MIME trees, forwarded emails and the email body
+func TestGmailMessageSaver(t *testing.T) {
+	longLetter := "Dear Hiring Manager,\n" + strings.Repeat("I am excited to apply for this role. ", 10)
+
+	remoteCV := gmailPart("application/pdf", "resume.pdf", "")
+	remoteCV.Body.AttachmentId = "att-1"
+
+	signatureLogo := gmailPart("image/png", "logo.png", "png")
+	signatureLogo.Headers = []*gmail.MessagePartHeader{{Name: "Content-ID", Value: "<logo@example.com>"}}
+
+	forwardedParts := gmailPart("message/rfc822", "", "",
+		gmailPart("multipart/mixed", "", "",
+			gmailPart("text/plain", "", "Hello"),
+			gmailPart("text/plain", "cv.txt", "Ann Lee CV")))
+	forwardedParts.Headers = []*gmail.MessagePartHeader{{Name: "From", Value: "Ann Lee <ann@example.com>"}}
+
+	tests := []struct {
+		name        string
+		message     *gmail.Message
+		includeBody bool
+		want        []string
+	}{
+		{
+			name: "attachment in nested multipart",
+			message: gmailMessage("Jane Smith <jane@example.com>", "Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("multipart/related", "", "",
+					gmailPart("multipart/alternative", "", "",
+						gmailPart("text/plain", "", "Hi"),
+						gmailPart("text/html", "", "<p>Hi</p>")),
+					signatureLogo),
+				gmailPart("multipart/mixed", "", "", remoteCV))),
+			want: []string{"JaneSmith_CV.pdf"},
+		},
+		{
+			name: "attached email split by Gmail",
+			message: gmailMessage("Recruiter <hr@example.com>", "Fwd: Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("text/plain", "", "See below"), forwardedParts)),
+			want: []string{"AnnLee_CV.txt"},
+		},
+		{
+			name: "attached raw email",
+			message: gmailMessage("Recruiter <hr@example.com>", "Fwd: Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("message/rfc822", "application.eml", testEmail("Bob Ray <bob@example.com>", "Application", "resume.txt", "CV")))),
+			want: []string{"BobRay_CV.txt"},
+		},
+		{
+			name: "inline forward names the original sender",
+			message: gmailMessage("Recruiter <hr@example.com>", "Fwd: Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("text/plain", "", "---------- Forwarded message ---------\nFrom: Ann Lee <ann@example.com>\nSubject: Application\n"),
+				gmailPart("application/pdf", "cv.pdf", "pdf"))),
+			want: []string{"AnnLee_CV.pdf"},
+		},
+		{
+			name: "body saved as cover letter",
+			message: gmailMessage("Jane Smith <jane@example.com>", "Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("text/plain", "", longLetter),
+				gmailPart("application/pdf", "cv.pdf", "pdf"))),
+			includeBody: true,
+			want:        []string{"JaneSmith_CV.pdf", "JaneSmith_CoverLetter.txt"},
+		},
+		{
+			name:        "body only",
+			message:     gmailMessage("Jane Smith <jane@example.com>", "Application", gmailPart("text/html", "", "<p>"+longLetter+"</p>")),
+			includeBody: true,
+			want:        []string{"JaneSmith_Email.html"},
+		},
+		{
+			name: "body ignored when a letter is attached",
+			message: gmailMessage("Jane Smith <jane@example.com>", "Application", gmailPart("multipart/mixed", "", "",
+				gmailPart("text/plain", "", longLetter),
+				gmailPart("application/pdf", "cover_letter.pdf", "pdf"))),
+			includeBody: true,
+			want:        []string{"JaneSmith_CoverLetter.pdf"},
+		},
+		{
+			name:    "body not saved by default",
+			message: gmailMessage("Jane Smith <jane@example.com>", "Application", gmailPart("text/plain", "", longLetter)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploadsDir := t.TempDir()
+			saver := &gmailMessageSaver{
+				uploadsDir:  uploadsDir,
+				includeBody: tt.includeBody,
+				fetch: func(attachmentID string) ([]byte, error) {
+					if attachmentID != "att-1" {
+						return nil, fmt.Errorf("unknown attachment %s", attachmentID)
+					}
+					return []byte("%PDF"), nil
+				},
+			}
+
+			saved, err := saver.save(tt.message)
+			if err != nil {
+				t.Fatalf("save() returned error: %v", err)
+			}
+			got := uploadedNames(t, uploadsDir)
+			if len(saved) != len(tt.want) || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v (saved %d)", tt.want, got, len(saved))
+			}
+		})
+	}
+}
+
+// TestDecodeGmailData tests padded and unpadded URL-safe base64
+func TestDecodeGmailData(t *testing.T) {
+	for _, data := range []string{"SGk_", "SGk-Pw", "SGk-Pw=="} {
+		if _, err := decodeGmailData(data); err != nil {
+			t.Errorf("decodeGmailData(%q) returned error: %v", data, err)
+		}
+	}
+}
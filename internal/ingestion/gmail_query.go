@@ -0,0 +1,102 @@
+package ingestion
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// gmailDateLayout is the date format accepted in GmailFilter.After and Before
+const gmailDateLayout = "2006-01-02"
+
+// GmailQuery turns a filter into a Gmail search query restricted to messages
+// with attachments, e.g. subject:"Job Application" from:jobs@example.com has:attachment
+func GmailQuery(filter models.GmailFilter) (string, error) {
+	var terms []string
+	addTerm := func(operator, value string) {
+		if value = gmailQuote(value); value != "" {
+			terms = append(terms, operator+value)
+		}
+	}
+
+	addTerm("subject:", filter.Subject)
+	addTerm("from:", filter.From)
+	addTerm("to:", filter.To)
+	if label := gmailLabel(filter.Label); label != "" {
+		terms = append(terms, "label:"+label)
+	}
+
+	after, err := parseGmailDate("after", filter.After)
+	if err != nil {
+		return "", err
+	}
+	before, err := parseGmailDate("before", filter.Before)
+	if err != nil {
+		return "", err
+	}
+	if !after.IsZero() && !before.IsZero() && !before.After(after) {
+		return "", fmt.Errorf("gmail filter: before (%s) must be later than after (%s)", filter.Before, filter.After)
+	}
+	if !after.IsZero() {
+		terms = append(terms, "after:"+after.Format("2006/01/02"))
+	}
+	if !before.IsZero() {
+		terms = append(terms, "before:"+before.Format("2006/01/02"))
+	}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		terms = append(terms, "("+query+")")
+	}
+
+	// Exclusions alone would match almost the whole mailbox
+	if len(terms) == 0 {
+		return "", fmt.Errorf("gmail filter needs a subject, sender, recipient, label, date or query")
+	}
+
+	for _, term := range filter.Exclude {
+		addTerm("-", term)
+	}
+
+	return strings.Join(append(terms, "has:attachment"), " "), nil
+}
+
+// gmailQuote quotes a search value when it contains spaces or search syntax.
+// Gmail has no escape for a double quote inside a phrase, so quotes are dropped.
+func gmailQuote(value string) string {
+	value = strings.Join(strings.Fields(strings.ReplaceAll(value, `"`, " ")), " ")
+	if value == "" {
+		return ""
+	}
+	if strings.ContainsAny(value, ` (){}[]:-+~*|<>`) {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// gmailLabel writes a label name the way Gmail search expects it: spaces and
+// slashes become hyphens and search syntax is dropped
+func gmailLabel(label string) string {
+	label = strings.Join(strings.Fields(label), "-")
+	label = strings.ReplaceAll(label, "/", "-")
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`"(){}[]:+~*|<>`, r) {
+			return -1
+		}
+		return r
+	}, label)
+}
+
+// parseGmailDate parses a YYYY-MM-DD filter date; an empty value gives the zero time
+func parseGmailDate(field, value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(gmailDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("gmail filter: %s must be a date like 2025-06-01: %q", field, value)
+	}
+	return t, nil
+}
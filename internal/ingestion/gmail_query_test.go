@@ -0,0 +1,69 @@
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// TestGmailQuery tests quoting and combining structured Gmail filters
+func TestGmailQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  models.GmailFilter
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "single word subject",
+			filter: models.GmailFilter{Subject: "Application"},
+			want:   "subject:Application has:attachment",
+		},
+		{
+			name:   "multi-word subject is a phrase",
+			filter: models.GmailFilter{Subject: "  Job   Application "},
+			want:   `subject:"Job Application" has:attachment`,
+		},
+		{
+			name:   "quotes and search syntax are neutralised",
+			filter: models.GmailFilter{Subject: `Re: "Senior" Engineer OR from:me`},
+			want:   `subject:"Re: Senior Engineer OR from:me" has:attachment`,
+		},
+		{
+			name: "all fields",
+			filter: models.GmailFilter{
+				Subject: "Job Application",
+				From:    "careers@jobboard.com",
+				To:      "jobs@example.com",
+				Label:   "Applications/2025 Q2",
+				After:   "2025-06-01",
+				Before:  "2025-07-01",
+				Exclude: []string{"newsletter", "out of office"},
+				Query:   "filename:pdf OR filename:docx",
+			},
+			want: `subject:"Job Application" from:careers@jobboard.com to:jobs@example.com label:Applications-2025-Q2 ` +
+				`after:2025/06/01 before:2025/07/01 (filename:pdf OR filename:docx) -newsletter -"out of office" has:attachment`,
+		},
+		{
+			name:   "raw query only",
+			filter: models.GmailFilter{Query: "in:inbox"},
+			want:   "(in:inbox) has:attachment",
+		},
+		{name: "empty filter", filter: models.GmailFilter{}, wantErr: true},
+		{name: "exclusions only", filter: models.GmailFilter{Exclude: []string{"spam"}}, wantErr: true},
+		{name: "bad date", filter: models.GmailFilter{Subject: "CV", After: "01/06/2025"}, wantErr: true},
+		{name: "inverted range", filter: models.GmailFilter{After: "2025-07-01", Before: "2025-06-01"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GmailQuery(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GmailQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GmailQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
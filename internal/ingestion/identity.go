@@ -0,0 +1,424 @@
+package ingestion
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+const (
+	// duplicateCVSimilarity is the similarity above which two CVs are treated as the same submission
+	duplicateCVSimilarity = 0.9
+	// nearDuplicateCVSimilarity is the similarity above which CVs of different applicants are flagged
+	nearDuplicateCVSimilarity = 0.75
+	// contactSectionSize is how much leading text is searched for the applicant's own email and phone;
+	// later mentions are usually referees or former employers
+	contactSectionSize = 1500
+	// phoneKeyDigits is how many trailing digits identify a phone number regardless of country prefix
+	phoneKeyDigits = 9
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d ().-]{7,}\d`)
+)
+
+// genericApplicantNames never identify a person (e.g. Gmail senders without a display name)
+var genericApplicantNames = map[string]bool{
+	"": true, "unknown": true, "applicant": true, "candidate": true, "noreply": true,
+	"document": true, "scan": true, "file": true, "application": true, "jobs": true,
+}
+
+// applicantFile is a classified applicant document waiting for identity resolution
+type applicantFile struct {
+	filename string // path relative to the uploads directory
+	path     string
+	folder   string // per-applicant folder the file was found in, if any
+	name     string // applicant name taken from the folder or file name
+	docType  DocumentType
+	text     string
+	ocr      *OCRResult
+	modTime  time.Time
+	emails   []string
+	phones   []string
+	shingles map[string]struct{}
+}
+
+// newApplicantFile extracts the identity signals of a classified document
+func newApplicantFile(filename, path, folder string, docType DocumentType, extraction *Extraction, modTime time.Time) *applicantFile {
+	name := folder
+	if name == "" {
+		name = applicantNameFromFile(filename)
+	}
+	f := &applicantFile{
+		filename: filename,
+		path:     path,
+		folder:   folder,
+		name:     name,
+		docType:  docType,
+		text:     extraction.Text,
+		ocr:      extraction.OCR,
+		modTime:  modTime,
+	}
+
+	// Certificates and transcripts carry the issuer's contact details, not the applicant's
+	if docType != models.DocumentCV && docType != models.DocumentCoverLetter {
+		return f
+	}
+
+	contact := f.text
+	if len(contact) > contactSectionSize {
+		contact = contact[:contactSectionSize]
+	}
+	f.emails = extractEmails(contact)
+	f.phones = extractPhones(contact)
+	if docType == models.DocumentCV {
+		f.shingles = textShingles(f.text)
+	}
+	return f
+}
+
+// applicantNameFromFile derives the applicant name from a file name such as
+// "JohnDoe_CV.pdf", "John_Doe_Resume.pdf" or "JohnDoeCoverLetter.docx"; it is
+// empty when the file name holds no name (e.g. "resume.pdf")
+func applicantNameFromFile(filename string) string {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	segments := strings.FieldsFunc(base, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+
+	var name []string
+	for i, segment := range segments {
+		words := splitCamelCase(segment)
+		// Drop document-type words and copy counters ("JaneSmithCV", "Jane_CV_2")
+		kept := words[:0]
+		for _, w := range words {
+			if _, hint := filenameHints[strings.ToLower(w)]; hint || unicode.IsDigit([]rune(w)[0]) {
+				break
+			}
+			kept = append(kept, w)
+		}
+		if len(kept) == 0 {
+			break
+		}
+		name = append(name, kept...)
+
+		// "John_Doe_CV" spreads one name over several segments; "JaneSmith_Portfolio" does not
+		if len(kept) < len(words) || len(words) > 1 || i >= 2 {
+			break
+		}
+		if i+1 < len(segments) && !unicode.IsUpper([]rune(segments[i+1])[0]) {
+			break
+		}
+	}
+
+	return strings.Join(name, "")
+}
+
+// nameKey normalizes an applicant name for comparison ("John_Doe" and "johndoe" match)
+func nameKey(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) {
+			sb.WriteRune(r)
+		}
+	}
+	key := sb.String()
+	if genericApplicantNames[key] {
+		return ""
+	}
+	return key
+}
+
+// extractEmails returns the distinct lower-case email addresses in text
+func extractEmails(text string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+	for _, match := range emailPattern.FindAllString(text, -1) {
+		email := strings.ToLower(strings.TrimRight(match, "."))
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// extractPhones returns phone numbers in text as their trailing digits, so
+// "+41 79 555 01 01" and "079 555 01 01" compare equal
+func extractPhones(text string) []string {
+	var phones []string
+	seen := make(map[string]bool)
+	for _, match := range phonePattern.FindAllString(text, -1) {
+		var digits []rune
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits = append(digits, r)
+			}
+		}
+		// Shorter runs are dates or years; longer ones are IDs or several numbers run together
+		if len(digits) < phoneKeyDigits || len(digits) > 15 {
+			continue
+		}
+		key := string(digits[len(digits)-phoneKeyDigits:])
+		if !seen[key] {
+			seen[key] = true
+			phones = append(phones, key)
+		}
+	}
+	return phones
+}
+
+// textShingles returns the set of three-word sequences in text
+func textShingles(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	shingles := make(map[string]struct{})
+	for i := 0; i+3 <= len(words); i++ {
+		shingles[strings.Join(words[i:i+3], " ")] = struct{}{}
+	}
+	return shingles
+}
+
+// shingleSimilarity is the Jaccard similarity of two shingle sets (0-1)
+func shingleSimilarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for s := range a {
+		if _, ok := b[s]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// identitySet is a union-find over applicant files; each root tracks the
+// email addresses on the CVs of its members so different people are never merged
+type identitySet struct {
+	parent []int
+	emails []map[string]bool
+}
+
+func newIdentitySet(files []*applicantFile) *identitySet {
+	s := &identitySet{parent: make([]int, len(files)), emails: make([]map[string]bool, len(files))}
+	for i, f := range files {
+		s.parent[i] = i
+		s.emails[i] = make(map[string]bool)
+		if f.docType != models.DocumentCV {
+			continue
+		}
+		for _, email := range f.emails {
+			s.emails[i][email] = true
+		}
+	}
+	return s
+}
+
+func (s *identitySet) find(i int) int {
+	for s.parent[i] != i {
+		s.parent[i] = s.parent[s.parent[i]]
+		i = s.parent[i]
+	}
+	return i
+}
+
+// conflicts reports whether two groups both have email addresses and share none
+func (s *identitySet) conflicts(i, j int) bool {
+	a, b := s.emails[s.find(i)], s.emails[s.find(j)]
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	for email := range a {
+		if b[email] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *identitySet) union(i, j int) {
+	ri, rj := s.find(i), s.find(j)
+	if ri == rj {
+		return
+	}
+	s.parent[rj] = ri
+	for email := range s.emails[rj] {
+		s.emails[ri][email] = true
+	}
+}
+
+// resolveApplicants groups documents that belong to the same person. Files in
+// the same applicant folder and CVs sharing an email address always link
+// documents; an email address in a cover letter, a shared phone number,
+// matching normalized name or near-identical CV links them unless the emails
+// of their CVs disagree.
+func resolveApplicants(files []*applicantFile) [][]*applicantFile {
+	dropSharedEmails(files)
+	set := newIdentitySet(files)
+
+	link := func(strong bool, keys func(f *applicantFile) []string) {
+		owner := make(map[string]int)
+		for i, f := range files {
+			for _, key := range keys(f) {
+				j, seen := owner[key]
+				if !seen {
+					owner[key] = i
+					continue
+				}
+				if strong || !set.conflicts(i, j) {
+					set.union(j, i)
+				}
+			}
+		}
+	}
+
+	link(true, func(f *applicantFile) []string {
+		if f.folder != "" {
+			return []string{f.folder}
+		}
+		return nil
+	})
+	link(true, func(f *applicantFile) []string {
+		if f.docType == models.DocumentCV {
+			return f.emails
+		}
+		return nil
+	})
+	link(false, func(f *applicantFile) []string { return f.emails })
+	link(false, func(f *applicantFile) []string { return f.phones })
+	link(false, func(f *applicantFile) []string {
+		if key := nameKey(f.name); key != "" {
+			return []string{key}
+		}
+		return nil
+	})
+
+	// The same CV sent twice (e.g. from a different address or without a name)
+	for i := range files {
+		for j := i + 1; j < len(files); j++ {
+			if files[i].docType != models.DocumentCV || files[j].docType != models.DocumentCV || set.find(i) == set.find(j) {
+				continue
+			}
+			if shingleSimilarity(files[i].shingles, files[j].shingles) >= duplicateCVSimilarity && !set.conflicts(i, j) {
+				set.union(i, j)
+			}
+		}
+	}
+
+	groups := make(map[int][]*applicantFile)
+	var roots []int
+	for i, f := range files {
+		root := set.find(i)
+		if groups[root] == nil {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], f)
+	}
+
+	resolved := make([][]*applicantFile, 0, len(roots))
+	for _, root := range roots {
+		resolved = append(resolved, groups[root])
+	}
+	return resolved
+}
+
+// dropSharedEmails removes the email addresses found in the files of more than
+// one named applicant, such as the employer's address in the recipient block
+// of cover letters; they identify nobody
+func dropSharedEmails(files []*applicantFile) {
+	owners := make(map[string]map[string]bool)
+	for _, f := range files {
+		owner := nameKey(f.name)
+		if owner == "" {
+			continue
+		}
+		for _, email := range f.emails {
+			if owners[email] == nil {
+				owners[email] = make(map[string]bool)
+			}
+			owners[email][owner] = true
+		}
+	}
+
+	for _, f := range files {
+		kept := f.emails[:0]
+		for _, email := range f.emails {
+			if len(owners[email]) <= 1 {
+				kept = append(kept, email)
+			}
+		}
+		f.emails = kept
+	}
+}
+
+// applicantDisplayName picks the applicant folder name or else the most common
+// real name in a group, falling back to the email address for senders without a name
+func applicantDisplayName(group []*applicantFile) string {
+	var folders, names []string
+	for _, f := range group {
+		if f.folder != "" {
+			folders = append(folders, f.folder)
+		} else if nameKey(f.name) != "" {
+			names = append(names, f.name)
+		}
+	}
+	if best := mostCommon(folders); best != "" {
+		return best
+	}
+	if best := mostCommon(names); best != "" {
+		return best
+	}
+
+	for _, f := range group {
+		if len(f.emails) > 0 {
+			return strings.Split(f.emails[0], "@")[0]
+		}
+	}
+	if group[0].name != "" {
+		return group[0].name
+	}
+	return "Unknown"
+}
+
+// mostCommon returns the most frequent value, preferring the first alphabetically on ties
+func mostCommon(values []string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, v := range values {
+		counts[v]++
+		if counts[v] > counts[best] || counts[v] == counts[best] && v < best {
+			best = v
+		}
+	}
+	return best
+}
+
+// newestFirst orders documents by modification time, then by length, so the latest submission wins
+func newestFirst(files []*applicantFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.After(files[j].modTime)
+		}
+		return len(files[i].text) > len(files[j].text)
+	})
+}
+
+// supersededReason explains why an older document of the same applicant is not scored
+func supersededReason(kept, dropped *applicantFile) string {
+	if dropped.docType == models.DocumentCV && shingleSimilarity(kept.shingles, dropped.shingles) >= duplicateCVSimilarity {
+		return fmt.Sprintf("duplicate submission of %s", kept.filename)
+	}
+	return fmt.Sprintf("superseded by newer %s %s", strings.ReplaceAll(string(dropped.docType), "_", " "), kept.filename)
+}
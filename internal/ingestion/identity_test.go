@@ -0,0 +1,233 @@
+package ingestion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCV builds a CV with the given contact details and body
+func testCV(name, email, body string) string {
+	return fmt.Sprintf("%s\n%s | +41 79 555 %02d %02d\n\nWork Experience\n%s\n\nEducation\nBSc Computer Science\n\nSkills\nGo, Kubernetes",
+		name, email, len(name), len(email), body)
+}
+
+const testExperience = `Acme Corp, Lead Developer, 2018 - Present
+Led a team of eight engineers building payment services in Go and Kubernetes.
+Globex, Developer, 2014 - 2018
+Built reporting pipelines and reduced batch processing time by forty percent.`
+
+// writeTestFiles writes files into dir with increasing modification times in the given order
+func writeTestFiles(t *testing.T, dir string, files [][2]string) {
+	t.Helper()
+	base := time.Now().Add(-time.Hour)
+	for i, file := range files {
+		path := filepath.Join(dir, file[0])
+		if err := os.WriteFile(path, []byte(file[1]), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file[0], err)
+		}
+		modTime := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, modTime, modTime)
+	}
+}
+
+// TestApplicantNameFromFile tests name extraction from common file naming styles
+func TestApplicantNameFromFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"JohnDoe_CV.pdf", "JohnDoe"},
+		{"John_Doe_CV.pdf", "JohnDoe"},
+		{"John-Doe-Resume.docx", "JohnDoe"},
+		{"JohnDoeCoverLetter.txt", "JohnDoe"},
+		{"JaneSmith_Portfolio.pdf", "JaneSmith"},
+		{"Unknown_CV_2.pdf", "Unknown"},
+		{"resume.pdf", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := applicantNameFromFile(tt.filename); got != tt.want {
+				t.Errorf("applicantNameFromFile(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExtractPhones tests that phone formats normalize to the same key and dates are ignored
+func TestExtractPhones(t *testing.T) {
+	got := extractPhones("Tel: +41 79 555 01 01\nMobile: 079-555-01-01\nAcme Corp 2018 - 2024")
+	want := []string{"795550101"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractPhones() = %v, want %v", got, want)
+	}
+}
+
+// TestLoadDocuments_IdentityResolution tests merging by name and email, and separation of distinct senders
+func TestLoadDocuments_IdentityResolution(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, [][2]string{
+		// Same person, different file name styles
+		{"JohnDoe_CV.txt", testCV("John Doe", "john@example.com", "Northwind, Analyst, 2015 - 2020\nModelled demand for retail stores.")},
+		{"John_Doe_CoverLetter.txt", "Dear Hiring Manager,\nI am writing to apply for the analyst role.\nYours sincerely,\nJohn Doe"},
+		// Gmail senders without a name, told apart by email
+		{"Unknown_CV.txt", testCV("Ann Lee", "ann@example.com", "Initech, Tester, 2012 - 2019\nAutomated regression suites for banking software.")},
+		{"Unknown_CV_2.txt", testCV("Bob Ray", "bob@example.com", "Hooli, Designer, 2016 - 2021\nDesigned onboarding flows for mobile apps.")},
+		// Cover letter linked to the first Unknown sender only by email
+		{"AnnLee_Letter.txt", "Dear Hiring Manager,\nI am writing to apply. You can reach me at ann@example.com.\nKind regards"},
+	})
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	byName := make(map[string]int)
+	for i, doc := range docs {
+		byName[doc.Name] = i
+	}
+	if len(docs) != 3 {
+		t.Fatalf("Expected 3 applicants, got %d: %v", len(docs), byName)
+	}
+
+	john, ok := byName["JohnDoe"]
+	if !ok || docs[john].CoverLetter().Content == "" {
+		t.Errorf("JohnDoe CV and cover letter should be merged, got %v", byName)
+	}
+
+	ann, ok := byName["AnnLee"]
+	if !ok || !strings.Contains(docs[ann].CV().Content, "Initech") || docs[ann].CoverLetter().Content == "" {
+		t.Errorf("Unknown sender CV should be merged with AnnLee's letter by email, got %v", byName)
+	}
+	if ok && !reflect.DeepEqual(docs[ann].Emails, []string{"ann@example.com"}) {
+		t.Errorf("Expected AnnLee email to be recorded, got %v", docs[ann].Emails)
+	}
+
+	// Senders without a name are reported under their email address
+	if _, ok := byName["bob"]; !ok {
+		t.Errorf("Second unknown sender should remain a separate applicant, got %v", byName)
+	}
+	if len(fh.UnprocessedFiles()) != 0 {
+		t.Errorf("Expected no unprocessed files, got %+v", fh.UnprocessedFiles())
+	}
+}
+
+// TestLoadDocuments_SharedRecipientEmail tests that the employer's address in
+// the recipient block of cover letters does not merge different applicants
+func TestLoadDocuments_SharedRecipientEmail(t *testing.T) {
+	letter := func(name, email string) string {
+		return fmt.Sprintf("Acme Corp\nHiring Team\ncareers@acme.com\n\nDear Hiring Manager,\nI am writing to apply for the developer role. %s\nYours sincerely,\n%s", email, name)
+	}
+
+	tests := []struct {
+		name    string
+		letters [][2]string
+	}{
+		{
+			name: "letters with the applicants' own emails",
+			letters: [][2]string{
+				{"JaneSmith_CoverLetter.txt", letter("Jane Smith", "jane@example.com")},
+				{"JohnDoe_CoverLetter.txt", letter("John Doe", "john@example.com")},
+			},
+		},
+		{
+			name: "letters without the applicants' emails",
+			letters: [][2]string{
+				{"JaneSmith_CoverLetter.txt", letter("Jane Smith", "")},
+				{"JohnDoe_CoverLetter.txt", letter("John Doe", "")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeTestFiles(t, tmpDir, append([][2]string{
+				{"JaneSmith_CV.txt", testCV("Jane Smith", "jane@example.com", testExperience)},
+				{"JohnDoe_CV.txt", testCV("John Doe", "john@example.com", "Northwind, Analyst, 2015 - 2020\nModelled demand for retail stores.")},
+			}, tt.letters...))
+
+			fh := NewFileHandler(tmpDir)
+			docs, err := fh.LoadDocuments()
+			if err != nil {
+				t.Fatalf("Failed to load documents: %v", err)
+			}
+
+			if len(docs) != 2 {
+				t.Fatalf("Expected 2 applicants, got %+v", docs)
+			}
+			for _, doc := range docs {
+				first := strings.Fields(doc.Name)[0][:4]
+				if !strings.Contains(doc.CV().Content, first) || !strings.Contains(doc.CoverLetter().Content, first) {
+					t.Errorf("%s: expected their own CV and cover letter", doc.Name)
+				}
+				for _, email := range doc.Emails {
+					if email == "careers@acme.com" {
+						t.Errorf("%s: the employer's address was recorded as the applicant's", doc.Name)
+					}
+				}
+			}
+			if len(fh.UnprocessedFiles()) != 0 {
+				t.Errorf("Expected no unprocessed files, got %+v", fh.UnprocessedFiles())
+			}
+		})
+	}
+}
+
+// TestLoadDocuments_DuplicateSubmissions tests that a re-sent CV is merged and the newest copy scored
+func TestLoadDocuments_DuplicateSubmissions(t *testing.T) {
+	tmpDir := t.TempDir()
+	cv := testCV("Jane Smith", "jane@example.com", testExperience)
+	writeTestFiles(t, tmpDir, [][2]string{
+		{"JaneSmith_CV.txt", cv},
+		{"Unknown_CV.txt", cv + "\nReferences available on request"},
+	})
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	if len(docs) != 1 || docs[0].Name != "JaneSmith" {
+		t.Fatalf("Expected one applicant JaneSmith, got %+v", docs)
+	}
+	if filepath.Base(docs[0].CV().Path) != "Unknown_CV.txt" {
+		t.Errorf("Expected newest CV to be scored, got %s", docs[0].CV().Path)
+	}
+
+	unprocessed := fh.UnprocessedFiles()
+	if len(unprocessed) != 1 || unprocessed[0].File != "JaneSmith_CV.txt" || !strings.Contains(unprocessed[0].Reason, "duplicate submission") {
+		t.Errorf("Expected older copy to be reported as duplicate, got %+v", unprocessed)
+	}
+}
+
+// TestLoadDocuments_FlagsNearDuplicateCVs tests that different applicants with copied CVs are flagged
+func TestLoadDocuments_FlagsNearDuplicateCVs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestFiles(t, tmpDir, [][2]string{
+		{"JaneSmith_CV.txt", testCV("Jane Smith", "jane@example.com", testExperience)},
+		{"MaryJones_CV.txt", testCV("Mary Jones", "mary@example.com", testExperience)},
+	})
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("Applicants with different emails must not be merged, got %d", len(docs))
+	}
+	for _, doc := range docs {
+		if len(doc.ReviewReasons) != 1 || !strings.Contains(doc.ReviewReasons[0], "similar") {
+			t.Errorf("%s: expected near-duplicate review flag, got %v", doc.Name, doc.ReviewReasons)
+		}
+	}
+}
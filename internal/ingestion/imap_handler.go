@@ -0,0 +1,399 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+const (
+	// IMAP connection security modes
+	IMAPSecurityTLS      = "tls"      // implicit TLS, usually port 993
+	IMAPSecurityStartTLS = "starttls" // upgrade a plain connection, usually port 143
+	IMAPSecurityNone     = "none"     // unencrypted; only for local test servers
+
+	// IMAP authentication methods
+	IMAPAuthLogin   = "login"   // username and password (or app password)
+	IMAPAuthXOAuth2 = "xoauth2" // username and OAuth access token (Exchange Online, Gmail)
+
+	// imapStateFile records the fetched UIDs per mailbox; it lives in the uploads
+	// directory so clearing the uploads also starts the next fetch from scratch
+	imapStateFile = ".imap_state.json"
+	// imapFetchBatch is how many messages are downloaded per FETCH command
+	imapFetchBatch = 20
+	// imapDialTimeout bounds connecting to the server
+	imapDialTimeout = 30 * time.Second
+)
+
+// IMAPConfig describes the mailbox and search criteria for IMAP ingestion
+type IMAPConfig struct {
+	Host       string    `json:"host"`
+	Port       int       `json:"port"`     // defaults to 993 for TLS and 143 otherwise
+	Security   string    `json:"security"` // IMAPSecurityTLS (default), IMAPSecurityStartTLS or IMAPSecurityNone
+	Username   string    `json:"username"`
+	Password   string    `json:"-"`    // password, or access token for XOAUTH2
+	Auth       string    `json:"auth"` // IMAPAuthLogin (default) or IMAPAuthXOAuth2
+	Folder     string    `json:"folder"`
+	Subject    string    `json:"subject"`
+	From       string    `json:"from"`
+	Since      time.Time `json:"since"`
+	UnseenOnly bool      `json:"unseen_only"`
+	// TLSConfig overrides the default TLS settings (e.g. a private CA)
+	TLSConfig *tls.Config `json:"-"`
+}
+
+// IMAPConfigFromEnv returns an IMAP configuration from the IMAP_* environment
+// variables, so credentials need not be sent with every request
+func IMAPConfigFromEnv() IMAPConfig {
+	port, _ := strconv.Atoi(os.Getenv("IMAP_PORT"))
+	return IMAPConfig{
+		Host:     os.Getenv("IMAP_HOST"),
+		Port:     port,
+		Security: os.Getenv("IMAP_SECURITY"),
+		Username: os.Getenv("IMAP_USERNAME"),
+		Password: os.Getenv("IMAP_PASSWORD"),
+		Auth:     os.Getenv("IMAP_AUTH"),
+		Folder:   os.Getenv("IMAP_FOLDER"),
+	}
+}
+
+// withDefaults fills in the port, security, authentication and folder defaults
+func (c IMAPConfig) withDefaults() IMAPConfig {
+	if c.Security == "" {
+		c.Security = IMAPSecurityTLS
+	}
+	if c.Auth == "" {
+		c.Auth = IMAPAuthLogin
+	}
+	if c.Folder == "" {
+		c.Folder = "INBOX"
+	}
+	if c.Port == 0 {
+		c.Port = 143
+		if c.Security == IMAPSecurityTLS {
+			c.Port = 993
+		}
+	}
+	return c
+}
+
+// Validate checks that the configuration can be used to connect
+func (c IMAPConfig) Validate() error {
+	c = c.withDefaults()
+	if c.Host == "" {
+		return fmt.Errorf("IMAP host is required")
+	}
+	if c.Username == "" || c.Password == "" {
+		return fmt.Errorf("IMAP username and password are required")
+	}
+	switch c.Security {
+	case IMAPSecurityTLS, IMAPSecurityStartTLS, IMAPSecurityNone:
+	default:
+		return fmt.Errorf("unknown IMAP security mode %q (use tls, starttls or none)", c.Security)
+	}
+	switch c.Auth {
+	case IMAPAuthLogin, IMAPAuthXOAuth2:
+	default:
+		return fmt.Errorf("unknown IMAP auth method %q (use login or xoauth2)", c.Auth)
+	}
+	return nil
+}
+
+// mailboxKey identifies the mailbox in the UID state file
+func (c IMAPConfig) mailboxKey() string {
+	return fmt.Sprintf("%s@%s:%d/%s", c.Username, c.Host, c.Port, c.Folder)
+}
+
+// criteria builds the IMAP SEARCH criteria for application emails
+func (c IMAPConfig) criteria() *imap.SearchCriteria {
+	criteria := imap.NewSearchCriteria()
+	if c.Subject != "" {
+		criteria.Header.Add("Subject", c.Subject)
+	}
+	if c.From != "" {
+		criteria.Header.Add("From", c.From)
+	}
+	if !c.Since.IsZero() {
+		criteria.Since = c.Since
+	}
+	if c.UnseenOnly {
+		criteria.WithoutFlags = []string{imap.SeenFlag}
+	}
+	return criteria
+}
+
+// imapMailboxState records which messages of one mailbox were fetched. The
+// UIDs themselves are kept rather than the highest one, so a fetch with other
+// filters still finds older matching messages and skips those already fetched.
+type imapMailboxState struct {
+	UIDValidity uint32 `json:"uid_validity"`
+	Fetched     string `json:"fetched,omitempty"`  // UID set, e.g. "1:40,45"
+	LastUID     uint32 `json:"last_uid,omitempty"` // written by older versions
+}
+
+// fetchedUIDs returns the fetched UIDs; messages up to the LastUID of older
+// state files count as fetched
+func (s imapMailboxState) fetchedUIDs() *imap.SeqSet {
+	fetched, err := imap.ParseSeqSet(s.Fetched)
+	if err != nil {
+		if s.Fetched != "" {
+			log.Printf("WARNING: Ignoring corrupt IMAP UID set %q: %v", s.Fetched, err)
+		}
+		fetched = new(imap.SeqSet)
+	}
+	if s.LastUID > 0 {
+		fetched.AddRange(1, s.LastUID)
+	}
+	return fetched
+}
+
+// IMAPHandler fetches application attachments from an IMAP mailbox
+type IMAPHandler struct {
+	config     IMAPConfig
+	uploadsDir string
+	progressCb GmailProgressCallback
+}
+
+// NewIMAPHandler creates a new IMAP handler
+func NewIMAPHandler(config IMAPConfig, uploadsDir string, progressCb GmailProgressCallback) (*IMAPHandler, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &IMAPHandler{
+		config:     config.withDefaults(),
+		uploadsDir: uploadsDir,
+		progressCb: progressCb,
+	}, nil
+}
+
+// FetchAttachmentsWithContext downloads the attachments of matching emails that
+// no earlier fetch downloaded into the uploads directory and returns how many
+// files were saved. The mailbox is opened read-only, so no flags change.
+func (ih *IMAPHandler) FetchAttachmentsWithContext(ctx context.Context) (int, error) {
+	if err := os.MkdirAll(ih.uploadsDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create uploads directory: %w", err)
+	}
+
+	ih.reportProgress(0, 100, fmt.Sprintf("Connecting to %s...", ih.config.Host))
+	c, err := ih.connect()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Logout()
+
+	// Abort blocking commands when the context is canceled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Terminate()
+		case <-done:
+		}
+	}()
+
+	mbox, err := c.Select(ih.config.Folder, true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open IMAP folder %s: %w", ih.config.Folder, err)
+	}
+
+	states, err := ih.loadState()
+	if err != nil {
+		return 0, err
+	}
+	key := ih.config.mailboxKey()
+	state := states[key]
+	if state.UIDValidity != mbox.UidValidity {
+		// UIDs were reassigned (or this is the first fetch); start over
+		state = imapMailboxState{UIDValidity: mbox.UidValidity}
+	}
+
+	fetched := state.fetchedUIDs()
+	state.LastUID = 0
+
+	ih.reportProgress(10, 100, "Searching emails...")
+	uids, err := c.UidSearch(ih.config.criteria())
+	if err != nil {
+		return 0, ctxErr(ctx, fmt.Errorf("failed to search IMAP folder: %w", err))
+	}
+	var newUIDs []uint32
+	for _, uid := range uids {
+		if !fetched.Contains(uid) {
+			newUIDs = append(newUIDs, uid)
+		}
+	}
+	sort.Slice(newUIDs, func(i, j int) bool { return newUIDs[i] < newUIDs[j] })
+
+	if len(newUIDs) == 0 {
+		log.Printf("No new matching emails in %s", ih.config.Folder)
+		return 0, nil
+	}
+	log.Printf("Found %d new emails to process", len(newUIDs))
+
+	saved := 0
+	for start := 0; start < len(newUIDs); start += imapFetchBatch {
+		batch := newUIDs[start:min(start+imapFetchBatch, len(newUIDs))]
+		ih.reportProgress(20+80*start/len(newUIDs), 100, fmt.Sprintf("Downloading emails %d-%d of %d", start+1, start+len(batch), len(newUIDs)))
+
+		n, err := ih.fetchBatch(c, batch)
+		saved += n
+		if err != nil {
+			return saved, ctxErr(ctx, err)
+		}
+
+		// Record progress after every batch so an interrupted fetch resumes where it stopped
+		fetched.AddNum(batch...)
+		state.Fetched = fetched.String()
+		states[key] = state
+		if err := ih.saveState(states); err != nil {
+			return saved, err
+		}
+	}
+
+	ih.reportProgress(100, 100, fmt.Sprintf("Downloaded %d attachments", saved))
+	log.Printf("Downloaded %d attachments from %d emails", saved, len(newUIDs))
+	return saved, nil
+}
+
+// connect dials the server with the configured security and authenticates
+func (ih *IMAPHandler) connect() (*client.Client, error) {
+	cfg := ih.config
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dialer := &net.Dialer{Timeout: imapDialTimeout}
+
+	tlsConfig := cfg.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: cfg.Host}
+	}
+
+	var c *client.Client
+	var err error
+	if cfg.Security == IMAPSecurityTLS {
+		c, err = client.DialWithDialerTLS(dialer, addr, tlsConfig)
+	} else {
+		c, err = client.DialWithDialer(dialer, addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+	}
+
+	if cfg.Security == IMAPSecurityStartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Logout()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if cfg.Auth == IMAPAuthXOAuth2 {
+		err = c.Authenticate(&xoauth2Client{username: cfg.Username, token: cfg.Password})
+	} else {
+		err = c.Login(cfg.Username, cfg.Password)
+	}
+	if err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("IMAP authentication failed: %w", err)
+	}
+	return c, nil
+}
+
+// fetchBatch downloads the given messages and saves their attachments
+func (ih *IMAPHandler) fetchBatch(c *client.Client, uids []uint32) (int, error) {
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(uids...)
+
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, imapFetchBatch)
+	fetchErr := make(chan error, 1)
+	go func() {
+		fetchErr <- c.UidFetch(seqset, []imap.FetchItem{imap.FetchUid, section.FetchItem()}, messages)
+	}()
+
+	saved := 0
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			log.Printf("Skipping email UID %d: server returned no body", msg.Uid)
+			continue
+		}
+		n, err := saveMessageAttachments(body, ih.uploadsDir)
+		if err != nil {
+			log.Printf("Failed to process email UID %d: %v", msg.Uid, err)
+		}
+		saved += n
+	}
+
+	if err := <-fetchErr; err != nil {
+		return saved, fmt.Errorf("failed to fetch emails: %w", err)
+	}
+	return saved, nil
+}
+
+// loadState reads the fetched UIDs per mailbox
+func (ih *IMAPHandler) loadState() (map[string]imapMailboxState, error) {
+	states := make(map[string]imapMailboxState)
+	data, err := os.ReadFile(filepath.Join(ih.uploadsDir, imapStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("failed to read IMAP state: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		log.Printf("WARNING: Ignoring corrupt IMAP state file: %v", err)
+		return make(map[string]imapMailboxState), nil
+	}
+	return states, nil
+}
+
+// saveState stores the fetched UIDs per mailbox
+func (ih *IMAPHandler) saveState(states map[string]imapMailboxState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode IMAP state: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(ih.uploadsDir, imapStateFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to save IMAP state: %w", err)
+	}
+	return nil
+}
+
+// reportProgress calls the progress callback if set
+func (ih *IMAPHandler) reportProgress(current, total int, message string) {
+	if ih.progressCb != nil {
+		ih.progressCb(current, total, message)
+	}
+}
+
+// ctxErr prefers the context error when a command failed because the fetch was canceled
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// xoauth2Client implements the XOAUTH2 SASL mechanism
+type xoauth2Client struct {
+	username string
+	token    string
+}
+
+func (c *xoauth2Client) Start() (string, []byte, error) {
+	return "XOAUTH2", []byte("user=" + c.username + "\x01auth=Bearer " + c.token + "\x01\x01"), nil
+}
+
+// Next answers the server's error challenge with an empty response, after which it reports the failure
+func (c *xoauth2Client) Next(challenge []byte) ([]byte, error) {
+	return []byte{}, nil
+}
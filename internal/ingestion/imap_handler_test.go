@@ -0,0 +1,250 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+)
+
+// testEmail builds a multipart message with one attachment
+func testEmail(from, subject, filename, content string) string {
+	return fmt.Sprintf("From: %s\r\n"+
+		"To: jobs@example.com\r\n"+
+		"Subject: %s\r\n"+
+		"Date: Mon, 02 Jun 2025 10:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: multipart/mixed; boundary=\"b1\"\r\n"+
+		"\r\n"+
+		"--b1\r\n"+
+		"Content-Type: text/plain\r\n"+
+		"\r\n"+
+		"Please find my application attached.\r\n"+
+		"--b1\r\n"+
+		"Content-Type: text/plain; name=\"%s\"\r\n"+
+		"Content-Disposition: attachment; filename=\"%s\"\r\n"+
+		"Content-Transfer-Encoding: base64\r\n"+
+		"\r\n"+
+		"%s\r\n"+
+		"--b1--\r\n", from, subject, filename, filename, base64.StdEncoding.EncodeToString([]byte(content)))
+}
+
+// startTestIMAPServer runs an in-process IMAP server over TLS and returns a
+// config for it and the mailbox to add messages to
+func startTestIMAPServer(t *testing.T) (IMAPConfig, backend.Mailbox) {
+	t.Helper()
+
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatalf("Failed to log in to memory backend: %v", err)
+	}
+	mbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatalf("Failed to open INBOX: %v", err)
+	}
+
+	// Borrow httptest's self-signed certificate for 127.0.0.1
+	ts := httptest.NewUnstartedServer(nil)
+	ts.StartTLS()
+	serverTLS := ts.TLS.Clone()
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	ts.Close()
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := server.New(be)
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return IMAPConfig{
+		Host:      host,
+		Port:      portNum,
+		Username:  "username",
+		Password:  "password",
+		Subject:   "Application",
+		TLSConfig: &tls.Config{RootCAs: roots, ServerName: host},
+	}, mbox
+}
+
+// addTestEmail appends a raw message to the mailbox
+func addTestEmail(t *testing.T, mbox backend.Mailbox, raw string) {
+	t.Helper()
+	if err := mbox.CreateMessage(nil, time.Now(), bytes.NewBufferString(raw)); err != nil {
+		t.Fatalf("Failed to add message: %v", err)
+	}
+}
+
+// uploadedNames lists the visible files in dir
+func uploadedNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TestIMAPHandler_FetchesNewMessagesOnly tests search filtering, sender naming and UID tracking
+func TestIMAPHandler_FetchesNewMessagesOnly(t *testing.T) {
+	cfg, mbox := startTestIMAPServer(t)
+	addTestEmail(t, mbox, testEmail("Jane Smith <jane@example.com>", "Application: Engineer", "resume.txt", "Jane Smith CV"))
+	addTestEmail(t, mbox, testEmail("bob@example.com", "Application: Engineer", "../../letter.txt", "Dear Hiring Manager"))
+	addTestEmail(t, mbox, testEmail("news@example.com", "Newsletter", "offers.txt", "Offers"))
+
+	uploadsDir := t.TempDir()
+	handler, err := NewIMAPHandler(cfg, uploadsDir, nil)
+	if err != nil {
+		t.Fatalf("NewIMAPHandler() returned error: %v", err)
+	}
+
+	saved, err := handler.FetchAttachmentsWithContext(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAttachmentsWithContext() returned error: %v", err)
+	}
+	want := []string{"JaneSmith_CV.txt", "bob_CoverLetter.txt"}
+	if got := uploadedNames(t, uploadsDir); saved != 2 || strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expected %v, got %v (saved %d)", want, got, saved)
+	}
+
+	// A second run only downloads messages that arrived since the first
+	saved, err = handler.FetchAttachmentsWithContext(context.Background())
+	if err != nil || saved != 0 {
+		t.Fatalf("Expected no new attachments, got %d (%v)", saved, err)
+	}
+
+	addTestEmail(t, mbox, testEmail("Ann Lee <ann@example.com>", "Application: Engineer", "cv.txt", "Ann Lee CV"))
+	saved, err = handler.FetchAttachmentsWithContext(context.Background())
+	if err != nil || saved != 1 {
+		t.Fatalf("Expected one new attachment, got %d (%v)", saved, err)
+	}
+	if _, err := os.Stat(filepath.Join(uploadsDir, "AnnLee_CV.txt")); err != nil {
+		t.Errorf("Expected AnnLee_CV.txt to be downloaded: %v", err)
+	}
+}
+
+// TestIMAPHandler_DifferentFilters tests that a fetch with other filters still
+// finds older matching messages and skips those an earlier fetch downloaded
+func TestIMAPHandler_DifferentFilters(t *testing.T) {
+	cfg, mbox := startTestIMAPServer(t)
+	addTestEmail(t, mbox, testEmail("Jane Smith <jane@example.com>", "Application: Engineer", "resume.txt", "Jane Smith CV"))
+	addTestEmail(t, mbox, testEmail("Bob Ray <bob@example.com>", "Application: Designer", "resume.txt", "Bob Ray CV"))
+	addTestEmail(t, mbox, testEmail("Ann Lee <ann@example.com>", "Application: Engineer", "resume.txt", "Ann Lee CV"))
+
+	uploadsDir := t.TempDir()
+	fetch := func(subject string) int {
+		t.Helper()
+		cfg.Subject = subject
+		handler, err := NewIMAPHandler(cfg, uploadsDir, nil)
+		if err != nil {
+			t.Fatalf("NewIMAPHandler() returned error: %v", err)
+		}
+		saved, err := handler.FetchAttachmentsWithContext(context.Background())
+		if err != nil {
+			t.Fatalf("FetchAttachmentsWithContext(%q) returned error: %v", subject, err)
+		}
+		return saved
+	}
+
+	// The newest message matches the first fetch; the older designer application must still be found
+	if saved := fetch("Engineer"); saved != 2 {
+		t.Fatalf("Expected 2 engineer applications, got %d", saved)
+	}
+	if saved := fetch("Designer"); saved != 1 {
+		t.Fatalf("Expected the older designer application, got %d", saved)
+	}
+	// Every application was fetched once
+	if saved := fetch("Application"); saved != 0 {
+		t.Errorf("Expected no attachments downloaded twice, got %d", saved)
+	}
+	want := []string{"AnnLee_CV.txt", "BobRay_CV.txt", "JaneSmith_CV.txt"}
+	if got := uploadedNames(t, uploadsDir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestIMAPState_OlderFormat tests that messages up to the LastUID of older state files count as fetched
+func TestIMAPState_OlderFormat(t *testing.T) {
+	fetched := imapMailboxState{UIDValidity: 1, LastUID: 3}.fetchedUIDs()
+	if !fetched.Contains(3) || fetched.Contains(4) {
+		t.Errorf("Expected UIDs 1:3 to count as fetched, got %s", fetched)
+	}
+
+	fetched = imapMailboxState{UIDValidity: 1, Fetched: "2,5:6"}.fetchedUIDs()
+	if fetched.Contains(1) || !fetched.Contains(5) || fetched.String() != "2,5:6" {
+		t.Errorf("Expected UIDs 2,5:6, got %s", fetched)
+	}
+}
+
+// TestIMAPHandler_RejectsBadCredentials tests that login failures are reported
+func TestIMAPHandler_RejectsBadCredentials(t *testing.T) {
+	cfg, _ := startTestIMAPServer(t)
+	cfg.Password = "wrong"
+
+	handler, err := NewIMAPHandler(cfg, t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("NewIMAPHandler() returned error: %v", err)
+	}
+	if _, err := handler.FetchAttachmentsWithContext(context.Background()); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("Expected authentication error, got %v", err)
+	}
+}
+
+// TestIMAPConfig_Validate tests required fields and defaults
+func TestIMAPConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  IMAPConfig
+		wantErr bool
+	}{
+		{"valid", IMAPConfig{Host: "imap.example.com", Username: "jobs", Password: "secret"}, false},
+		{"missing host", IMAPConfig{Username: "jobs", Password: "secret"}, true},
+		{"missing password", IMAPConfig{Host: "imap.example.com", Username: "jobs"}, true},
+		{"unknown security", IMAPConfig{Host: "imap.example.com", Username: "jobs", Password: "secret", Security: "ssl3"}, true},
+		{"xoauth2", IMAPConfig{Host: "outlook.office365.com", Username: "jobs", Password: "token", Auth: IMAPAuthXOAuth2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if cfg := (IMAPConfig{Security: IMAPSecurityStartTLS}).withDefaults(); cfg.Port != 143 || cfg.Folder != "INBOX" {
+		t.Errorf("Unexpected defaults: %+v", cfg)
+	}
+}
+
+// TestXOAuth2Client tests the XOAUTH2 initial response format
+func TestXOAuth2Client(t *testing.T) {
+	mech, ir, _ := (&xoauth2Client{username: "jobs@example.com", token: "abc"}).Start()
+	if mech != "XOAUTH2" || string(ir) != "user=jobs@example.com\x01auth=Bearer abc\x01\x01" {
+		t.Errorf("Unexpected XOAUTH2 response: %s %q", mech, ir)
+	}
+}
@@ -0,0 +1,238 @@
+package ingestion
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/emersion/go-message"
+	_ "github.com/emersion/go-message/charset" // decode non-UTF-8 headers and file names
+	gomail "github.com/emersion/go-message/mail"
+)
+
+const (
+	// maxMailMessageSize is the largest single message read from an .eml or .mbox file
+	maxMailMessageSize = 50 << 20
+	// maxForwardDepth bounds recursion into emails attached to emails
+	maxForwardDepth = 3
+)
+
+// forwardedFromPattern finds the original sender in the body of an inline-forwarded email
+var forwardedFromPattern = regexp.MustCompile(`(?im)^[\s>]*(?:-+\s*(?:forwarded message|original message)\s*-+|begin forwarded message:)\s*$(?:.*\n){0,3}?^[\s>]*\*?From:\*?\s*(.+?)\s*$`)
+
+// forwardSubjectPattern matches the subject prefixes mail clients add when forwarding
+var forwardSubjectPattern = regexp.MustCompile(`(?i)^\s*(?:fwd?|fw)\s*:`)
+
+// IsMailFile reports whether filename is an exported email (.eml) or mailbox archive (.mbox)
+func IsMailFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".eml" || ext == ".mbox"
+}
+
+// ImportMailFile imports an .eml or .mbox file from disk (see ImportMail)
+func (fh *FileHandler) ImportMailFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+	return fh.ImportMail(filepath.Base(path), f)
+}
+
+// ImportMail saves the attachments of an exported email (.eml) or mailbox
+// archive (.mbox) to the uploads directory, named after each sender like Gmail
+// downloads, and returns how many files were saved
+func (fh *FileHandler) ImportMail(filename string, r io.Reader) (int, error) {
+	if err := os.MkdirAll(fh.uploadsDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create uploads directory: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".eml") {
+		return saveMessageAttachments(io.LimitReader(r, maxMailMessageSize), fh.uploadsDir)
+	}
+
+	saved, messages := 0, 0
+	err := readMbox(r, func(msg []byte) {
+		messages++
+		n, err := saveMessageAttachments(bytes.NewReader(msg), fh.uploadsDir)
+		if err != nil {
+			log.Printf("Failed to process message %d of %s: %v", messages, filename, err)
+		}
+		saved += n
+	})
+	if err != nil {
+		return saved, fmt.Errorf("failed to read mailbox %s: %w", filename, err)
+	}
+	if messages == 0 {
+		return 0, fmt.Errorf("no messages found in %s", filename)
+	}
+	log.Printf("Imported %d attachment(s) from %d message(s) in %s", saved, messages, filename)
+	return saved, nil
+}
+
+// readMbox calls fn with each message of an mbox archive. Messages start with a
+// "From " line after a blank line; ">From " escapes in the body are undone.
+// Messages larger than maxMailMessageSize are skipped.
+func readMbox(r io.Reader, fn func(msg []byte)) error {
+	br := bufio.NewReader(r)
+	var msg bytes.Buffer
+	inMessage, tooLarge, prevBlank := false, false, true
+
+	flush := func() {
+		if tooLarge {
+			log.Printf("Skipping mailbox message larger than %d MB", maxMailMessageSize>>20)
+		} else if inMessage && msg.Len() > 0 {
+			fn(msg.Bytes())
+		}
+		msg.Reset()
+		tooLarge = false
+	}
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if prevBlank && bytes.HasPrefix(line, []byte("From ")) {
+				flush()
+				inMessage = true
+			} else if inMessage && !tooLarge {
+				if unquoted := bytes.TrimLeft(line, ">"); len(unquoted) < len(line) && bytes.HasPrefix(unquoted, []byte("From ")) {
+					line = line[1:]
+				}
+				if msg.Len()+len(line) > maxMailMessageSize {
+					tooLarge = true
+				} else {
+					msg.Write(line)
+				}
+			}
+			prevBlank = len(bytes.TrimRight(line, "\r\n")) == 0
+		}
+		if err == io.EOF {
+			flush()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// saveMessageAttachments writes the attachments of a raw RFC 5322 message to
+// dir, named after the sender like Gmail downloads, and returns how many were
+// saved. Attachments of forwarded emails are named after the original sender.
+func saveMessageAttachments(r io.Reader, dir string) (int, error) {
+	saved, err := saveAttachments(r, dir, 0, nil)
+	return len(saved), err
+}
+
+// saveAttachments does the work of saveMessageAttachments and returns the paths
+// written; depth counts the emails this one is attached to. If senders is not
+// nil, it records the address of the sender each path is named after.
+func saveAttachments(r io.Reader, dir string, depth int, senders map[string]string) ([]string, error) {
+	mr, err := gomail.CreateReader(r)
+	if err != nil && (mr == nil || !message.IsUnknownCharset(err)) {
+		return nil, fmt.Errorf("failed to parse email: %w", err)
+	}
+	defer mr.Close()
+
+	senderName, senderAddress := "Unknown", ""
+	if from, err := mr.Header.AddressList("From"); err == nil && len(from) > 0 {
+		senderName, senderAddress = senderNameFromAddress(from[0].Name, from[0].Address), from[0].Address
+	}
+	subject, _ := mr.Header.Subject()
+	forwarded := forwardSubjectPattern.MatchString(subject)
+
+	var saved []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !message.IsUnknownCharset(err) {
+			return saved, fmt.Errorf("failed to read email part: %w", err)
+		}
+
+		switch header := part.Header.(type) {
+		case *gomail.InlineHeader:
+			// "---------- Forwarded message ---------\nFrom: Jane Smith <jane@example.com>"
+			if contentType, _, _ := header.ContentType(); forwarded && contentType == "text/plain" {
+				body, _ := io.ReadAll(io.LimitReader(part.Body, 1<<20))
+				if addr := forwardedSender(string(body)); addr != nil {
+					senderName, senderAddress = senderNameFromAddress(addr.Name, addr.Address), addr.Address
+				}
+			}
+		case *gomail.AttachmentHeader:
+			// An email forwarded as an attachment carries its own sender and attachments
+			if contentType, _, _ := header.ContentType(); contentType == "message/rfc822" && depth < maxForwardDepth {
+				paths, err := saveAttachments(part.Body, dir, depth+1, senders)
+				if err != nil {
+					log.Printf("Failed to process attached email: %v", err)
+				}
+				saved = append(saved, paths...)
+				continue
+			}
+
+			filename, err := header.Filename()
+			if err != nil || filename == "" {
+				continue
+			}
+			name, err := SanitizeUploadName(attachmentFilename(senderName, filename))
+			if err != nil {
+				continue
+			}
+
+			// Attachments are checked like uploads, so e.g. an executable renamed to .pdf is skipped
+			body := bufio.NewReaderSize(part.Body, UploadSniffLength)
+			head, _ := body.Peek(UploadSniffLength)
+			if err := CheckUploadContent(name, head); err != nil {
+				log.Printf("Skipping attachment %s: %v", filename, err)
+				continue
+			}
+
+			filePath := uniqueFilePath(dir, name)
+			if err := writeAttachment(filePath, body); err != nil {
+				return saved, err
+			}
+			log.Printf("Downloaded: %s", filepath.Base(filePath))
+			saved = append(saved, filePath)
+			if senders != nil {
+				senders[filePath] = senderAddress
+			}
+		}
+	}
+	return saved, nil
+}
+
+// forwardedSender returns the original sender quoted in a forwarded email body, or nil
+func forwardedSender(body string) *mail.Address {
+	match := forwardedFromPattern.FindStringSubmatch(strings.ReplaceAll(body, "\r\n", "\n"))
+	if match == nil {
+		return nil
+	}
+	addr, err := mail.ParseAddress(match[1])
+	if err != nil {
+		return nil
+	}
+	return addr
+}
+
+// writeAttachment copies an attachment body to filePath
+func writeAttachment(filePath string, body io.Reader) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to write file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("unable to write file: %w", err)
+	}
+	return nil
+}
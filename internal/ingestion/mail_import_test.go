@@ -0,0 +1,141 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestImportMail_EML tests that a single exported email is saved under the sender's name
+func TestImportMail_EML(t *testing.T) {
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+
+	raw := testEmail("Jane Smith <jane@example.com>", "Application", "resume.txt", "Jane Smith CV")
+	saved, err := fh.ImportMail("application.eml", strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ImportMail() returned error: %v", err)
+	}
+	if got := uploadedNames(t, uploadsDir); saved != 1 || strings.Join(got, ",") != "JaneSmith_CV.txt" {
+		t.Errorf("Expected JaneSmith_CV.txt, got %v (saved %d)", got, saved)
+	}
+}
+
+// TestImportMail_ChecksAttachments tests that attachments whose content does
+// not match the extension are skipped
+func TestImportMail_ChecksAttachments(t *testing.T) {
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+
+	raw := testEmail("Jane Smith <jane@example.com>", "Application", "resume.pdf", "MZ\x90\x00 executable renamed to .pdf")
+	saved, err := fh.ImportMail("application.eml", strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ImportMail() returned error: %v", err)
+	}
+	if got := uploadedNames(t, uploadsDir); saved != 0 || len(got) != 0 {
+		t.Errorf("Expected the attachment to be skipped, got %v (saved %d)", got, saved)
+	}
+}
+
+// TestImportMail_Mbox tests splitting an mbox archive and unescaping ">From " lines
+func TestImportMail_Mbox(t *testing.T) {
+	uploadsDir := t.TempDir()
+	fh := NewFileHandler(uploadsDir)
+
+	letter := testEmail("bob@example.com", "Application", "letter.txt", "Dear Hiring Manager")
+	letter = strings.Replace(letter, "Please find my application attached.", ">From the job board:", 1)
+	mbox := "From jane@example.com Mon Jun  2 10:00:00 2025\n" +
+		testEmail("Jane Smith <jane@example.com>", "Application", "cv.txt", "Jane Smith CV") + "\n" +
+		"From bob@example.com Mon Jun  2 11:00:00 2025\n" +
+		letter + "\n" +
+		"From empty@example.com Mon Jun  2 12:00:00 2025\n" +
+		"From: empty@example.com\r\nSubject: No attachment\r\n\r\nHello\r\n"
+
+	var bodies []string
+	if err := readMbox(strings.NewReader(mbox), func(msg []byte) { bodies = append(bodies, string(msg)) }); err != nil {
+		t.Fatalf("readMbox() returned error: %v", err)
+	}
+	if len(bodies) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(bodies))
+	}
+	if !strings.Contains(bodies[1], "\nFrom the job board:") {
+		t.Errorf("Expected escaped From line to be restored, got %q", bodies[1])
+	}
+
+	saved, err := fh.ImportMail("export.mbox", strings.NewReader(mbox))
+	if err != nil {
+		t.Fatalf("ImportMail() returned error: %v", err)
+	}
+	want := []string{"JaneSmith_CV.txt", "bob_CoverLetter.txt"}
+	if got := uploadedNames(t, uploadsDir); saved != 2 || strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v (saved %d)", want, got, saved)
+	}
+
+	if _, err := fh.ImportMail("empty.mbox", strings.NewReader("not a mailbox")); err == nil {
+		t.Error("Expected error for mailbox without messages")
+	}
+}
+
+// TestImportMail_Forwarded tests that forwarded applications are named after the original sender
+func TestImportMail_Forwarded(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "attached email",
+			raw: "From: Recruiter <hr@example.com>\r\n" +
+				"Subject: Fwd: Application\r\n" +
+				"MIME-Version: 1.0\r\n" +
+				"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+				"\r\n" +
+				"--outer\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"See attached.\r\n" +
+				"--outer\r\n" +
+				"Content-Type: message/rfc822\r\n" +
+				"Content-Disposition: attachment; filename=\"application.eml\"\r\n" +
+				"\r\n" +
+				testEmail("Ann Lee <ann@example.com>", "Application", "cv.txt", "Ann Lee CV") +
+				"--outer--\r\n",
+			want: "AnnLee_CV.txt",
+		},
+		{
+			name: "inline forward",
+			raw: strings.Replace(
+				testEmail("Recruiter <hr@example.com>", "Fwd: Application", "cv.txt", "Ann Lee CV"),
+				"Please find my application attached.",
+				"---------- Forwarded message ---------\r\nFrom: Ann Lee <ann@example.com>\r\nDate: Mon, 2 Jun 2025\r\nSubject: Application", 1),
+			want: "AnnLee_CV.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploadsDir := t.TempDir()
+			if _, err := NewFileHandler(uploadsDir).ImportMail("message.eml", strings.NewReader(tt.raw)); err != nil {
+				t.Fatalf("ImportMail() returned error: %v", err)
+			}
+			if got := uploadedNames(t, uploadsDir); strings.Join(got, ",") != tt.want {
+				t.Errorf("Expected %s, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestImportMailFile tests importing an .eml file from disk
+func TestImportMailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application.EML")
+	os.WriteFile(path, []byte(testEmail("jane@example.com", "Application", "cv.txt", "CV")), 0644)
+
+	if !IsMailFile(path) || IsMailFile("cv.pdf") {
+		t.Error("IsMailFile() misclassified files")
+	}
+	uploadsDir := t.TempDir()
+	if saved, err := NewFileHandler(uploadsDir).ImportMailFile(path); err != nil || saved != 1 {
+		t.Errorf("Expected one attachment, got %d (%v)", saved, err)
+	}
+}
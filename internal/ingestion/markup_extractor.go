@@ -0,0 +1,218 @@
+package ingestion
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// htmlBlockElements start a new line in extracted text
+var htmlBlockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true,
+	"footer": true, "aside": true, "main": true, "nav": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "ul": true,
+	"ol": true, "li": true, "table": true, "tr": true, "dl": true, "dt": true,
+	"dd": true, "blockquote": true, "pre": true, "address": true, "hr": true,
+	"form": true, "fieldset": true, "figure": true, "figcaption": true,
+}
+
+// htmlSkipElements never contain visible document text
+var htmlSkipElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true,
+	"template": true, "svg": true, "iframe": true, "object": true,
+}
+
+// extractHTML extracts visible text from an HTML document
+func extractHTML(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open HTML file: %w", err)
+	}
+	defer f.Close()
+
+	text, err := htmlToText(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML file: %w", err)
+	}
+
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// htmlToText tokenizes HTML and emits visible text with block elements on separate lines
+func htmlToText(r io.Reader) (string, error) {
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(r)
+	skipDepth := 0
+	preDepth := 0
+	cellIndex := 0
+
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return "", err
+			}
+			return normalizeExtractedText(sb.String()), nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+
+			if htmlSkipElements[tag] {
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			switch {
+			case tag == "br":
+				sb.WriteByte('\n')
+			case tag == "tr":
+				cellIndex = 0
+				sb.WriteByte('\n')
+			case tag == "td" || tag == "th":
+				if cellIndex > 0 {
+					sb.WriteByte('\t')
+				}
+				cellIndex++
+			case tag == "li":
+				sb.WriteString("\n- ")
+			case htmlBlockElements[tag]:
+				sb.WriteByte('\n')
+			}
+			if tag == "pre" && tt == html.StartTagToken {
+				preDepth++
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+
+			if htmlSkipElements[tag] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			if tag == "pre" && preDepth > 0 {
+				preDepth--
+			}
+			// List items already start on a new line
+			if htmlBlockElements[tag] && tag != "li" {
+				sb.WriteByte('\n')
+			}
+
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			text := string(tokenizer.Text())
+			if preDepth == 0 {
+				// Collapse whitespace as a browser would
+				text = strings.Join(strings.Fields(text), " ")
+				if text == "" {
+					continue
+				}
+				if sb.Len() > 0 {
+					last := sb.String()[sb.Len()-1]
+					if last != '\n' && last != '\t' && last != ' ' {
+						sb.WriteByte(' ')
+					}
+				}
+			}
+			sb.WriteString(text)
+		}
+	}
+}
+
+var (
+	mdFencePattern    = regexp.MustCompile("^\\s*(```|~~~)")
+	mdHeadingPattern  = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	mdListPattern     = regexp.MustCompile(`^(\s*)([*+-]|\d+[.)])\s+`)
+	mdQuotePattern    = regexp.MustCompile(`^\s*>\s?`)
+	mdRulePattern     = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	mdImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdEmphasisPattern = regexp.MustCompile(`(\*\*|__|\*|_|~~)([^*_~\s](?:[^*_~]*[^*_~\s])?)(\*\*|__|\*|_|~~)`)
+	mdCodePattern     = regexp.MustCompile("`([^`]*)`")
+	mdTagPattern      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdTableSepPattern = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// extractMarkdown extracts plain text from a Markdown document
+func extractMarkdown(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open Markdown file: %w", err)
+	}
+
+	text := markdownToText(string(data))
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// markdownToText strips Markdown syntax while keeping headings, lists, tables and link targets readable
+func markdownToText(md string) string {
+	var sb strings.Builder
+	inFence := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		if mdFencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+			continue
+		}
+
+		if mdRulePattern.MatchString(line) || mdTableSepPattern.MatchString(line) && strings.Contains(line, "-") && strings.Contains(line, "|") {
+			sb.WriteByte('\n')
+			continue
+		}
+
+		line = mdHeadingPattern.ReplaceAllString(line, "")
+		line = mdQuotePattern.ReplaceAllString(line, "")
+		line = mdListPattern.ReplaceAllString(line, "$1- ")
+		line = mdImagePattern.ReplaceAllString(line, "$1")
+		line = mdLinkPattern.ReplaceAllString(line, "$1 ($2)")
+		line = mdCodePattern.ReplaceAllString(line, "$1")
+		line = mdEmphasisPattern.ReplaceAllString(line, "$2")
+		line = mdTagPattern.ReplaceAllString(line, "")
+
+		// Table rows become tab-separated cells
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|") && len(trimmed) > 1 {
+			cells := strings.Split(trimmed[1:len(trimmed)-1], "|")
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			line = strings.Join(cells, "\t")
+		}
+
+		sb.WriteString(html.UnescapeString(line))
+		sb.WriteByte('\n')
+	}
+
+	return normalizeExtractedText(sb.String())
+}
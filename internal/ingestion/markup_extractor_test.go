@@ -0,0 +1,81 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHTMLToText tests visible text extraction from HTML
+func TestHTMLToText(t *testing.T) {
+	doc := `<!DOCTYPE html><html><head><title>CV</title><style>p{color:red}</style></head>
+<body>
+  <h1>Jane   Smith</h1>
+  <p>Senior Engineer &amp; Team Lead<br>jane@example.com</p>
+  <script>var tracking = 1;</script>
+  <ul><li>Go</li><li>Python</li></ul>
+  <table><tr><td>2018</td><td>Acme Corp</td></tr></table>
+</body></html>`
+
+	text, err := htmlToText(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("htmlToText() returned error: %v", err)
+	}
+
+	for _, want := range []string{"Jane Smith\n", "Senior Engineer & Team Lead\njane@example.com", "- Go\n- Python", "2018\tAcme Corp"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("htmlToText() missing %q, got:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"tracking", "color:red", "<title>"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("htmlToText() should not contain %q, got:\n%s", unwanted, text)
+		}
+	}
+}
+
+// TestMarkdownToText tests stripping of Markdown syntax
+func TestMarkdownToText(t *testing.T) {
+	md := "# Jane Smith\n\n**Senior Engineer** at [Acme](https://acme.example)\n\n" +
+		"## Skills\n* Go\n* `Kubernetes`\n\n| Year | Company |\n|------|---------|\n| 2018 | Acme |\n\n---\n> Available immediately\n"
+
+	text := markdownToText(md)
+
+	for _, want := range []string{"Jane Smith\n", "Senior Engineer at Acme (https://acme.example)", "- Go\n- Kubernetes", "2018\tAcme", "Available immediately"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("markdownToText() missing %q, got:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"#", "**", "|---"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("markdownToText() should not contain %q, got:\n%s", unwanted, text)
+		}
+	}
+}
+
+// TestLoadDocuments_MarkupFormats tests that HTML and Markdown CVs are loaded as plain text
+func TestLoadDocuments_MarkupFormats(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_CV.html"),
+		[]byte("<html><body><h1>Jane Smith</h1><p>Senior Engineer with ten years of experience</p></body></html>"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_CoverLetter.md"),
+		[]byte("# Dear Hiring Manager\n\nI am **very** excited to apply for the Senior Engineer position.\n"), 0644)
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 document, got %d", len(docs))
+	}
+
+	if strings.Contains(docs[0].CV().Content, "<h1>") || !strings.Contains(docs[0].CV().Content, "Jane Smith") {
+		t.Errorf("CV content should be plain text, got %q", docs[0].CV().Content)
+	}
+	if strings.Contains(docs[0].CoverLetter().Content, "**") || !strings.Contains(docs[0].CoverLetter().Content, "very excited") {
+		t.Errorf("Cover letter should be plain text, got %q", docs[0].CoverLetter().Content)
+	}
+}
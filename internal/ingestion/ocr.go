@@ -0,0 +1,228 @@
+package ingestion
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// OCRLowConfidenceThreshold is the page confidence (0-100) below which a document is flagged for manual review
+	OCRLowConfidenceThreshold = 60.0
+	// ocrRenderDPI is the resolution used to rasterize scanned PDF pages
+	ocrRenderDPI = 300
+)
+
+// OCRPage holds the recognition result for a single page or image
+type OCRPage struct {
+	Number     int     `json:"number"`
+	Text       string  `json:"-"`
+	Confidence float64 `json:"confidence"` // 0-100
+}
+
+// OCRResult holds the recognized text of a document with per-page confidence
+type OCRResult struct {
+	Engine string    `json:"engine"`
+	Pages  []OCRPage `json:"pages"`
+}
+
+// Text joins the recognized text of all pages
+func (r *OCRResult) Text() string {
+	parts := make([]string, 0, len(r.Pages))
+	for _, p := range r.Pages {
+		if p.Text != "" {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// LowConfidencePages returns the pages whose confidence is below the threshold
+func (r *OCRResult) LowConfidencePages(threshold float64) []OCRPage {
+	var low []OCRPage
+	for _, p := range r.Pages {
+		if p.Confidence < threshold {
+			low = append(low, p)
+		}
+	}
+	return low
+}
+
+// OCREngine recognizes text in a single image file. The default engine runs the
+// local Tesseract binary; other engines (e.g. a multimodal LLM) can be plugged in
+// with SetOCREngine.
+type OCREngine interface {
+	Name() string
+	RecognizeImage(imagePath string) (OCRPage, error)
+}
+
+var (
+	ocrMu     sync.RWMutex
+	ocrEngine OCREngine = &TesseractEngine{Language: "eng"}
+	// pdfRasterizer renders PDF pages to images for OCR; replaceable in tests
+	pdfRasterizer = rasterizePDF
+)
+
+// SetOCREngine replaces the engine used for scanned PDFs and image uploads
+func SetOCREngine(engine OCREngine) {
+	ocrMu.Lock()
+	defer ocrMu.Unlock()
+	ocrEngine = engine
+}
+
+// currentOCREngine returns the configured OCR engine
+func currentOCREngine() OCREngine {
+	ocrMu.RLock()
+	defer ocrMu.RUnlock()
+	return ocrEngine
+}
+
+// TesseractEngine runs the tesseract command line tool
+type TesseractEngine struct {
+	// Language is the tesseract language code (e.g. "eng", "eng+fra")
+	Language string
+}
+
+// Name returns the engine name recorded in OCR results
+func (e *TesseractEngine) Name() string {
+	return "tesseract"
+}
+
+// RecognizeImage runs tesseract in TSV mode to obtain text and word confidences
+func (e *TesseractEngine) RecognizeImage(imagePath string) (OCRPage, error) {
+	args := []string{imagePath, "stdout"}
+	if e.Language != "" {
+		args = append(args, "-l", e.Language)
+	}
+	args = append(args, "tsv")
+
+	cmd := exec.Command("tesseract", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return OCRPage{}, fmt.Errorf("OCR requires 'tesseract' (install tesseract-ocr): %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTesseractTSV(output), nil
+}
+
+// parseTesseractTSV rebuilds text lines from tesseract TSV output and averages word confidence
+func parseTesseractTSV(tsv []byte) OCRPage {
+	var sb strings.Builder
+	var confSum float64
+	words := 0
+	lastBlock, lastLine := "", ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(tsv))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// level page block par line word left top width height conf text
+		fields := strings.SplitN(scanner.Text(), "\t", 12)
+		if len(fields) < 12 || fields[0] != "5" {
+			continue
+		}
+
+		conf, err := strconv.ParseFloat(fields[10], 64)
+		text := strings.TrimSpace(fields[11])
+		if err != nil || conf < 0 || text == "" {
+			continue
+		}
+
+		block := fields[2] + "." + fields[3]
+		line := block + "." + fields[4]
+		switch {
+		case sb.Len() == 0:
+		case block != lastBlock:
+			sb.WriteString("\n\n")
+		case line != lastLine:
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(' ')
+		}
+		lastBlock, lastLine = block, line
+
+		sb.WriteString(text)
+		confSum += conf
+		words++
+	}
+
+	page := OCRPage{Number: 1, Text: sb.String()}
+	if words > 0 {
+		page.Confidence = confSum / float64(words)
+	}
+	return page
+}
+
+// recognizeImage runs OCR on a single image upload (.png, .jpg, ...)
+func recognizeImage(filePath string) (*OCRResult, error) {
+	engine := currentOCREngine()
+	page, err := engine.RecognizeImage(filePath)
+	if err != nil {
+		return nil, err
+	}
+	page.Number = 1
+
+	result := &OCRResult{Engine: engine.Name(), Pages: []OCRPage{page}}
+	if len(result.Text()) < MinExtractedTextLength {
+		return nil, fmt.Errorf("OCR found no readable text in: %s", filePath)
+	}
+	return result, nil
+}
+
+// recognizePDF rasterizes each page of an image-only PDF and runs OCR on it
+func recognizePDF(filePath string) (*OCRResult, error) {
+	tmpDir, err := os.MkdirTemp("", "cv-ocr-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCR work directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	images, err := pdfRasterizer(filePath, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no pages rendered from PDF: %s", filePath)
+	}
+
+	engine := currentOCREngine()
+	result := &OCRResult{Engine: engine.Name()}
+	for i, image := range images {
+		page, err := engine.RecognizeImage(image)
+		if err != nil {
+			return nil, fmt.Errorf("OCR failed on page %d: %w", i+1, err)
+		}
+		page.Number = i + 1
+		result.Pages = append(result.Pages, page)
+	}
+
+	if len(result.Text()) < MinExtractedTextLength {
+		return nil, fmt.Errorf("OCR found no readable text in: %s", filePath)
+	}
+	return result, nil
+}
+
+// rasterizePDF renders PDF pages to PNG files using pdftoppm (poppler-utils)
+func rasterizePDF(filePath, outDir string) ([]string, error) {
+	prefix := filepath.Join(outDir, "page")
+	cmd := exec.Command("pdftoppm", "-r", strconv.Itoa(ocrRenderDPI), "-png", filePath, prefix)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("PDF rasterization requires 'pdftoppm' (install poppler-utils): %w\n%s", err, strings.TrimSpace(string(output)))
+	}
+
+	images, err := filepath.Glob(prefix + "*.png")
+	if err != nil {
+		return nil, err
+	}
+	// pdftoppm zero-pads page numbers, so lexical order is page order
+	sort.Strings(images)
+	return images, nil
+}
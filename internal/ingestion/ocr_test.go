@@ -0,0 +1,126 @@
+package ingestion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeOCREngine returns canned text and confidence per image file name
+type fakeOCREngine struct {
+	pages map[string]OCRPage
+}
+
+func (e *fakeOCREngine) Name() string { return "fake" }
+
+func (e *fakeOCREngine) RecognizeImage(imagePath string) (OCRPage, error) {
+	page, ok := e.pages[filepath.Base(imagePath)]
+	if !ok {
+		return OCRPage{}, fmt.Errorf("no canned result for %s", imagePath)
+	}
+	return page, nil
+}
+
+// useFakeOCR installs a fake engine and rasterizer for the duration of a test
+func useFakeOCR(t *testing.T, engine *fakeOCREngine, pageImages []string) {
+	t.Helper()
+	prevEngine := currentOCREngine()
+	prevRasterizer := pdfRasterizer
+
+	SetOCREngine(engine)
+	pdfRasterizer = func(filePath, outDir string) ([]string, error) {
+		return pageImages, nil
+	}
+
+	t.Cleanup(func() {
+		SetOCREngine(prevEngine)
+		pdfRasterizer = prevRasterizer
+	})
+}
+
+// TestParseTesseractTSV tests that words are rebuilt into lines and confidence is averaged
+func TestParseTesseractTSV(t *testing.T) {
+	tsv := strings.Join([]string{
+		"level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext",
+		"1\t1\t0\t0\t0\t0\t0\t0\t100\t100\t-1\t",
+		"5\t1\t1\t1\t1\t1\t0\t0\t10\t10\t90\tJane",
+		"5\t1\t1\t1\t1\t2\t0\t0\t10\t10\t80\tSmith",
+		"5\t1\t1\t1\t2\t1\t0\t0\t10\t10\t70\tEngineer",
+		"5\t1\t2\t1\t1\t1\t0\t0\t10\t10\t60\tExperience",
+		"5\t1\t2\t1\t1\t2\t0\t0\t10\t10\t-1\t ",
+	}, "\n")
+
+	page := parseTesseractTSV([]byte(tsv))
+
+	expected := "Jane Smith\nEngineer\n\nExperience"
+	if page.Text != expected {
+		t.Errorf("Expected text %q, got %q", expected, page.Text)
+	}
+	if page.Confidence != 75 {
+		t.Errorf("Expected confidence 75, got %v", page.Confidence)
+	}
+}
+
+// TestRecognizePDF_PerPageConfidence tests that each rasterized page is recognized and numbered
+func TestRecognizePDF_PerPageConfidence(t *testing.T) {
+	useFakeOCR(t, &fakeOCREngine{pages: map[string]OCRPage{
+		"page-1.png": {Text: "Jane Smith, Senior Engineer with ten years of experience", Confidence: 92},
+		"page-2.png": {Text: "Education: BSc Computer Science", Confidence: 41},
+	}}, []string{"/tmp/page-1.png", "/tmp/page-2.png"})
+
+	result, err := recognizePDF("JaneSmith_CV.pdf")
+	if err != nil {
+		t.Fatalf("recognizePDF() returned error: %v", err)
+	}
+
+	if len(result.Pages) != 2 || result.Pages[0].Number != 1 || result.Pages[1].Number != 2 {
+		t.Fatalf("Expected pages numbered 1 and 2, got %+v", result.Pages)
+	}
+	if !strings.Contains(result.Text(), "BSc Computer Science") {
+		t.Errorf("Expected text from all pages, got %q", result.Text())
+	}
+
+	low := result.LowConfidencePages(OCRLowConfidenceThreshold)
+	if len(low) != 1 || low[0].Number != 2 {
+		t.Errorf("Expected page 2 to be low confidence, got %+v", low)
+	}
+}
+
+// TestLoadDocuments_ImageCVFlaggedForReview tests that a low-confidence scan is loaded and flagged
+func TestLoadDocuments_ImageCVFlaggedForReview(t *testing.T) {
+	tmpDir := t.TempDir()
+	useFakeOCR(t, &fakeOCREngine{pages: map[string]OCRPage{
+		"JaneSmith_CV.png": {Text: "Jane Smith, Senior Engineer with ten years of experience", Confidence: 48},
+		"JohnDoe_CV.jpg":   {Text: "John Doe, Project Manager with eight years of delivery", Confidence: 95},
+	}}, nil)
+
+	os.WriteFile(filepath.Join(tmpDir, "JaneSmith_CV.png"), []byte("\x89PNG\r\n\x1a\nimage-data"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "JohnDoe_CV.jpg"), []byte("\xFF\xD8\xFF\xE0image-data"), 0644)
+
+	fh := NewFileHandler(tmpDir)
+	docs, err := fh.LoadDocuments()
+	if err != nil {
+		t.Fatalf("Failed to load documents: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(docs))
+	}
+
+	for _, doc := range docs {
+		if len(doc.OCRPages) != 1 {
+			t.Errorf("%s: expected 1 OCR page, got %d", doc.Name, len(doc.OCRPages))
+		}
+		switch doc.Name {
+		case "JaneSmith":
+			if len(doc.ReviewReasons) != 1 || !strings.Contains(doc.ReviewReasons[0], "JaneSmith_CV.png") {
+				t.Errorf("Expected low-confidence review reason, got %v", doc.ReviewReasons)
+			}
+		case "JohnDoe":
+			if len(doc.ReviewReasons) != 0 {
+				t.Errorf("High-confidence scan should not be flagged, got %v", doc.ReviewReasons)
+			}
+		}
+	}
+}
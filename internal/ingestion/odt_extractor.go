@@ -0,0 +1,155 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxODTContentSize limits how much of content.xml is read to guard against zip bombs
+const maxODTContentSize = 50 << 20
+
+// extractODT extracts text from an OpenDocument text (.odt) file
+func extractODT(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ODT file: %w", err)
+	}
+	defer r.Close()
+
+	var content *zip.File
+	for _, f := range r.File {
+		if f.Name == "content.xml" {
+			content = f
+			break
+		}
+	}
+	if content == nil {
+		return "", fmt.Errorf("content.xml not found in ODT file: %s", filePath)
+	}
+
+	rc, err := content.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read ODT content: %w", err)
+	}
+	defer rc.Close()
+
+	text, err := odfXMLToText(io.LimitReader(rc, maxODTContentSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ODT content: %w", err)
+	}
+
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// odfXMLToText walks OpenDocument XML and emits paragraphs, table rows and cells as plain text
+func odfXMLToText(r io.Reader) (string, error) {
+	const textNS = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	const tableNS = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+
+	var sb strings.Builder
+	decoder := xml.NewDecoder(r)
+	cellIndex := 0
+	cellParas := 0
+	// paraDepth tracks open text:p/text:h elements; text outside them is markup whitespace
+	paraDepth := 0
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == textNS && (t.Name.Local == "p" || t.Name.Local == "h"):
+				paraDepth++
+				// Paragraphs inside a table cell are joined with spaces
+				if cellIndex > 0 {
+					if cellParas > 0 {
+						sb.WriteByte(' ')
+					}
+					cellParas++
+				}
+			case t.Name.Space == textNS && t.Name.Local == "tab":
+				sb.WriteByte('\t')
+			case t.Name.Space == textNS && t.Name.Local == "line-break":
+				sb.WriteByte('\n')
+			case t.Name.Space == textNS && t.Name.Local == "s":
+				// text:s collapses runs of spaces; text:c holds the count
+				count := 1
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "c" {
+						if n, err := strconv.Atoi(attr.Value); err == nil && n > 0 {
+							count = n
+						}
+					}
+				}
+				sb.WriteString(strings.Repeat(" ", count))
+			case t.Name.Space == textNS && t.Name.Local == "list-item":
+				sb.WriteString("- ")
+			case t.Name.Space == tableNS && t.Name.Local == "table-row":
+				cellIndex = 0
+			case t.Name.Space == tableNS && t.Name.Local == "table-cell":
+				if cellIndex > 0 {
+					sb.WriteByte('\t')
+				}
+				cellIndex++
+				cellParas = 0
+			case t.Name.Space == textNS && (t.Name.Local == "note" || t.Name.Local == "tracked-changes"):
+				// Footnotes and revision history are not part of the main text
+				if err := decoder.Skip(); err != nil {
+					return "", err
+				}
+			}
+		case xml.EndElement:
+			switch {
+			case t.Name.Space == textNS && (t.Name.Local == "p" || t.Name.Local == "h"):
+				paraDepth--
+				if cellIndex == 0 {
+					sb.WriteByte('\n')
+				}
+			case t.Name.Space == tableNS && t.Name.Local == "table-row":
+				sb.WriteByte('\n')
+				cellIndex = 0
+			}
+		case xml.CharData:
+			if paraDepth > 0 {
+				sb.Write(t)
+			}
+		}
+	}
+
+	return normalizeExtractedText(sb.String()), nil
+}
+
+// normalizeExtractedText trims trailing whitespace from each line and collapses blank line runs
+func normalizeExtractedText(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
@@ -0,0 +1,66 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestODT creates a minimal OpenDocument text file with the given body XML
+func writeTestODT(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "JaneSmith_CV.odt")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create ODT: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	w.Write([]byte("application/vnd.oasis.opendocument.text"))
+	w, _ = zw.Create("content.xml")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+  xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+  xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0">
+  <office:body>
+    <office:text>` + body + `</office:text>
+  </office:body>
+</office:document-content>`))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write ODT: %v", err)
+	}
+	return path
+}
+
+// TestExtractODT tests paragraphs, spacing and tables in ODT documents
+func TestExtractODT(t *testing.T) {
+	path := writeTestODT(t, `
+      <text:h text:outline-level="1">Jane Smith</text:h>
+      <text:p>Senior Engineer<text:tab/>Zürich</text:p>
+      <text:p>Ten<text:s text:c="2"/>years of experience<text:note><text:note-body><text:p>footnote</text:p></text:note-body></text:note></text:p>
+      <table:table>
+        <table:table-row>
+          <table:table-cell><text:p>2018 - 2024</text:p></table:table-cell>
+          <table:table-cell><text:p>Acme Corp</text:p></table:table-cell>
+        </table:table-row>
+      </table:table>`)
+
+	text, err := ExtractText(path)
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+
+	for _, want := range []string{"Jane Smith\n", "Senior Engineer\tZürich", "Ten  years of experience", "2018 - 2024\tAcme Corp"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Extracted text missing %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "footnote") {
+		t.Errorf("Footnotes should be skipped, got:\n%s", text)
+	}
+}
@@ -0,0 +1,199 @@
+package ingestion
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// rtfSkipDestinations are RTF groups that hold metadata rather than document text
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl":      true,
+	"colortbl":     true,
+	"stylesheet":   true,
+	"info":         true,
+	"pict":         true,
+	"object":       true,
+	"listtable":    true,
+	"themedata":    true,
+	"datastore":    true,
+	"latentstyles": true,
+	"generator":    true,
+	"xmlnstbl":     true,
+	"rsidtbl":      true,
+	"fldinst":      true,
+}
+
+// rtfState is the formatting state saved and restored at group boundaries
+type rtfState struct {
+	skip        bool
+	ucSkip      int
+	destChecked bool
+}
+
+// extractRTF extracts text from an RTF document
+func extractRTF(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open RTF file: %w", err)
+	}
+
+	if !strings.HasPrefix(string(data), `{\rtf`) {
+		return "", fmt.Errorf("invalid RTF file: %s", filePath)
+	}
+
+	text := rtfToText(string(data))
+	if len(text) < MinExtractedTextLength {
+		return "", fmt.Errorf("extracted text is too short (likely failed extraction) from: %s", filePath)
+	}
+
+	return text, nil
+}
+
+// rtfToText converts RTF markup to plain text, skipping metadata destinations
+func rtfToText(rtf string) string {
+	var sb strings.Builder
+	stack := []rtfState{}
+	state := rtfState{ucSkip: 1}
+	// pendingSkip counts fallback characters to drop after a \uN escape
+	pendingSkip := 0
+
+	writeRune := func(r rune) {
+		if state.skip {
+			return
+		}
+		if pendingSkip > 0 {
+			pendingSkip--
+			return
+		}
+		sb.WriteRune(r)
+	}
+
+	for i := 0; i < len(rtf); i++ {
+		ch := rtf[i]
+		switch ch {
+		case '{':
+			stack = append(stack, state)
+			state.destChecked = false
+		case '}':
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			pendingSkip = 0
+		case '\r', '\n':
+			// Line breaks in RTF source are not significant
+		case '\\':
+			if i+1 >= len(rtf) {
+				break
+			}
+			next := rtf[i+1]
+
+			switch {
+			case next == '\\' || next == '{' || next == '}':
+				writeRune(rune(next))
+				i++
+			case next == '*':
+				// Ignorable destination: skip the group unless we understand it
+				state.skip = true
+				i++
+			case next == '\'':
+				// Hex-encoded Windows-1252 character
+				if i+3 < len(rtf) {
+					if v, err := strconv.ParseUint(rtf[i+2:i+4], 16, 8); err == nil {
+						b := byte(v)
+						if b >= 0x80 && b <= 0x9F {
+							writeRune(cp1252High[b-0x80])
+						} else {
+							writeRune(rune(b))
+						}
+					}
+				}
+				i += 3
+			case next == '~':
+				writeRune(' ')
+				i++
+			case next == '_':
+				writeRune('-')
+				i++
+			case next == '\r' || next == '\n':
+				writeRune('\n')
+				i++
+			case isASCIILetter(next):
+				j := i + 1
+				for j < len(rtf) && isASCIILetter(rtf[j]) {
+					j++
+				}
+				word := rtf[i+1 : j]
+
+				// Optional numeric parameter
+				k := j
+				if k < len(rtf) && rtf[k] == '-' {
+					k++
+				}
+				for k < len(rtf) && rtf[k] >= '0' && rtf[k] <= '9' {
+					k++
+				}
+				param, hasParam := 0, k > j
+				if hasParam {
+					param, _ = strconv.Atoi(rtf[j:k])
+				}
+				// A single space delimiter belongs to the control word
+				if k < len(rtf) && rtf[k] == ' ' {
+					k++
+				}
+				i = k - 1
+
+				if !state.destChecked {
+					state.destChecked = true
+					if rtfSkipDestinations[word] {
+						state.skip = true
+					}
+				}
+
+				switch word {
+				case "par", "line", "sect", "page", "row":
+					writeRune('\n')
+				case "tab", "cell":
+					writeRune('\t')
+				case "emdash":
+					writeRune('—')
+				case "endash":
+					writeRune('–')
+				case "bullet":
+					writeRune('•')
+				case "lquote":
+					writeRune('‘')
+				case "rquote":
+					writeRune('’')
+				case "ldblquote":
+					writeRune('“')
+				case "rdblquote":
+					writeRune('”')
+				case "uc":
+					if hasParam {
+						state.ucSkip = param
+					}
+				case "u":
+					if hasParam {
+						if param < 0 {
+							param += 65536
+						}
+						writeRune(rune(param))
+						pendingSkip = state.ucSkip
+					}
+				}
+			}
+		default:
+			writeRune(rune(ch))
+		}
+	}
+
+	return normalizeExtractedText(sb.String())
+}
+
+// isASCIILetter reports whether b is an ASCII letter
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
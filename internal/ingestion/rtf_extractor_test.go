@@ -0,0 +1,46 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRTFToText tests conversion of common RTF constructs
+func TestRTFToText(t *testing.T) {
+	rtf := `{\rtf1\ansi\deff0{\fonttbl{\f0 Times New Roman;}}{\colortbl;\red0\green0\blue0;}` +
+		`{\*\generator Riched20;}\pard\b Jane Smith\b0\par ` +
+		`Senior Engineer \endash  Z\'fcrich\par ` +
+		`{\field{\*\fldinst HYPERLINK "mailto:jane@example.com"}{\fldrslt jane@example.com}}\par ` +
+		`Skills:\tab Go\tab Python\par ` +
+		`Caf\u233?\par}`
+
+	text := rtfToText(rtf)
+
+	for _, want := range []string{"Jane Smith\n", "Senior Engineer – Zürich", "jane@example.com", "Skills:\tGo\tPython", "Café"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("rtfToText() missing %q, got:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"Times New Roman", "Riched20", "HYPERLINK", "red0"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("rtfToText() should not contain %q, got:\n%s", unwanted, text)
+		}
+	}
+}
+
+// TestExtractRTF tests RTF extraction through ExtractText
+func TestExtractRTF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "JaneSmith_CV.rtf")
+	rtf := `{\rtf1\ansi Jane Smith\par Senior Engineer with ten years of experience in distributed systems\par}`
+	os.WriteFile(path, []byte(rtf), 0644)
+
+	text, err := ExtractText(path)
+	if err != nil {
+		t.Fatalf("ExtractText() returned error: %v", err)
+	}
+	if !strings.HasPrefix(text, "Jane Smith\nSenior Engineer") {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+}
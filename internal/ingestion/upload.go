@@ -0,0 +1,117 @@
+package ingestion
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxUploadFileSize is the largest single document accepted for upload
+	MaxUploadFileSize = 25 << 20
+	// MaxUploadRequestSize is the most data accepted in one upload request
+	MaxUploadRequestSize = 256 << 20
+	// UploadSniffLength is how many leading bytes CheckUploadContent needs
+	UploadSniffLength = 8192
+	// maxUploadNameLength keeps saved names well below file system limits
+	maxUploadNameLength = 200
+)
+
+// zipFormats are document formats stored as ZIP packages. The entries that
+// identify them may come after the sniffed bytes, so any ZIP is accepted.
+var zipFormats = map[string]bool{".docx": true, ".odt": true}
+
+// SanitizeUploadName turns a client-supplied file name into a safe name in the
+// uploads directory: directories are dropped, separators and control
+// characters replaced and leading dots removed so the file is not hidden
+func SanitizeUploadName(name string) (string, error) {
+	name = strings.ToValidUTF8(name, "_")
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimLeft(sanitizeFilename(name), ". ")
+	if name == "" {
+		return "", fmt.Errorf("invalid file name")
+	}
+
+	if len(name) > maxUploadNameLength {
+		ext := filepath.Ext(name)
+		if len(ext) > maxUploadNameLength/2 {
+			ext = ""
+		}
+		name = TruncateUTF8(strings.TrimSuffix(name, filepath.Ext(name)), maxUploadNameLength-len(ext)) + ext
+	}
+	return name, nil
+}
+
+// MaxUploadSize returns the size limit of an upload: archives and mail exports
+// bundle many documents, so they may be larger than a single document
+func MaxUploadSize(filename string) int64 {
+	if IsArchiveFile(filename) || IsMailFile(filename) {
+		return MaxArchiveSize
+	}
+	return MaxUploadFileSize
+}
+
+// CheckUploadContent checks that the leading bytes of an upload match its
+// extension, so that e.g. an executable renamed to .pdf is rejected
+func CheckUploadContent(filename string, head []byte) error {
+	if len(head) == 0 {
+		return fmt.Errorf("file is empty")
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	switch {
+	case IsArchiveFile(filename):
+		if !bytes.HasPrefix(head, []byte("PK\x03\x04")) && !bytes.HasPrefix(head, []byte("PK\x05\x06")) {
+			return contentMismatch(ext, head)
+		}
+		return nil
+	case IsMailFile(filename):
+		if IsBinaryData(string(head)) {
+			return contentMismatch(ext, head)
+		}
+		return nil
+	}
+
+	format := lookupFormat(filename)
+	if format == nil {
+		return fmt.Errorf("unsupported file type: %s", ext)
+	}
+	if format.Text {
+		if IsBinaryData(string(head)) {
+			return contentMismatch(ext, head)
+		}
+		return nil
+	}
+
+	sniffed := SniffMIMEType(head)
+	if sniffed == "application/zip" && zipFormats[ext] {
+		return nil
+	}
+	for _, mimeType := range format.MIMETypes {
+		if sniffed == mimeType {
+			return nil
+		}
+	}
+	return contentMismatch(ext, head)
+}
+
+// contentMismatch describes an upload whose content does not match its extension
+func contentMismatch(ext string, head []byte) error {
+	return fmt.Errorf("content (%s) does not match the %s extension", SniffMIMEType(head), ext)
+}
+
+// TruncateUTF8 cuts s to at most maxLen bytes without splitting a character.
+// It backs off to the start of the character at the cut, so invalid bytes
+// earlier in s do not shorten it further.
+func TruncateUTF8(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen]
+}
@@ -0,0 +1,113 @@
+package ingestion
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestSanitizeUploadName tests that client-supplied names stay inside the uploads directory
+func TestSanitizeUploadName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "Jane_Smith_CV.pdf", want: "Jane_Smith_CV.pdf"},
+		{name: "../../etc/passwd", want: "passwd"},
+		{name: `..\..\Windows\evil.docx`, want: "evil.docx"},
+		{name: "/abs/path/cv.pdf", want: "cv.pdf"},
+		{name: ".hidden_cv.pdf", want: "hidden_cv.pdf"},
+		{name: "cv\x00.pdf", want: "cv_.pdf"},
+		{name: `what?<cv>|*.pdf`, want: "what__cv___.pdf"},
+		{name: "..", wantErr: true},
+		{name: "", wantErr: true},
+		{name: "uploads/", want: "uploads"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizeUploadName(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("SanitizeUploadName(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+			}
+		})
+	}
+
+	// Long names are cut, keeping the extension
+	got, err := SanitizeUploadName(strings.Repeat("é", 300) + ".pdf")
+	if err != nil || len(got) > maxUploadNameLength || !strings.HasSuffix(got, "é.pdf") {
+		t.Errorf("Expected a shortened name ending in é.pdf, got %q (%d bytes), %v", got, len(got), err)
+	}
+}
+
+// TestTruncateUTF8 tests cutting at a byte limit without splitting a character
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"Zürich", 2, "Z"},
+		{"Zürich", 3, "Zü"},
+		{"a\xffbcdef", 4, "a\xffbc"},
+		{"\xff\xfe\xfd", 2, "\xff\xfe"},
+		{"ü", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateUTF8(tt.input, tt.maxLen); got != tt.want {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+// TestCheckUploadContent tests that uploads are rejected when their bytes do not match the extension
+func TestCheckUploadContent(t *testing.T) {
+	var docx bytes.Buffer
+	zw := zip.NewWriter(&docx)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte("<w:document/>"))
+	zw.Close()
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		wantErr  bool
+	}{
+		{"PDF", "cv.pdf", []byte("%PDF-1.7\n"), false},
+		{"DOCX", "cv.docx", docx.Bytes(), false},
+		{"DOC", "cv.doc", []byte(oleSignature + "\x00\x00"), false},
+		{"PNG", "scan.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), false},
+		{"TIFF", "scan.tiff", []byte("II*\x00\x08\x00\x00\x00"), false},
+		{"Text", "cover_letter.txt", []byte("Dear hiring manager"), false},
+		{"HTML", "cv.html", []byte("<html><body>Jane</body></html>"), false},
+		{"ZIP", "export.zip", docx.Bytes(), false},
+		{"Mail", "application.eml", []byte("From: jane@example.com\r\n\r\nHello"), false},
+		{"Executable as PDF", "cv.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00"), true},
+		{"Text as PDF", "cv.pdf", []byte("just some text"), true},
+		{"PDF as DOCX", "cv.docx", []byte("%PDF-1.7\n"), true},
+		{"PDF as text", "cv.txt", []byte("%PDF-1.7\n"), true},
+		{"Text as ZIP", "export.zip", []byte("not a zip"), true},
+		{"Empty", "cv.pdf", nil, true},
+		{"Unsupported", "setup.exe", []byte("MZ\x90\x00"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckUploadContent(tt.filename, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckUploadContent(%q) error = %v, wantErr %v", tt.filename, err, tt.wantErr)
+			}
+		})
+	}
+}
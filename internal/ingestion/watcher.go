@@ -0,0 +1,131 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long a watched folder must be quiet before it is
+// processed, so files still being copied are not read half-written
+const DefaultWatchDebounce = 5 * time.Second
+
+// WatchFolder watches dir and its (non-hidden) subfolders and calls onChange
+// once the folder has been quiet for debounce after files are created, written,
+// renamed or removed. Changes to hidden files are ignored. WatchFolder blocks
+// until ctx is cancelled and then returns ctx.Err().
+func WatchFolder(ctx context.Context, dir string, debounce time.Duration, onChange func()) error {
+	watcher, err := NewFolderWatcher(dir)
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	return watcher.Run(ctx, debounce, onChange)
+}
+
+// FolderWatcher collects the changes to a folder and its (non-hidden) subfolders
+type FolderWatcher struct {
+	watcher *fsnotify.Watcher
+}
+
+// NewFolderWatcher starts watching dir. Changes made from then on are reported
+// by Run, so the folder can be read once in between without missing files.
+func NewFolderWatcher(dir string) (*FolderWatcher, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("watch folder %s is not a directory", dir)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder watcher: %w", err)
+	}
+	if err := addWatchDirs(watcher, dir); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	log.Printf("Watching %s for new applications", dir)
+	return &FolderWatcher{watcher: watcher}, nil
+}
+
+// Close stops watching the folder
+func (w *FolderWatcher) Close() error {
+	return w.watcher.Close()
+}
+
+// Run calls onChange once the folder has been quiet for debounce after files
+// are created, written, renamed or removed. Changes to hidden files are ignored.
+// Run blocks until ctx is cancelled and then returns ctx.Err().
+func (w *FolderWatcher) Run(ctx context.Context, debounce time.Duration, onChange func()) error {
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	watcher := w.watcher
+
+	// The timer only fires after the last event in a burst
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return fmt.Errorf("folder watcher closed")
+			}
+			if strings.HasPrefix(filepath.Base(event.Name), ".") || event.Op == fsnotify.Chmod {
+				continue
+			}
+			// Folders created later (e.g. one per applicant) are watched too
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchDirs(watcher, event.Name); err != nil {
+						log.Printf("Warning: %v", err)
+					}
+				}
+			}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return fmt.Errorf("folder watcher closed")
+			}
+			log.Printf("Warning: folder watcher error: %v", err)
+
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+// addWatchDirs adds dir and its non-hidden subfolders to the watcher
+func addWatchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
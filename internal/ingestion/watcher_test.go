@@ -0,0 +1,69 @@
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWatchFolder_Debounces tests that a burst of writes triggers a single callback
+func TestWatchFolder_Debounces(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchFolder(ctx, dir, 200*time.Millisecond, func() { calls.Add(1) })
+	}()
+	time.Sleep(100 * time.Millisecond) // let the watcher start
+
+	// A file written in chunks, plus a new applicant folder
+	path := filepath.Join(dir, "JaneSmith_CV.txt")
+	for i := 0; i < 5; i++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		f.WriteString("Jane Smith CV\n")
+		f.Close()
+		time.Sleep(50 * time.Millisecond)
+	}
+	os.Mkdir(filepath.Join(dir, "John Doe"), 0755)
+
+	time.Sleep(500 * time.Millisecond)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("Expected 1 callback after the burst, got %d", got)
+	}
+
+	// Files in folders created after the watch started are seen too
+	os.WriteFile(filepath.Join(dir, "John Doe", "cv.txt"), []byte("John Doe CV"), 0644)
+	time.Sleep(500 * time.Millisecond)
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("Expected a callback for the new folder's file, got %d", got)
+	}
+
+	// Hidden files (e.g. state files) are ignored
+	os.WriteFile(filepath.Join(dir, ".imap_state.json"), []byte("{}"), 0644)
+	time.Sleep(500 * time.Millisecond)
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Hidden file should not trigger a callback, got %d", got)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestWatchFolder_MissingDir tests that a missing folder is reported
+func TestWatchFolder_MissingDir(t *testing.T) {
+	err := WatchFolder(context.Background(), filepath.Join(t.TempDir(), "missing"), time.Second, func() {})
+	if err == nil {
+		t.Error("Expected error for a missing folder")
+	}
+}
@@ -0,0 +1,282 @@
+// Package jobs stores job descriptions so they can be reused across ingestions
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fmuoria/CV-Review-agent/internal/config"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// ErrNotFound is returned for an unknown job description ID or version
+var ErrNotFound = errors.New("job description not found")
+
+// Entry is a stored job description with its edit history
+type Entry struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Versions holds every saved version, oldest first
+	Versions []models.JobDescription `json:"versions"`
+}
+
+// Latest returns the current version
+func (e Entry) Latest() models.JobDescription {
+	return e.Versions[len(e.Versions)-1]
+}
+
+// Summary describes a stored job description without its history
+type Summary struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Library keeps job descriptions in a JSON file. Every edit adds a version, so
+// results scored against an older version can still be traced back to it.
+// The file is read on each call, so the GUI and the server can share it.
+type Library struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPath returns JOB_DESCRIPTIONS_FILE or <config dir>/job_descriptions.json
+func DefaultPath() (string, error) {
+	if path := os.Getenv("JOB_DESCRIPTIONS_FILE"); path != "" {
+		return path, nil
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "job_descriptions.json"), nil
+}
+
+// NewLibrary opens the library stored at path
+func NewLibrary(path string) *Library {
+	return &Library{path: path}
+}
+
+// List returns the stored job descriptions, most recently updated first
+func (l *Library) List() ([]Summary, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]Summary, 0, len(entries))
+	for _, e := range entries {
+		latest := e.Latest()
+		summaries = append(summaries, Summary{
+			ID:        e.ID,
+			Title:     latest.Title,
+			Version:   latest.Version,
+			CreatedAt: e.CreatedAt,
+			UpdatedAt: e.UpdatedAt,
+		})
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt) })
+	return summaries, nil
+}
+
+// Get returns a job description; version 0 means the latest
+func (l *Library) Get(id string, version int) (models.JobDescription, error) {
+	entry, err := l.Entry(id)
+	if err != nil {
+		return models.JobDescription{}, err
+	}
+	if version == 0 {
+		return entry.Latest(), nil
+	}
+	for _, jd := range entry.Versions {
+		if jd.Version == version {
+			return jd, nil
+		}
+	}
+	return models.JobDescription{}, fmt.Errorf("version %d of %s: %w", version, id, ErrNotFound)
+}
+
+// Entry returns a job description with all its versions
+func (l *Library) Entry(id string) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
+
+// Create validates and stores a new job description as version 1
+func (l *Library) Create(jd models.JobDescription) (models.JobDescription, error) {
+	if err := jd.Validate(); err != nil {
+		return models.JobDescription{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return models.JobDescription{}, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return models.JobDescription{}, fmt.Errorf("failed to generate job description ID: %w", err)
+	}
+	now := time.Now().UTC()
+	jd.ID = slug(jd.Title) + "-" + hex.EncodeToString(suffix)
+	jd.Version = 1
+	entries = append(entries, Entry{ID: jd.ID, CreatedAt: now, UpdatedAt: now, Versions: []models.JobDescription{jd}})
+
+	if err := l.save(entries); err != nil {
+		return models.JobDescription{}, err
+	}
+	return jd, nil
+}
+
+// Update validates jd and stores it as the next version of id
+func (l *Library) Update(id string, jd models.JobDescription) (models.JobDescription, error) {
+	if err := jd.Validate(); err != nil {
+		return models.JobDescription{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return models.JobDescription{}, err
+	}
+	for i := range entries {
+		if entries[i].ID != id {
+			continue
+		}
+		jd.ID = id
+		jd.Version = entries[i].Latest().Version + 1
+		entries[i].Versions = append(entries[i].Versions, jd)
+		entries[i].UpdatedAt = time.Now().UTC()
+		if err := l.save(entries); err != nil {
+			return models.JobDescription{}, err
+		}
+		return jd, nil
+	}
+	return models.JobDescription{}, ErrNotFound
+}
+
+// Delete removes a job description with all its versions
+func (l *Library) Delete(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return err
+	}
+	kept := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(entries) {
+		return ErrNotFound
+	}
+	return l.save(kept)
+}
+
+// load reads the library file; callers hold the lock
+func (l *Library) load() ([]Entry, error) {
+	data, err := os.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job descriptions: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse job descriptions: %w", err)
+	}
+	// Drop entries without versions so Latest is always safe
+	valid := entries[:0]
+	for _, e := range entries {
+		if len(e.Versions) > 0 {
+			valid = append(valid, e)
+		}
+	}
+	return valid, nil
+}
+
+// save writes the library file, replacing it atomically; callers hold the lock
+func (l *Library) save(entries []Entry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job descriptions: %w", err)
+	}
+
+	dir := filepath.Dir(l.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to save job descriptions: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".job-descriptions-*")
+	if err != nil {
+		return fmt.Errorf("failed to save job descriptions: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save job descriptions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save job descriptions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to save job descriptions: %w", err)
+	}
+	return nil
+}
+
+// slug turns a title into a URL-safe ID prefix ("Senior Go Engineer" -> "senior-go-engineer")
+func slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if b.Len() >= 40 {
+				break
+			}
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "job"
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// TestLibrary_Lifecycle tests creating, versioning and deleting job descriptions
+func TestLibrary_Lifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "job_descriptions.json")
+	library := NewLibrary(path)
+
+	created, err := library.Create(models.JobDescription{
+		Title:              "Senior Go Engineer",
+		RequiredExperience: []string{"5+ years of Go"},
+	})
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if !strings.HasPrefix(created.ID, "senior-go-engineer-") || created.Version != 1 {
+		t.Errorf("Expected ID senior-go-engineer-<hex> version 1, got %s version %d", created.ID, created.Version)
+	}
+
+	edited := created
+	edited.RequiredExperience = append(edited.RequiredExperience, "Kubernetes")
+	edited.Version = 7 // ignored, versions are assigned by the library
+	updated, err := library.Update(created.ID, edited)
+	if err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if updated.ID != created.ID || updated.Version != 2 {
+		t.Errorf("Expected %s version 2, got %s version %d", created.ID, updated.ID, updated.Version)
+	}
+
+	// A new library on the same file sees both versions
+	reloaded := NewLibrary(path)
+	latest, err := reloaded.Get(created.ID, 0)
+	if err != nil || len(latest.RequiredExperience) != 2 {
+		t.Errorf("Get(latest) = %+v, %v", latest, err)
+	}
+	first, err := reloaded.Get(created.ID, 1)
+	if err != nil || len(first.RequiredExperience) != 1 {
+		t.Errorf("Get(version 1) = %+v, %v", first, err)
+	}
+	if _, err := reloaded.Get(created.ID, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing version, got %v", err)
+	}
+
+	summaries, err := reloaded.List()
+	if err != nil || len(summaries) != 1 || summaries[0].Version != 2 || summaries[0].Title != "Senior Go Engineer" {
+		t.Errorf("List() = %+v, %v", summaries, err)
+	}
+
+	if err := reloaded.Delete(created.ID); err != nil {
+		t.Fatalf("Delete() returned error: %v", err)
+	}
+	if _, err := reloaded.Get(created.ID, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := reloaded.Delete(created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+	if _, err := reloaded.Update(created.ID, edited); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a deleted job description, got %v", err)
+	}
+}
+
+// TestLibrary_RejectsInvalid tests that invalid job descriptions are not stored
+func TestLibrary_RejectsInvalid(t *testing.T) {
+	library := NewLibrary(filepath.Join(t.TempDir(), "job_descriptions.json"))
+
+	_, err := library.Create(models.JobDescription{Title: "No requirements"})
+	var invalid *models.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+
+	summaries, err := library.List()
+	if err != nil || len(summaries) != 0 {
+		t.Errorf("Expected an empty library, got %+v, %v", summaries, err)
+	}
+}
+
+// TestSlug tests job description ID prefixes
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Senior Go Engineer":    "senior-go-engineer",
+		"C++ / Rust Dev (m/f)":  "c-rust-dev-m-f",
+		"???":                   "job",
+		strings.Repeat("a", 60): strings.Repeat("a", 40),
+	}
+	for title, expected := range tests {
+		if got := slug(title); got != expected {
+			t.Errorf("slug(%q) = %q, want %q", title, got, expected)
+		}
+	}
+}
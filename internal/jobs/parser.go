@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/llm"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// maxPostingLength caps the job ad text sent to the LLM
+const maxPostingLength = 30000
+
+// Parser turns free-text job postings into structured job descriptions using the LLM
+type Parser struct {
+	llmClient *llm.VertexAIClient
+}
+
+// NewParser creates a new job posting parser
+func NewParser(llmClient *llm.VertexAIClient) *Parser {
+	return &Parser{
+		llmClient: llmClient,
+	}
+}
+
+// ParseText asks the LLM to split a job posting into required and
+// nice-to-have experience, education and duties. The result is meant to be
+// reviewed by a person before it is used for scoring.
+func (p *Parser) ParseText(ctx context.Context, text string) (models.JobDescription, error) {
+	text = strings.TrimSpace(strings.ToValidUTF8(text, "�"))
+	if text == "" {
+		return models.JobDescription{}, fmt.Errorf("the job posting is empty")
+	}
+
+	response, err := p.llmClient.GenerateContent(ctx, buildParsePrompt(text))
+	if err != nil {
+		return models.JobDescription{}, fmt.Errorf("failed to get LLM response: %w", err)
+	}
+
+	jobDesc, err := parseJobDescription(response)
+	if err != nil {
+		return models.JobDescription{}, fmt.Errorf("failed to parse job description: %w", err)
+	}
+	return jobDesc, nil
+}
+
+// PostingText returns the text of a job posting: a PDF, DOCX, HTML or any
+// other file ExtractText supports, or a plain text file
+func PostingText(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".text", "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read job posting: %w", err)
+		}
+		return string(data), nil
+	}
+
+	text, err := ingestion.ExtractText(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract job posting text: %w", err)
+	}
+	return text, nil
+}
+
+// buildParsePrompt creates the prompt asking for a JobDescription JSON object
+func buildParsePrompt(text string) string {
+	if len(text) > maxPostingLength {
+		text = truncate(text, maxPostingLength) + "\n...[posting truncated for length]"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("You are an expert recruiter. Convert the job posting below into a structured job description.\n\n")
+	sb.WriteString("## INSTRUCTIONS\n")
+	sb.WriteString("- Put requirements the posting states as mandatory (\"must\", \"required\", \"minimum\") in the required lists.\n")
+	sb.WriteString("- Put requirements stated as optional (\"nice to have\", \"preferred\", \"a plus\", \"bonus\") in the nice_to_have lists.\n")
+	sb.WriteString("- Experience: years, skills, tools and domains. Education: degrees, certifications and licences. Duties: what the person will do.\n")
+	sb.WriteString("- One short requirement per item, in the posting's own words. Do not invent requirements.\n")
+	sb.WriteString("- description: a summary of the role in at most three sentences, without benefits, salary or application instructions.\n")
+	sb.WriteString("- Use empty lists for categories the posting does not mention.\n\n")
+	sb.WriteString("## RESPONSE FORMAT\n")
+	sb.WriteString("Respond with ONLY this JSON object, no other text:\n")
+	sb.WriteString(`{"title": "", "description": "", "required_experience": [], "required_education": [], "required_duties": [], ` +
+		`"nice_to_have_experience": [], "nice_to_have_education": [], "nice_to_have_duties": []}` + "\n\n")
+	sb.WriteString("## JOB POSTING\n")
+	sb.WriteString(text)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// parseJobDescription extracts the JSON object from an LLM response and tidies
+// the lists so the result fits the job description limits
+func parseJobDescription(response string) (models.JobDescription, error) {
+	var jobDesc models.JobDescription
+
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end <= start {
+		return jobDesc, fmt.Errorf("no JSON found in response: %s", truncate(response, 200))
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &jobDesc); err != nil {
+		return jobDesc, fmt.Errorf("invalid JSON in response: %w", err)
+	}
+
+	jobDesc.ID = ""
+	jobDesc.Version = 0
+	jobDesc.Title = truncate(strings.TrimSpace(jobDesc.Title), models.MaxTitleLength)
+	jobDesc.Description = truncate(strings.TrimSpace(jobDesc.Description), models.MaxDescriptionLength)
+	for _, list := range []*[]string{
+		&jobDesc.RequiredExperience, &jobDesc.RequiredEducation, &jobDesc.RequiredDuties,
+		&jobDesc.NiceToHaveExperience, &jobDesc.NiceToHaveEducation, &jobDesc.NiceToHaveDuties,
+	} {
+		*list = cleanItems(*list)
+	}
+	return jobDesc, nil
+}
+
+// cleanItems trims items and drops empty and repeated ones
+func cleanItems(items []string) []string {
+	cleaned := []string{}
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		item = truncate(strings.TrimSpace(item), models.MaxRequirementLength)
+		key := strings.ToLower(item)
+		if item == "" || seen[key] || len(cleaned) == models.MaxRequirements {
+			continue
+		}
+		seen[key] = true
+		cleaned = append(cleaned, item)
+	}
+	return cleaned
+}
+
+// truncate cuts s to at most maxLen bytes without splitting a character
+func truncate(s string, maxLen int) string {
+	return ingestion.TruncateUTF8(s, maxLen)
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseJobDescription tests reading the LLM response
+func TestParseJobDescription(t *testing.T) {
+	response := "Here is the job description:\n```json\n" + `{
+  "id": "ignored",
+  "version": 3,
+  "title": "  Data Analyst ",
+  "description": "Analyse sales data.",
+  "required_experience": ["3+ years of SQL", " ", "3+ years of sql", "Python"],
+  "required_education": ["BSc in Statistics"],
+  "required_duties": [],
+  "nice_to_have_experience": ["Tableau"]
+}` + "\n```"
+
+	jobDesc, err := parseJobDescription(response)
+	if err != nil {
+		t.Fatalf("parseJobDescription() returned error: %v", err)
+	}
+	if jobDesc.ID != "" || jobDesc.Version != 0 {
+		t.Errorf("Expected ID and version to be cleared, got %q and %d", jobDesc.ID, jobDesc.Version)
+	}
+	if jobDesc.Title != "Data Analyst" {
+		t.Errorf("Expected trimmed title, got %q", jobDesc.Title)
+	}
+	if got := strings.Join(jobDesc.RequiredExperience, "|"); got != "3+ years of SQL|Python" {
+		t.Errorf("Expected empty and repeated items dropped, got %q", got)
+	}
+	if jobDesc.RequiredDuties == nil || jobDesc.NiceToHaveDuties == nil {
+		t.Error("Expected empty lists rather than nil")
+	}
+	if err := jobDesc.Validate(); err != nil {
+		t.Errorf("Expected a valid job description, got %v", err)
+	}
+
+	for _, bad := range []string{"", "I could not read the posting.", "{not json}"} {
+		if _, err := parseJobDescription(bad); err == nil {
+			t.Errorf("Expected an error for response %q", bad)
+		}
+	}
+}
+
+// TestBuildParsePrompt tests the prompt and the posting length limit
+func TestBuildParsePrompt(t *testing.T) {
+	prompt := buildParsePrompt("We need a Go developer. Kubernetes is a plus.")
+	for _, want := range []string{"nice_to_have_experience", "required_duties", "Kubernetes is a plus", "Do not invent"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q", want)
+		}
+	}
+
+	long := buildParsePrompt(strings.Repeat("é", maxPostingLength))
+	if !strings.Contains(long, "[posting truncated for length]") {
+		t.Error("Expected long postings to be truncated")
+	}
+	if len(long) > maxPostingLength+2000 {
+		t.Errorf("Expected prompt of at most %d bytes, got %d", maxPostingLength+2000, len(long))
+	}
+
+	// An invalid byte early in the posting does not cut the rest of it
+	invalid := buildParsePrompt("\xff" + strings.Repeat("a", maxPostingLength))
+	if !strings.Contains(invalid, "\xff"+strings.Repeat("a", maxPostingLength-1)+"\n...[posting truncated for length]") {
+		t.Error("Expected a posting with an invalid byte to be cut at the length limit")
+	}
+}
+
+// TestPostingText tests reading job postings from files
+func TestPostingText(t *testing.T) {
+	dir := t.TempDir()
+
+	txt := filepath.Join(dir, "ad.txt")
+	os.WriteFile(txt, []byte("Senior accountant wanted"), 0644)
+	if text, err := PostingText(txt); err != nil || text != "Senior accountant wanted" {
+		t.Errorf("PostingText(txt) = %q, %v", text, err)
+	}
+
+	html := filepath.Join(dir, "ad.html")
+	os.WriteFile(html, []byte("<html><body><h1>Senior Accountant</h1><p>Must have a CPA licence and five years of audit experience.</p></body></html>"), 0644)
+	if text, err := PostingText(html); err != nil || !strings.Contains(text, "CPA licence") || strings.Contains(text, "<p>") {
+		t.Errorf("PostingText(html) = %q, %v", text, err)
+	}
+
+	if _, err := PostingText(filepath.Join(dir, "ad.xyz")); err == nil {
+		t.Error("Expected an error for an unsupported file type")
+	}
+}
@@ -2,6 +2,9 @@ package models
 
 // JobDescription represents a job posting with requirements
 type JobDescription struct {
+	// ID and Version are set for job descriptions stored in the library
+	ID                   string   `json:"id,omitempty"`
+	Version              int      `json:"version,omitempty"`
 	Title                string   `json:"title"`
 	RequiredExperience   []string `json:"required_experience"`
 	RequiredEducation    []string `json:"required_education"`
@@ -12,13 +15,71 @@ type JobDescription struct {
 	Description          string   `json:"description"`
 }
 
-// ApplicantDocument holds CV and cover letter content
+// Document types of applicant files
+const (
+	DocumentCV          = "cv"
+	DocumentCoverLetter = "cover_letter"
+	DocumentPortfolio   = "portfolio"
+	DocumentCertificate = "certificate"
+	DocumentReference   = "reference"
+	DocumentTranscript  = "transcript"
+)
+
+// Document is one typed applicant file
+type Document struct {
+	Type    string `json:"type"` // one of the Document* constants
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+// ApplicantDocument holds all documents submitted by one applicant
 type ApplicantDocument struct {
-	Name      string `json:"name"`
-	CVContent string `json:"cv_content"`
-	CVPath    string `json:"cv_path"`
-	CLContent string `json:"cl_content"` // Cover Letter
-	CLPath    string `json:"cl_path"`
+	Name      string     `json:"name"`
+	Documents []Document `json:"documents"`
+	// Emails and Phones are the applicant's contact details found in their documents
+	Emails []string `json:"emails,omitempty"`
+	Phones []string `json:"phones,omitempty"`
+	// OCRPages records per-page confidence for documents read by OCR
+	OCRPages []OCRPage `json:"ocr_pages,omitempty"`
+	// ReviewReasons explains why the applicant should be checked by a person
+	ReviewReasons []string `json:"review_reasons,omitempty"`
+}
+
+// CV returns the applicant's CV (empty if there is none)
+func (a ApplicantDocument) CV() Document {
+	return a.first(DocumentCV)
+}
+
+// CoverLetter returns the applicant's cover letter (empty if there is none)
+func (a ApplicantDocument) CoverLetter() Document {
+	return a.first(DocumentCoverLetter)
+}
+
+// AdditionalDocuments returns the documents other than the CV and cover letter
+func (a ApplicantDocument) AdditionalDocuments() []Document {
+	var docs []Document
+	for _, d := range a.Documents {
+		if d.Type != DocumentCV && d.Type != DocumentCoverLetter {
+			docs = append(docs, d)
+		}
+	}
+	return docs
+}
+
+func (a ApplicantDocument) first(docType string) Document {
+	for _, d := range a.Documents {
+		if d.Type == docType {
+			return d
+		}
+	}
+	return Document{}
+}
+
+// OCRPage records the OCR confidence of one page of an applicant document
+type OCRPage struct {
+	Document   string  `json:"document"` // file name
+	Page       int     `json:"page"`
+	Confidence float64 `json:"confidence"` // 0-100
 }
 
 // Scores represents evaluation scores for an applicant
@@ -36,23 +97,169 @@ type Scores struct {
 
 // ApplicantResult represents the evaluation result for one applicant
 type ApplicantResult struct {
-	Name   string `json:"name"`
-	Scores Scores `json:"scores"`
-	Rank   int    `json:"rank"`
-	CVPath string `json:"cv_path,omitempty"`
-	CLPath string `json:"cl_path,omitempty"`
+	// ID identifies the applicant in the API (name and a hash of the file names, unique within a job)
+	ID     string   `json:"id,omitempty"`
+	Name   string   `json:"name"`
+	Scores Scores   `json:"scores"`
+	Rank   int      `json:"rank"`
+	CVPath string   `json:"cv_path,omitempty"`
+	CLPath string   `json:"cl_path,omitempty"`
+	Emails []string `json:"emails,omitempty"`
+	Phones []string `json:"phones,omitempty"`
+	// Documents lists every file of the applicant (content is not carried into results)
+	Documents []Document `json:"documents,omitempty"`
+	// NeedsReview flags results whose input documents were unreliable (e.g. low OCR confidence)
+	NeedsReview   bool      `json:"needs_review,omitempty"`
+	ReviewReasons []string  `json:"review_reasons,omitempty"`
+	OCRPages      []OCRPage `json:"ocr_pages,omitempty"`
+}
+
+// ApplicantSummary is one row of an applicant list
+type ApplicantSummary struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Rank             int     `json:"rank"`
+	TotalScore       float64 `json:"total_score"`
+	ExperienceScore  float64 `json:"experience_score"`
+	EducationScore   float64 `json:"education_score"`
+	DutiesScore      float64 `json:"duties_score"`
+	CoverLetterScore float64 `json:"cover_letter_score"`
+	NeedsReview      bool    `json:"needs_review,omitempty"`
+	URL              string  `json:"url,omitempty"`
+}
+
+// ApplicantProfile holds an applicant's contact details and document quality flags
+type ApplicantProfile struct {
+	Emails        []string  `json:"emails,omitempty"`
+	Phones        []string  `json:"phones,omitempty"`
+	NeedsReview   bool      `json:"needs_review,omitempty"`
+	ReviewReasons []string  `json:"review_reasons,omitempty"`
+	OCRPages      []OCRPage `json:"ocr_pages,omitempty"`
+}
+
+// ApplicantFile is one of an applicant's documents with its extracted text
+type ApplicantFile struct {
+	Name        string `json:"name"` // file name, used in the download URL
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// ApplicantDetail is everything known about one applicant
+type ApplicantDetail struct {
+	ID        string           `json:"id"`
+	JobID     string           `json:"job_id"`
+	Name      string           `json:"name"`
+	Rank      int              `json:"rank"`
+	Scores    Scores           `json:"scores"`
+	Profile   ApplicantProfile `json:"profile"`
+	Documents []ApplicantFile  `json:"documents"`
 }
 
 // IngestRequest represents the request payload for document ingestion
 type IngestRequest struct {
-	Method         string `json:"method"`          // "upload" or "gmail"
-	GmailSubject   string `json:"gmail_subject"`   // Subject filter for Gmail
-	JobDescription string `json:"job_description"` // Job description text
+	Method         string         `json:"method"`                    // "upload", "gmail" or "imap"
+	GmailSubject   string         `json:"gmail_subject"`             // Subject filter for Gmail
+	GmailFilter    *GmailFilter   `json:"gmail_filter,omitempty"`    // Structured Gmail search; GmailSubject is used when nil
+	GmailFollowUp  *GmailFollowUp `json:"gmail_follow_up,omitempty"` // Labels and replies after scoring
+	JobDescription string         `json:"job_description"`           // Job description text
+}
+
+// GmailFilter selects the Gmail messages to fetch attachments from. All set
+// fields must match; values are quoted, so multi-word subjects work as phrases.
+type GmailFilter struct {
+	// Account is the authorized Gmail address to search; optional when only one is authorized
+	Account string   `json:"account,omitempty"`
+	Subject string   `json:"subject,omitempty"` // phrase in the subject line
+	From    string   `json:"from,omitempty"`    // sender address or name
+	To      string   `json:"to,omitempty"`      // recipient address, e.g. a jobs@ alias
+	Label   string   `json:"label,omitempty"`   // Gmail label name
+	After   string   `json:"after,omitempty"`   // received on or after, YYYY-MM-DD
+	Before  string   `json:"before,omitempty"`  // received before, YYYY-MM-DD
+	Exclude []string `json:"exclude,omitempty"` // terms that must not appear
+	Query   string   `json:"query,omitempty"`   // raw Gmail search syntax, added as is
+	// IncludeBody also saves the email body: as the cover letter when the email
+	// has attachments, otherwise for the classifier (it may be a pasted CV)
+	IncludeBody bool `json:"include_body,omitempty"`
+}
+
+// Gmail follow-up reply modes
+const (
+	GmailReplyDraft = "draft" // save the reply in Drafts for a recruiter to review
+	GmailReplySend  = "send"  // send the reply straight away
+)
+
+// GmailFollowUp configures the optional Gmail actions taken once applicants are scored
+type GmailFollowUp struct {
+	Labels []GmailLabelRule `json:"labels,omitempty"`
+	// Reply is GmailReplyDraft or GmailReplySend to acknowledge each application; empty for none
+	Reply string `json:"reply,omitempty"`
+	// ReplyTemplate is a text/template for the reply body with {{.Name}} and {{.JobTitle}}
+	ReplyTemplate string `json:"reply_template,omitempty"`
+}
+
+// GmailLabelRule labels the application emails of applicants ranked in the top
+// TopN (0 for everyone) whose total score is at least MinScore
+type GmailLabelRule struct {
+	Label    string  `json:"label"` // e.g. "CV-Review/Shortlisted"; created if missing
+	TopN     int     `json:"top_n,omitempty"`
+	MinScore float64 `json:"min_score,omitempty"`
+}
+
+// Matches reports whether the rule applies to a ranked result
+func (r GmailLabelRule) Matches(result ApplicantResult) bool {
+	return (r.TopN <= 0 || result.Rank <= r.TopN) && result.Scores.TotalScore >= r.MinScore
+}
+
+// UnprocessedFile describes an ingested file that was not used for scoring
+type UnprocessedFile struct {
+	File         string `json:"file"`
+	Applicant    string `json:"applicant,omitempty"`
+	DocumentType string `json:"document_type,omitempty"` // classifier result, e.g. "certificate" or "unknown"
+	Reason       string `json:"reason"`
+}
+
+// Upload statuses
+const (
+	UploadAccepted = "accepted"
+	UploadRejected = "rejected"
+)
+
+// UploadResult reports whether one file of an upload request was accepted
+type UploadResult struct {
+	File    string `json:"file"`               // name sent by the client
+	SavedAs string `json:"saved_as,omitempty"` // sanitized name in the uploads directory
+	Size    int64  `json:"size"`
+	Status  string `json:"status"` // UploadAccepted or UploadRejected
+	Reason  string `json:"reason,omitempty"`
+	// Documents counts the files unpacked from an archive or mail export
+	Documents int `json:"documents,omitempty"`
 }
 
 // ReportResponse represents the response with ranked applicants
 type ReportResponse struct {
 	Applicants []ApplicantResult `json:"applicants"`
 	JobTitle   string            `json:"job_title"`
-	Timestamp  string            `json:"timestamp"`
+	// JobDescription lets a saved report be exported or rescored later
+	JobDescription   *JobDescription   `json:"job_description,omitempty"`
+	Timestamp        string            `json:"timestamp"`
+	UnprocessedFiles []UnprocessedFile `json:"unprocessed_files,omitempty"`
+	// Total is the number of applicants matching the query; NextCursor fetches the next page
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ReportQuery filters, sorts and pages the applicants of a report
+type ReportQuery struct {
+	MinTotal       float64 // minimum total score
+	MinExperience  float64
+	MinEducation   float64
+	MinDuties      float64
+	MinCoverLetter float64
+	Name           string // case-insensitive substring of the applicant's name
+	Status         string // "needs_review", "ok" or empty for all
+	Sort           string // "rank" (default), "name" or a score field such as "total_score"
+	Descending     bool
+	Limit          int    // page size, 0 for all
+	Cursor         string // next_cursor of the previous page
 }
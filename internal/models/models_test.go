@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -78,3 +80,70 @@ func TestApplicantResultRanking(t *testing.T) {
 		t.Errorf("Expected Applicant1 to be rank 2, got %d", results[0].Rank)
 	}
 }
+
+func TestApplicantDocumentAccessors(t *testing.T) {
+	doc := ApplicantDocument{
+		Name: "Jane Smith",
+		Documents: []Document{
+			{Type: DocumentCertificate, Path: "aws.pdf"},
+			{Type: DocumentCV, Path: "cv.pdf"},
+			{Type: DocumentReference, Path: "ref.pdf"},
+		},
+	}
+
+	if doc.CV().Path != "cv.pdf" {
+		t.Errorf("Expected CV path 'cv.pdf', got '%s'", doc.CV().Path)
+	}
+	if doc.CoverLetter().Path != "" {
+		t.Errorf("Expected no cover letter, got '%s'", doc.CoverLetter().Path)
+	}
+	if additional := doc.AdditionalDocuments(); len(additional) != 2 || additional[1].Type != DocumentReference {
+		t.Errorf("Expected certificate and reference as additional documents, got %+v", additional)
+	}
+}
+
+// TestJobDescriptionValidate tests job description validation
+func TestJobDescriptionValidate(t *testing.T) {
+	valid := JobDescription{Title: "Data Analyst", RequiredExperience: []string{"SQL"}}
+
+	tests := []struct {
+		name   string
+		modify func(*JobDescription)
+		fields []string // invalid fields, none when valid
+	}{
+		{"Valid", func(jd *JobDescription) {}, nil},
+		{"Missing title", func(jd *JobDescription) { jd.Title = "  " }, []string{"title"}},
+		{"Long title", func(jd *JobDescription) { jd.Title = strings.Repeat("a", MaxTitleLength+1) }, []string{"title"}},
+		{"No requirements", func(jd *JobDescription) { jd.RequiredExperience = nil; jd.NiceToHaveDuties = []string{"Mentoring"} }, []string{"required_experience"}},
+		{"Empty item", func(jd *JobDescription) { jd.RequiredEducation = []string{"BSc", ""} }, []string{"required_education[1]"}},
+		{"Too many items", func(jd *JobDescription) { jd.NiceToHaveEducation = make([]string, MaxRequirements+1) }, []string{"nice_to_have_education"}},
+		{"Several fields", func(jd *JobDescription) { jd.Title = ""; jd.Description = strings.Repeat("a", MaxDescriptionLength+1) }, []string{"title", "description"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jd := valid
+			tt.modify(&jd)
+			err := jd.Validate()
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if len(invalid.Fields) != len(tt.fields) {
+				t.Fatalf("invalid fields = %+v, want %v", invalid.Fields, tt.fields)
+			}
+			for i, field := range tt.fields {
+				if invalid.Fields[i].Field != field {
+					t.Errorf("Fields[%d] = %q, want %q", i, invalid.Fields[i].Field, field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on job description fields; lengths count characters, not bytes
+const (
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 20000
+	MaxRequirements      = 100 // per list
+	MaxRequirementLength = 1000
+)
+
+// FieldError describes one invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the invalid fields of a request
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Error joins the field errors into one message
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+// Add records an invalid field
+func (e *ValidationError) Add(field, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e if any field is invalid, nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks that a job description can be scored against: it needs a
+// title and at least one requirement, and fields must stay within the limits.
+// Errors are returned as a *ValidationError.
+func (j JobDescription) Validate() error {
+	var v ValidationError
+	j.validate(&v, "")
+	return v.Err()
+}
+
+// ValidateField is Validate for a job description sent in a request field;
+// invalid fields are named "<field>.title" and so on
+func (j JobDescription) ValidateField(field string) error {
+	var v ValidationError
+	j.validate(&v, field+".")
+	return v.Err()
+}
+
+// validate records the invalid fields of j, prefixing field names with prefix
+func (j JobDescription) validate(v *ValidationError, prefix string) {
+	title := strings.TrimSpace(j.Title)
+	if title == "" {
+		v.Add(prefix+"title", "is required")
+	} else if utf8.RuneCountInString(title) > MaxTitleLength {
+		v.Add(prefix+"title", "must be at most %d characters", MaxTitleLength)
+	}
+	if utf8.RuneCountInString(j.Description) > MaxDescriptionLength {
+		v.Add(prefix+"description", "must be at most %d characters", MaxDescriptionLength)
+	}
+
+	lists := []struct {
+		field string
+		items []string
+	}{
+		{"required_experience", j.RequiredExperience},
+		{"required_education", j.RequiredEducation},
+		{"required_duties", j.RequiredDuties},
+		{"nice_to_have_experience", j.NiceToHaveExperience},
+		{"nice_to_have_education", j.NiceToHaveEducation},
+		{"nice_to_have_duties", j.NiceToHaveDuties},
+	}
+	for _, list := range lists {
+		if len(list.items) > MaxRequirements {
+			v.Add(prefix+list.field, "must have at most %d items", MaxRequirements)
+			continue
+		}
+		for i, item := range list.items {
+			field := fmt.Sprintf("%s%s[%d]", prefix, list.field, i)
+			if strings.TrimSpace(item) == "" {
+				v.Add(field, "must not be empty")
+			} else if utf8.RuneCountInString(item) > MaxRequirementLength {
+				v.Add(field, "must be at most %d characters", MaxRequirementLength)
+			}
+		}
+	}
+
+	if len(j.RequiredExperience)+len(j.RequiredEducation)+len(j.RequiredDuties) == 0 {
+		v.Add(prefix+"required_experience", "at least one required experience, education or duty is needed")
+	}
+}
@@ -0,0 +1,182 @@
+// Package openapi holds the OpenAPI 3 document of the HTTP API and validates
+// requests against it
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// Document returns the OpenAPI document served at /openapi.json
+func Document() []byte {
+	return document
+}
+
+// Spec is the part of an OpenAPI document used to validate requests
+type Spec struct {
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"` // path template -> lower-case method
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation describes one endpoint
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Parameters  []Parameter  `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+	// MaxBodySize limits the request body in bytes (0 for no limit)
+	MaxBodySize int64 `json:"x-max-body-size"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody lists the accepted media types of a request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType is the schema of a body in one media type. Encoding marks form
+// fields that hold JSON ("contentType": "application/json").
+type MediaType struct {
+	Schema   *Schema             `json:"schema"`
+	Encoding map[string]Encoding `json:"encoding"`
+}
+
+// Encoding describes how a form field is encoded
+type Encoding struct {
+	ContentType string `json:"contentType"`
+}
+
+// Schema is the subset of JSON Schema the API document uses
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties interface{}        `json:"additionalProperties"` // false or a schema
+	Items                *Schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	Pattern              string             `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// Load parses the embedded API document
+func Load() (*Spec, error) {
+	return Parse(document)
+}
+
+// Parse parses an OpenAPI document and checks that its references resolve
+// and its patterns compile
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	var check func(where string, schema *Schema) error
+	check = func(where string, schema *Schema) error {
+		if schema == nil {
+			return nil
+		}
+		if schema.Ref != "" {
+			if _, err := spec.resolve(schema); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+		}
+		if schema.Pattern != "" && schema.pattern == nil {
+			re, err := regexp.Compile(schema.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern: %w", where, err)
+			}
+			schema.pattern = re
+		}
+		for name, property := range schema.Properties {
+			if err := check(where+"."+name, property); err != nil {
+				return err
+			}
+		}
+		return check(where+"[]", schema.Items)
+	}
+
+	for name, schema := range spec.Components.Schemas {
+		if err := check(name, schema); err != nil {
+			return nil, err
+		}
+	}
+	for path, methods := range spec.Paths {
+		for method, op := range methods {
+			where := strings.ToUpper(method) + " " + path
+			for _, param := range op.Parameters {
+				if err := check(where+" "+param.Name, param.Schema); err != nil {
+					return nil, err
+				}
+			}
+			if op.RequestBody != nil {
+				for mediaType, content := range op.RequestBody.Content {
+					if err := check(where+" "+mediaType, content.Schema); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return &spec, nil
+}
+
+// Operation returns the operation for a method and path template, e.g.
+// ("GET", "/jobs/{id}/applicants"), or nil if the document has none
+func (s *Spec) Operation(method, path string) *Operation {
+	return s.Paths[path][strings.ToLower(method)]
+}
+
+// Endpoints returns "METHOD /path" and the summary of every operation
+func (s *Spec) Endpoints() map[string]string {
+	endpoints := make(map[string]string)
+	for path, methods := range s.Paths {
+		for method, op := range methods {
+			endpoints[strings.ToUpper(method)+" "+path] = op.Summary
+		}
+	}
+	return endpoints
+}
+
+// resolve follows a "#/components/schemas/Name" reference
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for depth := 0; schema.Ref != ""; depth++ {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		target, ok := s.Components.Schemas[name]
+		if !ok || name == schema.Ref || depth > 10 {
+			return nil, fmt.Errorf("unresolved reference %q", schema.Ref)
+		}
+		schema = target
+	}
+	return schema, nil
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fmuoria/CV-Review-agent/internal/ingestion"
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// TestLoad tests that the embedded document parses and documents every operation
+func TestLoad(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	for route, summary := range spec.Endpoints() {
+		if summary == "" {
+			t.Errorf("%s has no summary", route)
+		}
+	}
+	if spec.Operation("GET", "/jobs/{id}/applicants/{applicantId}") == nil {
+		t.Error("Expected an operation for GET /jobs/{id}/applicants/{applicantId}")
+	}
+}
+
+// TestJobDescriptionLimits tests that the schema limits match the models package
+func TestJobDescriptionLimits(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	schema := spec.Components.Schemas["JobDescription"]
+
+	if got := *schema.Properties["title"].MaxLength; got != models.MaxTitleLength {
+		t.Errorf("title maxLength = %d, want %d", got, models.MaxTitleLength)
+	}
+	if got := *schema.Properties["description"].MaxLength; got != models.MaxDescriptionLength {
+		t.Errorf("description maxLength = %d, want %d", got, models.MaxDescriptionLength)
+	}
+	list := schema.Properties["required_experience"]
+	if got := *list.MaxItems; got != models.MaxRequirements {
+		t.Errorf("required_experience maxItems = %d, want %d", got, models.MaxRequirements)
+	}
+	if got := *list.Items.MaxLength; got != models.MaxRequirementLength {
+		t.Errorf("required_experience items maxLength = %d, want %d", got, models.MaxRequirementLength)
+	}
+}
+
+// TestIngestBodyLimit tests that uploads are limited to the ingestion request size
+func TestIngestBodyLimit(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got := spec.Operation("POST", "/ingest").MaxBodySize; got != ingestion.MaxUploadRequestSize {
+		t.Errorf("POST /ingest x-max-body-size = %d, want %d", got, ingestion.MaxUploadRequestSize)
+	}
+}
+
+// TestValidateRequest tests the invalid fields reported for JSON bodies and query parameters
+func TestValidateRequest(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string // template
+		url    string
+		body   string
+		fields []string
+	}{
+		{
+			name:   "valid job description",
+			method: "POST",
+			path:   "/job-descriptions",
+			url:    "/job-descriptions",
+			body:   `{"title": "Go Engineer", "required_experience": ["Go"], "required_education": null}`,
+		},
+		{
+			name:   "invalid job description",
+			method: "POST",
+			path:   "/job-descriptions",
+			url:    "/job-descriptions",
+			body:   `{"title": "", "required_experience": ["Go", "", 3], "salary": 100}`,
+			fields: []string{"required_experience[1]", "required_experience[2]", "salary", "title"},
+		},
+		{
+			name:   "missing title",
+			method: "PUT",
+			path:   "/job-descriptions/{id}",
+			url:    "/job-descriptions/go-engineer",
+			body:   `{"required_duties": ["Code review"]}`,
+			fields: []string{"title"},
+		},
+		{
+			name:   "malformed JSON",
+			method: "POST",
+			path:   "/job-descriptions",
+			url:    "/job-descriptions",
+			body:   `{"title": `,
+			fields: []string{"body"},
+		},
+		{
+			name:   "follow-up rules",
+			method: "POST",
+			path:   "/gmail/follow-up",
+			url:    "/gmail/follow-up",
+			body:   `{"labels": [{"label": "Shortlisted", "top_n": 5}, {"min_score": 150}], "reply": "email"}`,
+			fields: []string{"labels[1].label", "labels[1].min_score", "reply"},
+		},
+		{
+			name:   "valid report query",
+			method: "GET",
+			path:   "/report",
+			url:    "/report?min_score=70&sort=total_score&order=desc&limit=20",
+		},
+		{
+			name:   "invalid report query",
+			method: "GET",
+			path:   "/report",
+			url:    "/report?min_score=high&status=maybe&limit=1000",
+			fields: []string{"min_score", "status", "limit"},
+		},
+		{
+			name:   "invalid version",
+			method: "GET",
+			path:   "/job-descriptions/{id}",
+			url:    "/job-descriptions/go-engineer?version=1.5",
+			fields: []string{"version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+
+			err := spec.ValidateRequest(r, tt.path, spec.Operation(tt.method, tt.path))
+			if got := invalidFields(t, err); !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("invalid fields = %v, want %v", got, tt.fields)
+			}
+		})
+	}
+}
+
+// TestValidateRequest_Form tests multipart forms, including the job description JSON field
+func TestValidateRequest_Form(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("method", "upload")
+	form.WriteField("job_description", `{"title": "Go Engineer", "required_experience": [""], "requirements": []}`)
+	form.WriteField("imap_port", "99999")
+	form.WriteField("gmail_after", "01/02/2025")
+	part, _ := form.CreateFormFile("files", "cv.pdf")
+	part.Write([]byte("%PDF-1.4"))
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/ingest", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+
+	err = spec.ValidateRequest(r, "/ingest", spec.Operation("POST", "/ingest"))
+	want := []string{"gmail_after", "imap_port", "job_description.required_experience[0]", "job_description.requirements"}
+	if got := invalidFields(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid fields = %v, want %v", got, want)
+	}
+
+	// The form stays parsed for the handler
+	if r.MultipartForm == nil || len(r.MultipartForm.File["files"]) != 1 || r.FormValue("method") != "upload" {
+		t.Error("Expected the parsed form to be kept on the request")
+	}
+}
+
+// invalidFields returns the field names of a validation error
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var invalid *models.ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected a *models.ValidationError, got %v", err)
+	}
+	fields := make([]string, len(invalid.Fields))
+	for i, f := range invalid.Fields {
+		fields[i] = f.Field
+	}
+	return fields
+}
@@ -0,0 +1,414 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// maxFormMemory is how much of a multipart form is kept in memory; larger
+// uploads go to temporary files
+const maxFormMemory = 32 << 20
+
+// ValidateRequest checks the parameters and body of a request against the
+// operation at path (a template such as "/jobs/{id}"). Invalid fields are
+// returned as a *models.ValidationError; other errors mean the body could not
+// be read. JSON bodies are restored and forms are left parsed, so handlers can
+// read them as usual.
+func (s *Spec) ValidateRequest(r *http.Request, path string, op *Operation) error {
+	var v models.ValidationError
+
+	pathValues := pathParams(path, r.URL.Path)
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = pathValues[param.Name]
+		case "query":
+			present = query.Has(param.Name)
+			value = query.Get(param.Name)
+		case "header":
+			value = r.Header.Get(param.Name)
+			present = value != ""
+		default:
+			continue
+		}
+		if !present {
+			if param.Required {
+				v.Add(param.Name, "is required")
+			}
+			continue
+		}
+		s.validateText(&v, param.Name, value, param.Schema)
+	}
+
+	if op.RequestBody != nil {
+		if err := s.validateBody(&v, r, op.RequestBody); err != nil {
+			return err
+		}
+	}
+	return v.Err()
+}
+
+// validateBody checks a JSON or form body
+func (s *Spec) validateBody(v *models.ValidationError, r *http.Request, body *RequestBody) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		if body.Required && r.ContentLength != 0 {
+			v.Add("Content-Type", "is required")
+		} else if body.Required {
+			v.Add("body", "is required")
+		}
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		v.Add("Content-Type", "is not a valid media type")
+		return nil
+	}
+	content, ok := body.Content[mediaType]
+	if !ok {
+		var accepted []string
+		for name := range body.Content {
+			accepted = append(accepted, name)
+		}
+		sort.Strings(accepted)
+		v.Add("Content-Type", "must be one of %s", strings.Join(accepted, ", "))
+		return nil
+	}
+
+	switch mediaType {
+	case "application/json":
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		if len(bytes.TrimSpace(data)) == 0 {
+			if body.Required {
+				v.Add("body", "is required")
+			}
+			return nil
+		}
+		value, err := decodeJSON(data)
+		if err != nil {
+			v.Add("body", "is not valid JSON: %v", err)
+			return nil
+		}
+		s.validateValue(v, "", value, content.Schema)
+
+	case "multipart/form-data", "application/x-www-form-urlencoded":
+		if mediaType == "multipart/form-data" {
+			err = r.ParseMultipartForm(maxFormMemory)
+		} else {
+			err = r.ParseForm()
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return err
+			}
+			return fmt.Errorf("failed to parse form: %w", err)
+		}
+		s.validateForm(v, r, content)
+	}
+	return nil
+}
+
+// validateForm checks form fields, including file uploads and fields holding JSON
+func (s *Spec) validateForm(v *models.ValidationError, r *http.Request, content MediaType) {
+	schema, err := s.resolve(content.Schema)
+	if err != nil || schema == nil {
+		return
+	}
+
+	for _, name := range schema.Required {
+		if !formHas(r, name) {
+			v.Add(name, "is required")
+		}
+	}
+
+	for _, name := range sortedKeys(schema.Properties) {
+		property, err := s.resolve(schema.Properties[name])
+		if err != nil {
+			continue
+		}
+		values := r.Form[name]
+
+		// File uploads
+		if isBinary(property) || (property.Type == "array" && property.Items != nil && isBinary(property.Items)) {
+			var files int
+			if r.MultipartForm != nil {
+				files = len(r.MultipartForm.File[name])
+			}
+			if property.Type == "array" {
+				s.checkCount(v, name, files, property)
+			} else if len(values) > 0 && files == 0 {
+				v.Add(name, "must be a file upload")
+			}
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		// Fields holding JSON documents, e.g. the job description
+		if content.Encoding[name].ContentType == "application/json" {
+			value, err := decodeJSON([]byte(values[0]))
+			if err != nil {
+				v.Add(name, "is not valid JSON: %v", err)
+				continue
+			}
+			s.validateValue(v, name, value, property)
+			continue
+		}
+
+		if property.Type == "array" {
+			s.checkCount(v, name, len(values), property)
+			for i, value := range values {
+				s.validateText(v, fmt.Sprintf("%s[%d]", name, i), value, property.Items)
+			}
+			continue
+		}
+		s.validateText(v, name, values[0], property)
+	}
+}
+
+// validateText checks a parameter or form value, converting it to the schema type
+func (s *Spec) validateText(v *models.ValidationError, field, text string, schema *Schema) {
+	schema, err := s.resolve(schema)
+	if err != nil || schema == nil {
+		return
+	}
+
+	var value interface{} = text
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			v.Add(field, "must be a number")
+			return
+		}
+		value = json.Number(text)
+	case "boolean":
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			v.Add(field, "must be true or false")
+			return
+		}
+		value = b
+	}
+	s.validateValue(v, field, value, schema)
+}
+
+// validateValue checks a decoded JSON value against a schema
+func (s *Spec) validateValue(v *models.ValidationError, field string, value interface{}, schema *Schema) {
+	schema, err := s.resolve(schema)
+	if err != nil || schema == nil {
+		return
+	}
+	name := field
+	if name == "" {
+		name = "body"
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			v.Add(name, "must not be null")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.Add(name, "must be an object")
+			return
+		}
+		for _, required := range schema.Required {
+			if _, ok := object[required]; !ok {
+				v.Add(join(field, required), "is required")
+			}
+		}
+		for _, key := range sortedKeys(object) {
+			item := object[key]
+			property, ok := schema.Properties[key]
+			if !ok {
+				if closed, ok := schema.AdditionalProperties.(bool); ok && !closed {
+					v.Add(join(field, key), "is not a known field")
+				}
+				continue
+			}
+			s.validateValue(v, join(field, key), item, property)
+		}
+
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.Add(name, "must be an array")
+			return
+		}
+		s.checkCount(v, name, len(items), schema)
+		for i, item := range items {
+			s.validateValue(v, fmt.Sprintf("%s[%d]", name, i), item, schema.Items)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			v.Add(name, "must be a string")
+			return
+		}
+		length := utf8.RuneCountInString(str)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				v.Add(name, "must not be empty")
+			} else {
+				v.Add(name, "must be at least %d characters", *schema.MinLength)
+			}
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			v.Add(name, "must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.pattern != nil && !schema.pattern.MatchString(str) {
+			v.Add(name, "has an invalid format")
+		}
+		if schema.Format == "date" && str != "" {
+			if _, err := time.Parse("2006-01-02", str); err != nil {
+				v.Add(name, "must be a date (YYYY-MM-DD)")
+			}
+		}
+		s.checkEnum(v, name, str, schema)
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			v.Add(name, "must be a number")
+			return
+		}
+		f, err := num.Float64()
+		if err != nil {
+			v.Add(name, "must be a number")
+			return
+		}
+		if schema.Type == "integer" && f != float64(int64(f)) {
+			v.Add(name, "must be a whole number")
+			return
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			v.Add(name, "must be at least %s", formatNumber(*schema.Minimum))
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			v.Add(name, "must be at most %s", formatNumber(*schema.Maximum))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.Add(name, "must be true or false")
+		}
+	}
+}
+
+// checkCount checks the number of items of an array or repeated form field
+func (s *Spec) checkCount(v *models.ValidationError, name string, count int, schema *Schema) {
+	if schema.MinItems != nil && count < *schema.MinItems {
+		v.Add(name, "must have at least %d item(s)", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && count > *schema.MaxItems {
+		v.Add(name, "must have at most %d items", *schema.MaxItems)
+	}
+}
+
+// checkEnum checks that a string is one of the allowed values
+func (s *Spec) checkEnum(v *models.ValidationError, name, value string, schema *Schema) {
+	if len(schema.Enum) == 0 {
+		return
+	}
+	allowed := make([]string, len(schema.Enum))
+	for i, option := range schema.Enum {
+		allowed[i] = fmt.Sprint(option)
+		if allowed[i] == value {
+			return
+		}
+	}
+	v.Add(name, "must be one of %s", strings.Join(allowed, ", "))
+}
+
+// pathParams matches a path template against a request path
+func pathParams(template, path string) map[string]string {
+	params := make(map[string]string)
+	templateParts := strings.Split(strings.Trim(template, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateParts) != len(pathParts) {
+		return params
+	}
+	for i, part := range templateParts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params[part[1:len(part)-1]] = pathParts[i]
+		}
+	}
+	return params
+}
+
+// decodeJSON decodes a JSON document, keeping numbers as json.Number
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// sortedKeys returns the keys of a map in order, so errors are listed consistently
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formHas reports whether a form has a value or file for a field
+func formHas(r *http.Request, name string) bool {
+	if len(r.Form[name]) > 0 {
+		return true
+	}
+	return r.MultipartForm != nil && len(r.MultipartForm.File[name]) > 0
+}
+
+// isBinary reports whether a schema describes a file upload
+func isBinary(schema *Schema) bool {
+	return schema.Type == "string" && schema.Format == "binary"
+}
+
+// join builds a dotted field name
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// formatNumber prints limits without trailing zeros
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,154 @@
+package scoring
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+const (
+	// additionalDocumentsBudget is the total characters of portfolios, certificates,
+	// references and transcripts included in a scoring prompt
+	additionalDocumentsBudget = 6000
+	// maxAdditionalDocumentSize keeps one long portfolio from using the whole budget
+	maxAdditionalDocumentSize = 2500
+	// minAdditionalDocumentSize is the smallest excerpt worth including
+	minAdditionalDocumentSize = 300
+)
+
+// additionalDocument is an excerpt of a supporting document selected for the prompt
+type additionalDocument struct {
+	models.Document
+	excerpt   string
+	truncated bool
+}
+
+// selectAdditionalDocuments picks the applicant's supporting documents that
+// mention the job's requirements, most relevant first, and trims them to the
+// additional documents budget. Without requirements every document is relevant.
+func selectAdditionalDocuments(docs []models.Document, jobDesc models.JobDescription) []additionalDocument {
+	keywords := requirementKeywords(jobDesc)
+
+	type candidate struct {
+		doc       models.Document
+		relevance int
+	}
+	var candidates []candidate
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.Content) == "" {
+			continue
+		}
+		relevance := keywordMatches(doc.Content, keywords)
+		if len(keywords) > 0 && relevance == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{doc, relevance})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].relevance > candidates[j].relevance
+	})
+
+	var selected []additionalDocument
+	remaining := additionalDocumentsBudget
+	for _, c := range candidates {
+		size := min(maxAdditionalDocumentSize, remaining)
+		if size < minAdditionalDocumentSize {
+			break
+		}
+		excerpt := sanitizeUTF8(c.doc.Content)
+		truncated := len(excerpt) > size
+		if truncated {
+			excerpt = truncateUTF8(excerpt, size)
+		}
+		remaining -= len(excerpt)
+		selected = append(selected, additionalDocument{Document: c.doc, excerpt: excerpt, truncated: truncated})
+	}
+	return selected
+}
+
+// writeAdditionalDocuments adds the selected supporting documents to the prompt
+func writeAdditionalDocuments(sb *strings.Builder, docs []additionalDocument) {
+	if len(docs) == 0 {
+		return
+	}
+
+	sb.WriteString("### ADDITIONAL DOCUMENTS\n")
+	sb.WriteString("Use these only as evidence for the experience, education and duties scores (e.g. a certificate confirming a qualification). They do not earn points on their own.\n\n")
+	for _, doc := range docs {
+		sb.WriteString(fmt.Sprintf("#### %s (%s)\n", documentTitle(doc.Type), filepath.Base(doc.Path)))
+		sb.WriteString(doc.excerpt)
+		if doc.truncated {
+			sb.WriteString("\n...[Document truncated for length]")
+		}
+		sb.WriteString("\n\n")
+	}
+}
+
+// documentTitle turns a document type such as "cover_letter" into a heading
+func documentTitle(docType string) string {
+	title := strings.ReplaceAll(docType, "_", " ")
+	if title == "" {
+		return "Document"
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// requirementKeywords returns the distinct words of four or more letters in the job requirements
+func requirementKeywords(jobDesc models.JobDescription) map[string]bool {
+	keywords := make(map[string]bool)
+	lists := [][]string{
+		jobDesc.RequiredExperience, jobDesc.RequiredEducation, jobDesc.RequiredDuties,
+		jobDesc.NiceToHaveExperience, jobDesc.NiceToHaveEducation, jobDesc.NiceToHaveDuties,
+	}
+	for _, list := range lists {
+		for _, item := range list {
+			for _, word := range words(item) {
+				if utf8.RuneCountInString(word) >= 4 && !requirementStopWords[word] {
+					keywords[word] = true
+				}
+			}
+		}
+	}
+	return keywords
+}
+
+// requirementStopWords are common requirement words that say nothing about relevance
+var requirementStopWords = map[string]bool{
+	"with": true, "years": true, "experience": true, "least": true, "knowledge": true,
+	"ability": true, "strong": true, "good": true, "working": true, "degree": true,
+	"skills": true, "related": true, "field": true, "understanding": true, "preferred": true,
+}
+
+// keywordMatches counts how many keywords appear in text
+func keywordMatches(text string, keywords map[string]bool) int {
+	found := make(map[string]bool)
+	for _, word := range words(text) {
+		if keywords[word] {
+			found[word] = true
+		}
+	}
+	return len(found)
+}
+
+// words splits text into lower-case words
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// truncateUTF8 shortens s to at most maxLen bytes without splitting a character
+func truncateUTF8(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen]
+}
@@ -0,0 +1,100 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fmuoria/CV-Review-agent/internal/models"
+)
+
+// TestSelectAdditionalDocuments tests relevance filtering, ordering and the shared budget
+func TestSelectAdditionalDocuments(t *testing.T) {
+	jobDesc := models.JobDescription{
+		RequiredExperience: []string{"Kubernetes administration", "Terraform"},
+		RequiredEducation:  []string{"Computer Science degree"},
+	}
+
+	docs := []models.Document{
+		{Type: models.DocumentTranscript, Path: "transcript.pdf", Content: "Computer Science, grade A"},
+		{Type: models.DocumentCertificate, Path: "cka.pdf", Content: "Certified Kubernetes Administration, Terraform associate"},
+		{Type: models.DocumentPortfolio, Path: "art.pdf", Content: "Watercolour paintings"},
+		{Type: models.DocumentReference, Path: "ref.pdf", Content: "Led our Kubernetes migration. " + strings.Repeat("Reliable and thorough. ", 400)},
+		{Type: models.DocumentCertificate, Path: "empty.pdf", Content: "  "},
+	}
+
+	selected := selectAdditionalDocuments(docs, jobDesc)
+
+	var paths []string
+	total := 0
+	for _, doc := range selected {
+		paths = append(paths, doc.Path)
+		total += len(doc.excerpt)
+		if len(doc.excerpt) > maxAdditionalDocumentSize {
+			t.Errorf("%s excerpt exceeds per-document limit: %d", doc.Path, len(doc.excerpt))
+		}
+	}
+
+	want := "cka.pdf,transcript.pdf,ref.pdf"
+	if strings.Join(paths, ",") != want {
+		t.Errorf("Expected documents %s, got %v", want, paths)
+	}
+	if total > additionalDocumentsBudget {
+		t.Errorf("Selected documents exceed budget: %d", total)
+	}
+	if len(selected) == 3 && !selected[2].truncated {
+		t.Errorf("Long reference should be truncated")
+	}
+}
+
+// TestSelectAdditionalDocuments_BudgetExhausted tests that documents beyond the budget are left out
+func TestSelectAdditionalDocuments_BudgetExhausted(t *testing.T) {
+	var docs []models.Document
+	for i := 0; i < 5; i++ {
+		docs = append(docs, models.Document{Type: models.DocumentCertificate, Content: strings.Repeat("Go ", 1000)})
+	}
+
+	selected := selectAdditionalDocuments(docs, models.JobDescription{})
+	if len(selected) != 3 {
+		t.Errorf("Expected budget to fit 3 documents, got %d", len(selected))
+	}
+}
+
+// TestBuildScoringPrompt_AdditionalDocuments tests that supporting documents appear after the cover letter
+func TestBuildScoringPrompt_AdditionalDocuments(t *testing.T) {
+	scorer := &Scorer{}
+
+	applicant := models.ApplicantDocument{
+		Name: "Jane Smith",
+		Documents: []models.Document{
+			{Type: models.DocumentCV, Path: "cv.pdf", Content: "Sample CV"},
+			{Type: models.DocumentCoverLetter, Path: "letter.pdf", Content: "Sample cover letter"},
+			{Type: models.DocumentCertificate, Path: "uploads/Jane Smith/cka.pdf", Content: "Certified Kubernetes Administrator"},
+		},
+	}
+	jobDesc := models.JobDescription{
+		Title:              "Platform Engineer",
+		RequiredExperience: []string{"Kubernetes"},
+	}
+
+	prompt := scorer.buildScoringPrompt(applicant, jobDesc)
+
+	section := strings.Index(prompt, "### ADDITIONAL DOCUMENTS")
+	if section < 0 || section < strings.Index(prompt, "### COVER LETTER CONTENT") {
+		t.Fatal("Expected additional documents section after the cover letter")
+	}
+	if !strings.Contains(prompt, "#### Certificate (cka.pdf)\nCertified Kubernetes Administrator") {
+		t.Error("Certificate content not found in prompt")
+	}
+}
+
+// TestTruncateUTF8 tests that truncation never splits a multi-byte character
+func TestTruncateUTF8(t *testing.T) {
+	got := truncateUTF8("Zürich", 2)
+	if got != "Z" || !utf8.ValidString(got) {
+		t.Errorf("truncateUTF8() = %q, want %q", got, "Z")
+	}
+	if got := truncateUTF8("a\xffbcdef", 4); got != "a\xffbc" {
+		t.Errorf("truncateUTF8() with an invalid byte = %q, want %q", got, "a\xffbc")
+	}
+}
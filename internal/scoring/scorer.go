@@ -75,7 +75,8 @@ func (s *Scorer) ScoreApplicant(ctx context.Context, applicant models.ApplicantD
 	prompt := s.buildScoringPrompt(applicant, jobDesc)
 
 	// Log request details
-	log.Printf("CV length: %d bytes, Cover letter: %d bytes", len(applicant.CVContent), len(applicant.CLContent))
+	log.Printf("CV length: %d bytes, Cover letter: %d bytes, Additional documents: %d",
+		len(applicant.CV().Content), len(applicant.CoverLetter().Content), len(applicant.AdditionalDocuments()))
 	log.Printf("Sending request to Gemini 2.5 Flash...")
 
 	// Get response from LLM
@@ -125,7 +126,7 @@ func (s *Scorer) buildScoringPrompt(applicant models.ApplicantDocument, jobDesc
 
 	sb.WriteString("### CV CONTENT\n")
 	// Sanitize and truncate CV content to prevent UTF-8 encoding errors and excessive length
-	cvContent := applicant.CVContent
+	cvContent := applicant.CV().Content
 	if !utf8.ValidString(cvContent) {
 		log.Printf("Sanitizing invalid UTF-8 in CV for applicant: %s (length: %d bytes)", applicant.Name, len(cvContent))
 		cvContent = sanitizeUTF8(cvContent)
@@ -139,10 +140,9 @@ func (s *Scorer) buildScoringPrompt(applicant models.ApplicantDocument, jobDesc
 	sb.WriteString(cvContent)
 	sb.WriteString("\n\n")
 
-	if applicant.CLContent != "" {
+	if clContent := applicant.CoverLetter().Content; clContent != "" {
 		sb.WriteString("### COVER LETTER CONTENT\n")
 		// Sanitize and truncate cover letter content
-		clContent := applicant.CLContent
 		if !utf8.ValidString(clContent) {
 			log.Printf("Sanitizing invalid UTF-8 in cover letter for applicant: %s (length: %d bytes)", applicant.Name, len(clContent))
 			clContent = sanitizeUTF8(clContent)
@@ -157,6 +157,13 @@ func (s *Scorer) buildScoringPrompt(applicant models.ApplicantDocument, jobDesc
 		sb.WriteString("\n\n")
 	}
 
+	// Portfolios, certificates, references and transcripts share their own budget
+	additional := selectAdditionalDocuments(applicant.AdditionalDocuments(), jobDesc)
+	if skipped := len(applicant.AdditionalDocuments()) - len(additional); skipped > 0 {
+		log.Printf("Leaving out %d additional document(s) for applicant %s (not relevant or over budget)", skipped, applicant.Name)
+	}
+	writeAdditionalDocuments(&sb, additional)
+
 	sb.WriteString("## CRITICAL SCORING INSTRUCTIONS\n\n")
 	sb.WriteString("CURRENT DATE FOR REFERENCE: November 22, 2025 (2025-11-22)\n\n")
 
@@ -581,12 +588,12 @@ func (s *Scorer) parseScores(response string) (models.Scores, error) {
 	return scores, nil
 }
 
-// truncate returns the first maxLen characters of s, appending "..." if truncated
+// truncate returns the first maxLen bytes of s, appending "..." if truncated
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "..."
+	return truncateUTF8(s, maxLen) + "..."
 }
 
 // min returns the minimum of two integers
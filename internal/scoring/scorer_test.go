@@ -183,6 +183,12 @@ func TestTruncate(t *testing.T) {
 			maxLen: 10,
 			want:   "",
 		},
+		{
+			name:   "Multi-byte character not split",
+			input:  "Zürich office",
+			maxLen: 2,
+			want:   "Z...",
+		},
 	}
 
 	for _, tt := range tests {
@@ -296,9 +302,11 @@ func TestBuildScoringPrompt_ContentTruncation(t *testing.T) {
 	longCL := strings.Repeat("This is cover letter content. ", 200) // ~6,000 chars
 
 	applicant := models.ApplicantDocument{
-		Name:      "John Doe",
-		CVContent: longCV,
-		CLContent: longCL,
+		Name: "John Doe",
+		Documents: []models.Document{
+			{Type: models.DocumentCV, Content: longCV},
+			{Type: models.DocumentCoverLetter, Content: longCL},
+		},
 	}
 
 	jobDesc := models.JobDescription{
@@ -331,9 +339,11 @@ func TestBuildScoringPrompt_NoTruncationNeeded(t *testing.T) {
 	scorer := &Scorer{}
 
 	applicant := models.ApplicantDocument{
-		Name:      "Jane Smith",
-		CVContent: "Short CV content",
-		CLContent: "Short cover letter",
+		Name: "Jane Smith",
+		Documents: []models.Document{
+			{Type: models.DocumentCV, Content: "Short CV content"},
+			{Type: models.DocumentCoverLetter, Content: "Short cover letter"},
+		},
 	}
 
 	jobDesc := models.JobDescription{
@@ -457,9 +467,11 @@ func TestBuildScoringPrompt_ContainsCriticalInstructions(t *testing.T) {
 	scorer := &Scorer{}
 
 	applicant := models.ApplicantDocument{
-		Name:      "Test Applicant",
-		CVContent: "Sample CV content",
-		CLContent: "Sample cover letter",
+		Name: "Test Applicant",
+		Documents: []models.Document{
+			{Type: models.DocumentCV, Content: "Sample CV content"},
+			{Type: models.DocumentCoverLetter, Content: "Sample cover letter"},
+		},
 	}
 
 	jobDesc := models.JobDescription{
@@ -513,7 +525,7 @@ func TestBuildScoringPrompt_DateCalculationExamples(t *testing.T) {
 
 	applicant := models.ApplicantDocument{
 		Name:      "Test Applicant",
-		CVContent: "Sample CV",
+		Documents: []models.Document{{Type: models.DocumentCV, Content: "Sample CV"}},
 	}
 
 	jobDesc := models.JobDescription{
@@ -547,7 +559,7 @@ func TestBuildScoringPrompt_ContextAwareKeywords(t *testing.T) {
 
 	applicant := models.ApplicantDocument{
 		Name:      "Test Applicant",
-		CVContent: "Sample CV",
+		Documents: []models.Document{{Type: models.DocumentCV, Content: "Sample CV"}},
 	}
 
 	jobDesc := models.JobDescription{
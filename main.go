@@ -15,7 +15,10 @@ func main() {
 	cvAgent := agent.NewCVReviewAgent()
 
 	// Create API server
-	server := api.NewServer(cvAgent)
+	server, err := api.NewServer(cvAgent)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {